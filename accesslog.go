@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+)
+
+// AccessResult 访问结果
+type AccessResult string
+
+const (
+	// AccessHit 命中
+	AccessHit AccessResult = "hit"
+	// AccessMiss 未命中
+	AccessMiss AccessResult = "miss"
+	// AccessPlaceholder 命中未找到占位符
+	AccessPlaceholder AccessResult = "placeholder"
+	// AccessTombstone 命中墓碑标记
+	AccessTombstone AccessResult = "tombstone"
+	// AccessWrite 写入
+	AccessWrite AccessResult = "write"
+)
+
+// AccessRecord 一次键访问的记录
+type AccessRecord struct {
+	// Time 访问时间
+	Time time.Time `json:"time"`
+	// Op 操作名称，如get、multi_get、set
+	Op string `json:"op"`
+	// CacheKey 实际的缓存键，包含前缀
+	CacheKey string `json:"cache_key"`
+	// Result 访问结果
+	Result AccessResult `json:"result"`
+	// Size 值的字节数，未命中时为0
+	Size int `json:"size"`
+	// TTL 写入的过期时间，只在写入时有值
+	TTL time.Duration `json:"ttl,omitempty"`
+}
+
+// AccessSink 访问记录的接收者
+// 在缓存操作的调用goroutine中同步调用，实现必须是线程安全的并且应尽快返回
+type AccessSink interface {
+	Record(rec AccessRecord)
+}
+
+// AccessSinkFunc 函数形式的访问记录接收者
+type AccessSinkFunc func(rec AccessRecord)
+
+// Record 记录访问
+func (f AccessSinkFunc) Record(rec AccessRecord) {
+	f(rec)
+}
+
+// writerSink 以JSON行格式写入访问记录
+type writerSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterSink 创建以JSON行格式写入w的访问记录接收者，写入错误被忽略
+func NewWriterSink(w io.Writer) AccessSink {
+	return &writerSink{enc: json.NewEncoder(w)}
+}
+
+// Record 记录访问
+func (s *writerSink) Record(rec AccessRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.enc.Encode(rec)
+}
+
+// AccessSampler 键访问采样器，按比例记录被访问的键、命中情况和值大小
+// 用于评估MaxCost、NumCounters和过期时间的合理取值
+// 按缓存键的哈希采样，被选中的键的每一次访问都会记录，便于分析单个键的访问间隔
+type AccessSampler struct {
+	threshold uint32
+	sink      AccessSink
+}
+
+// NewAccessSampler 创建键访问采样器，rate为采样的键比例，取值(0, 1]
+func NewAccessSampler(rate float64, sink AccessSink) *AccessSampler {
+	rate = min(max(rate, 0), 1)
+	return &AccessSampler{threshold: uint32(rate * float64(1<<32-1)), sink: sink}
+}
+
+// sampled 缓存键是否被采样
+func (s *AccessSampler) sampled(cacheKey string) bool {
+	if s.threshold == 0 || s.sink == nil {
+		return false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cacheKey))
+	// 短键的FNV哈希分布不均匀，再做一次混合
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x <= s.threshold
+}
+
+// WithAccessSampler 设置键访问采样器
+func WithAccessSampler(sampler *AccessSampler) CacheOption {
+	return func(o *cacheOptions) {
+		o.sampler = sampler
+	}
+}
+
+// sampleRead 记录读取，found为false表示键不存在
+func (o *cacheOptions) sampleRead(op, cacheKey string, data []byte, found bool) {
+	if o.sampler == nil || !o.sampler.sampled(cacheKey) {
+		return
+	}
+	rec := AccessRecord{Time: time.Now(), Op: op, CacheKey: cacheKey, Result: AccessMiss}
+	switch {
+	case !found:
+	case bytes.Equal(data, TombstonePlaceholderBytes):
+		rec.Result = AccessTombstone
+	case o.isPlaceholder(data):
+		rec.Result = AccessPlaceholder
+	default:
+		rec.Result = AccessHit
+		rec.Size = len(data)
+	}
+	o.sampler.sink.Record(rec)
+}
+
+// samplePairs 记录批量写入，pairs为交替的缓存键和值
+func (o *cacheOptions) samplePairs(op string, pairs []interface{}, ttl time.Duration) {
+	if o.sampler == nil {
+		return
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		cacheKey, _ := pairs[i].([]byte)
+		value, _ := pairs[i+1].([]byte)
+		o.sampleWrite(op, string(cacheKey), len(value), ttl)
+	}
+}
+
+// sampleWrite 记录写入
+func (o *cacheOptions) sampleWrite(op, cacheKey string, size int, ttl time.Duration) {
+	if o.sampler == nil || !o.sampler.sampled(cacheKey) {
+		return
+	}
+	o.sampler.sink.Record(AccessRecord{Time: time.Now(), Op: op, CacheKey: cacheKey, Result: AccessWrite, Size: size, TTL: ttl})
+}
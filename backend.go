@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BackendFactory 根据配置创建缓存提供者，用于接入不基于Store的第三方后端
+// 统计、写入频率限制等通用包装由工厂自行决定是否使用，Config中的Extra可用于传入后端特有的配置
+type BackendFactory func(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error)
+
+var (
+	backendFactoriesMu sync.RWMutex
+	backendFactories   = make(map[CacheType]BackendFactory)
+)
+
+// RegisterBackend 注册缓存类型的提供者工厂，通常在后端模块的init中调用
+// 注册后即可通过NewProvider按Config.Type创建；不能覆盖内置类型和已通过RegisterStore注册的类型
+func RegisterBackend(t CacheType, factory BackendFactory) {
+	if factory == nil {
+		panic("缓存: 提供者工厂不能为空")
+	}
+	switch t {
+	case MemoryCache, RedisCache, RedisClusterCache:
+		panic(fmt.Sprintf("缓存: 不能覆盖内置缓存类型 %s", t))
+	}
+	if _, ok := lookupStore(t); ok {
+		panic(fmt.Sprintf("缓存: 重复注册缓存类型 %s", t))
+	}
+	backendFactoriesMu.Lock()
+	defer backendFactoriesMu.Unlock()
+	if _, ok := backendFactories[t]; ok {
+		panic(fmt.Sprintf("缓存: 重复注册缓存类型 %s", t))
+	}
+	backendFactories[t] = factory
+}
+
+// lookupBackend 获取已注册的提供者工厂
+func lookupBackend(t CacheType) (BackendFactory, bool) {
+	backendFactoriesMu.RLock()
+	defer backendFactoriesMu.RUnlock()
+	factory, ok := backendFactories[t]
+	return factory, ok
+}
@@ -0,0 +1,22 @@
+package cache
+
+import "context"
+
+// bypassCtxKey 上下文中跳过缓存读取标记的键
+type bypassCtxKey struct{}
+
+// WithBypass 返回跳过缓存读取的上下文
+// 所有缓存实现的Get和MultiGet视为未命中，GetOrSet因此总是调用加载函数并用结果覆盖缓存；写入和删除照常执行
+// 用于管理接口的强制刷新以及排查旧数据
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCtxKey{}, true)
+}
+
+// BypassFromContext 上下文是否要求跳过缓存读取
+func BypassFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypass, _ := ctx.Value(bypassCtxKey{}).(bool)
+	return bypass
+}
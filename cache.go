@@ -19,18 +19,29 @@ var (
 	NotFoundPlaceholderBytes = []byte(NotFoundPlaceholder)
 	ErrPlaceholder           = errors.New("缓存: 占位符")
 
+	// DefaultTombstoneExpireTime 墓碑标记的默认过期时间
+	DefaultTombstoneExpireTime = time.Second * 5
+	// TombstonePlaceholder 墓碑标记，表示键刚被主动删除
+	TombstonePlaceholder      = "*tombstone*"
+	TombstonePlaceholderBytes = []byte(TombstonePlaceholder)
+	// ErrTombstone 读取到墓碑标记，读穿加载器不应回填该键
+	ErrTombstone = errors.New("缓存: 墓碑标记")
+
 	// DefaultClient 生成缓存客户端，keyPrefix通常是业务前缀
 	DefaultClient Cache
 )
 
-// Cache 缓存驱动接口
+// Cache 缓存驱动接口，批量删除、标签、复制等其他能力见扩展接口(ExtendedCache)
 type Cache interface {
 	Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string, val interface{}) error
 	MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error
 	MultiGet(ctx context.Context, keys []string, valueMap interface{}) error
 	Del(ctx context.Context, keys ...string) error
-	SetCacheWithNotFound(ctx context.Context, key string) error
+	SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error
+	GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
 }
 
 // Set 设置数据
@@ -58,7 +69,94 @@ func Del(ctx context.Context, keys ...string) error {
 	return DefaultClient.Del(ctx, keys...)
 }
 
-// SetCacheWithNotFound 设置未找到的缓存
-func SetCacheWithNotFound(ctx context.Context, key string) error {
-	return DefaultClient.SetCacheWithNotFound(ctx, key)
+// SetCacheWithNotFound 设置未找到的缓存，禁用占位符时不写入任何数据
+// 可选的ttl大于0时使用该过期时间，不使用实例配置的过期时间和退避
+func SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	return DefaultClient.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存，用于批量加载后一次写入所有缺失键的占位符
+func MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	return Extend(DefaultClient).MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	return Extend(DefaultClient).DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增，仅在键新建时设置过期时间
+func IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return Extend(DefaultClient).IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 分片批量删除大量键
+func DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	return Extend(DefaultClient).DelMany(ctx, keys, opts)
+}
+
+// Describe 获取缓存条目的元数据，包括剩余过期时间、存储大小和信封信息
+func Describe(ctx context.Context, key string) (EntryInfo, error) {
+	return Extend(DefaultClient).Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存，同一个键的并发加载只执行一次
+func GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	return DefaultClient.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+}
+
+// DelDelayed 延迟双删，立即删除数据，delay后再删除一次，清除并发读请求回填的旧数据
+func DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return Extend(DefaultClient).DelDelayed(ctx, key, delay)
+}
+
+// TTL 获取键的剩余过期时间，永不过期时返回0，键不存在时返回ErrCacheNotFound
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	return DefaultClient.TTL(ctx, key)
+}
+
+// Expire 重设键的过期时间，用于会话续期等场景，键不存在时返回ErrCacheNotFound
+func Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return DefaultClient.Expire(ctx, key, ttl)
+}
+
+// Exists 键是否存在，未找到占位符和墓碑标记同样视为存在
+func Exists(ctx context.Context, key string) (bool, error) {
+	return Extend(DefaultClient).Exists(ctx, key)
+}
+
+// SetNX 仅在键不存在时设置数据，写入成功时返回true，用于幂等保护和简单的锁
+func SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	return Extend(DefaultClient).SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签，用于按标签批量失效，如失效某个分类下的所有商品
+func SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	return Extend(DefaultClient).SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 删除标签下的所有键，返回键数量和释放的估算字节数
+func InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	return Extend(DefaultClient).InvalidateTag(ctx, tag)
+}
+
+// PreviewInvalidateTag 演练标签失效，返回标签下的键(不含键前缀)，用于在大范围失效前评估影响
+func PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	return Extend(DefaultClient).PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除匹配模式(不含键前缀)的键，如user:123:*，返回删除的键数量
+func DelPattern(ctx context.Context, pattern string) (int64, error) {
+	return Extend(DefaultClient).DelPattern(ctx, pattern)
+}
+
+// Copy 复制键到目标键，目标键已存在时覆盖，preserveTTL为false时目标键按过期时间为0的策略设置过期时间
+// 源键不存在时返回ErrCacheNotFound，用于预热后的键发布到正式键等场景
+func Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	return Extend(DefaultClient).Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键并保留剩余过期时间，目标键已存在时覆盖，源键不存在时返回ErrCacheNotFound
+func Rename(ctx context.Context, src, dst string) error {
+	return Extend(DefaultClient).Rename(ctx, src, dst)
 }
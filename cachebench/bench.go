@@ -0,0 +1,300 @@
+// Package cachebench 缓存压测工具，对任意Cache生成可配置的读写混合负载
+// 并报告吞吐量和延迟分位数，用于对比不同后端和调优配置
+package cachebench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// Distribution 键分布
+type Distribution string
+
+const (
+	// Uniform 均匀分布
+	Uniform Distribution = "uniform"
+	// Zipfian 齐夫分布，少量热点键承担大部分访问
+	Zipfian Distribution = "zipfian"
+)
+
+// Config 压测配置
+type Config struct {
+	// Duration 压测时长，与Operations同时设置时以先达到者为准，默认10秒
+	Duration time.Duration
+	// Operations 总操作数，0表示不限制
+	Operations int64
+	// Concurrency 并发数，默认为8
+	Concurrency int
+	// ReadRatio 读操作比例，取值0到1，为0时使用默认值0.9，纯写入压测可设置为负数
+	ReadRatio float64
+	// ValueSize 写入值的字节数，默认为256
+	ValueSize int
+	// KeySpace 键的数量，默认为10000
+	KeySpace int
+	// KeyPrefix 键的前缀，默认为bench
+	KeyPrefix string
+	// Distribution 键分布，默认为均匀分布
+	Distribution Distribution
+	// ZipfS 齐夫分布参数s，必须大于1，默认1.1
+	ZipfS float64
+	// Expiration 写入的过期时间，默认为1小时
+	Expiration time.Duration
+	// Preload 压测前预先写入所有键
+	Preload bool
+	// Seed 随机种子，0表示使用当前时间
+	Seed int64
+}
+
+// setDefaults 设置默认值
+func (c *Config) setDefaults() {
+	if c.Duration <= 0 && c.Operations <= 0 {
+		c.Duration = 10 * time.Second
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	switch {
+	case c.ReadRatio == 0:
+		c.ReadRatio = 0.9
+	case c.ReadRatio < 0:
+		c.ReadRatio = 0
+	case c.ReadRatio > 1:
+		c.ReadRatio = 1
+	}
+	if c.ValueSize <= 0 {
+		c.ValueSize = 256
+	}
+	if c.KeySpace <= 0 {
+		c.KeySpace = 10000
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = "bench"
+	}
+	if c.Distribution == "" {
+		c.Distribution = Uniform
+	}
+	if c.ZipfS <= 1 {
+		c.ZipfS = 1.1
+	}
+	if c.Expiration <= 0 {
+		c.Expiration = time.Hour
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+}
+
+// Latency 延迟统计
+type Latency struct {
+	Count int64
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	Max   time.Duration
+}
+
+// String 格式化输出
+func (l Latency) String() string {
+	return fmt.Sprintf("count=%d mean=%s p50=%s p90=%s p99=%s p99.9=%s max=%s",
+		l.Count, l.Mean, l.P50, l.P90, l.P99, l.P999, l.Max)
+}
+
+// Report 压测报告
+type Report struct {
+	// Duration 实际压测时长
+	Duration time.Duration
+	// Operations 总操作数
+	Operations int64
+	// Throughput 每秒操作数
+	Throughput float64
+	// Hits 读命中数
+	Hits int64
+	// Misses 读未命中数
+	Misses int64
+	// Errors 错误数
+	Errors int64
+	// Reads 读延迟
+	Reads Latency
+	// Writes 写延迟
+	Writes Latency
+}
+
+// HitRatio 读命中率
+func (r *Report) HitRatio() float64 {
+	total := r.Hits + r.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(total)
+}
+
+// String 格式化输出
+func (r *Report) String() string {
+	return fmt.Sprintf("耗时=%s 操作数=%d 吞吐量=%.0f/s 命中率=%.2f%% 错误=%d\n读: %s\n写: %s",
+		r.Duration, r.Operations, r.Throughput, r.HitRatio()*100, r.Errors, r.Reads, r.Writes)
+}
+
+// workerResult 单个并发的统计结果
+type workerResult struct {
+	reads, writes        []time.Duration
+	hits, misses, errors int64
+}
+
+// Run 对缓存执行压测
+func Run(ctx context.Context, c cache.Cache, config Config) (*Report, error) {
+	if c == nil {
+		return nil, errors.New("缓存不能为空")
+	}
+	config.setDefaults()
+	if config.Distribution != Uniform && config.Distribution != Zipfian {
+		return nil, fmt.Errorf("不支持的键分布: %s", config.Distribution)
+	}
+
+	value := make([]byte, config.ValueSize)
+	rand.New(rand.NewSource(config.Seed)).Read(value)
+
+	if config.Preload {
+		for i := 0; i < config.KeySpace; i++ {
+			if err := c.Set(ctx, keyName(config.KeyPrefix, i), &value, config.Expiration); err != nil {
+				return nil, fmt.Errorf("预热写入错误: %w", err)
+			}
+		}
+	}
+
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	var (
+		wg        sync.WaitGroup
+		remaining = config.Operations
+		mu        sync.Mutex
+		results   = make([]*workerResult, config.Concurrency)
+	)
+	// next 申请执行一次操作，达到总操作数时返回false
+	next := func() bool {
+		if config.Operations <= 0 {
+			return true
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if remaining <= 0 {
+			return false
+		}
+		remaining--
+		return true
+	}
+
+	start := time.Now()
+	for w := 0; w < config.Concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			results[w] = runWorker(ctx, c, &config, value, config.Seed+int64(w), next)
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := &Report{Duration: elapsed}
+	var reads, writes []time.Duration
+	for _, r := range results {
+		reads = append(reads, r.reads...)
+		writes = append(writes, r.writes...)
+		report.Hits += r.hits
+		report.Misses += r.misses
+		report.Errors += r.errors
+	}
+	report.Operations = int64(len(reads) + len(writes))
+	if elapsed > 0 {
+		report.Throughput = float64(report.Operations) / elapsed.Seconds()
+	}
+	report.Reads = summarize(reads)
+	report.Writes = summarize(writes)
+	return report, nil
+}
+
+// runWorker 执行单个并发的压测循环
+func runWorker(ctx context.Context, c cache.Cache, config *Config, value []byte, seed int64, next func() bool) *workerResult {
+	r := rand.New(rand.NewSource(seed))
+	pick := keyPicker(r, config)
+	result := &workerResult{}
+	for ctx.Err() == nil && next() {
+		key := keyName(config.KeyPrefix, pick())
+		if r.Float64() < config.ReadRatio {
+			var got []byte
+			start := time.Now()
+			err := c.Get(ctx, key, &got)
+			result.reads = append(result.reads, time.Since(start))
+			switch {
+			case err == nil:
+				result.hits++
+			case errors.Is(err, cache.ErrCacheNotFound), errors.Is(err, cache.ErrPlaceholder):
+				result.misses++
+			case ctx.Err() != nil:
+				// 压测结束时中断的请求不计为错误
+			default:
+				result.errors++
+			}
+			continue
+		}
+		start := time.Now()
+		err := c.Set(ctx, key, &value, config.Expiration)
+		result.writes = append(result.writes, time.Since(start))
+		if err != nil && ctx.Err() == nil {
+			result.errors++
+		}
+	}
+	return result
+}
+
+// keyPicker 根据键分布生成键序号
+func keyPicker(r *rand.Rand, config *Config) func() int {
+	if config.Distribution == Zipfian {
+		zipf := rand.NewZipf(r, config.ZipfS, 1, uint64(config.KeySpace-1))
+		return func() int { return int(zipf.Uint64()) }
+	}
+	return func() int { return r.Intn(config.KeySpace) }
+}
+
+// keyName 生成键名
+func keyName(prefix string, i int) string {
+	return prefix + ":" + strconv.Itoa(i)
+}
+
+// summarize 计算延迟分位数
+func summarize(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		idx := int(float64(len(samples)-1) * p)
+		return samples[idx]
+	}
+	return Latency{
+		Count: int64(len(samples)),
+		Mean:  total / time.Duration(len(samples)),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		P999:  percentile(0.999),
+		Max:   samples[len(samples)-1],
+	}
+}
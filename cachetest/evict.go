@@ -0,0 +1,169 @@
+package cachetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// EvictFunc 淘汰回调，key为完整的缓存键
+type EvictFunc func(key interface{}, value interface{})
+
+// evictEntry 存储条目
+type evictEntry struct {
+	value    interface{}
+	expireAt time.Time
+	seq      uint64
+}
+
+// EvictableStore 可显式触发淘汰的内存存储，实现cache.MemoryStore
+// 写入总是成功，只有调用EvictNow或EvictOldest时才淘汰，便于复现淘汰回调和未命中的处理逻辑
+type EvictableStore struct {
+	mu      sync.Mutex
+	items   map[interface{}]*evictEntry
+	seq     uint64
+	onEvict EvictFunc
+}
+
+var _ cache.MemoryStore = (*EvictableStore)(nil)
+
+// NewEvictableStore 创建可显式触发淘汰的内存存储，onEvict可以为空
+func NewEvictableStore(onEvict EvictFunc) *EvictableStore {
+	return &EvictableStore{
+		items:   make(map[interface{}]*evictEntry),
+		onEvict: onEvict,
+	}
+}
+
+// NewEvictableCache 使用可显式触发淘汰的存储创建内存缓存
+func NewEvictableCache(keyPrefix string, encode cache.Encoding, newObject func() interface{}, onEvict EvictFunc, opts ...cache.CacheOption) (cache.Cache, *EvictableStore) {
+	store := NewEvictableStore(onEvict)
+	return cache.NewMemoryCacheWithStore(store, keyPrefix, encode, newObject, opts...), store
+}
+
+// lookup 获取未过期的条目，过期条目直接删除，不触发淘汰回调
+func (s *EvictableStore) lookup(key interface{}) (*evictEntry, bool) {
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		delete(s.items, key)
+		return nil, false
+	}
+	return e, true
+}
+
+// Get 获取值
+func (s *EvictableStore) Get(key interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// SetWithTTL 设置值和过期时间
+func (s *EvictableStore) SetWithTTL(key, value interface{}, _ int64, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	e := &evictEntry{value: value, seq: s.seq}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+	s.items[key] = e
+	return true
+}
+
+// GetTTL 获取剩余过期时间
+func (s *EvictableStore) GetTTL(key interface{}) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	if e.expireAt.IsZero() {
+		return 0, true
+	}
+	return time.Until(e.expireAt), true
+}
+
+// Del 删除值，不触发淘汰回调
+func (s *EvictableStore) Del(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+// Wait 写入同步生效，无需等待
+func (s *EvictableStore) Wait() {}
+
+// Close 清空存储
+func (s *EvictableStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[interface{}]*evictEntry)
+}
+
+// Len 当前条目数量，包括尚未清理的过期条目
+func (s *EvictableStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// EvictNow 立即淘汰指定的完整缓存键，返回实际淘汰的数量
+func (s *EvictableStore) EvictNow(keys ...interface{}) int {
+	s.mu.Lock()
+	evicted := make([]interface{}, 0, len(keys))
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if e, ok := s.items[key]; ok {
+			delete(s.items, key)
+			evicted = append(evicted, key)
+			values = append(values, e.value)
+		}
+	}
+	s.mu.Unlock()
+	s.notify(evicted, values)
+	return len(evicted)
+}
+
+// EvictOldest 按写入顺序淘汰最早的n个条目，返回实际淘汰的数量
+func (s *EvictableStore) EvictOldest(n int) int {
+	s.mu.Lock()
+	keys := make([]interface{}, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.items[keys[i]].seq < s.items[keys[j]].seq
+	})
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, s.items[key].value)
+		delete(s.items, key)
+	}
+	s.mu.Unlock()
+	s.notify(keys, values)
+	return len(keys)
+}
+
+// notify 在锁外触发淘汰回调，允许回调中访问缓存
+func (s *EvictableStore) notify(keys, values []interface{}) {
+	if s.onEvict == nil {
+		return
+	}
+	for i, key := range keys {
+		s.onEvict(key, values[i])
+	}
+}
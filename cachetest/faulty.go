@@ -0,0 +1,281 @@
+// Package cachetest 缓存测试辅助工具
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// ErrInjected 注入的故障错误
+var ErrInjected = errors.New("缓存: 注入的故障")
+
+// FaultConfig 故障注入配置
+type FaultConfig struct {
+	// ErrorRate 返回ErrInjected的概率，取值0到1
+	ErrorRate float64
+	// Latency 每次操作额外增加的延迟
+	Latency time.Duration
+	// TimeoutRate 模拟超时的概率，取值0到1
+	// 命中时阻塞到上下文结束后返回上下文错误，上下文没有截止时间时等待Timeout后返回context.DeadlineExceeded
+	TimeoutRate float64
+	// Timeout 上下文没有截止时间时模拟超时的等待时间，默认1秒
+	Timeout time.Duration
+	// Seed 随机种子，0表示使用当前时间，固定种子可以复现故障序列
+	Seed int64
+}
+
+// FaultyCache 故障注入缓存，用于测试应用在缓存缓慢、不稳定或不可用时的行为
+type FaultyCache struct {
+	cache.Cache
+	mu     sync.Mutex
+	config FaultConfig
+	rand   *rand.Rand
+}
+
+// NewFaultyCache 创建故障注入缓存
+func NewFaultyCache(inner cache.Cache, config FaultConfig) *FaultyCache {
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultyCache{
+		Cache:  inner,
+		config: config,
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetConfig 运行时修改故障配置，可用于模拟故障恢复
+func (f *FaultyCache) SetConfig(config FaultConfig) {
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+}
+
+// inject 按配置注入延迟、超时或错误
+func (f *FaultyCache) inject(ctx context.Context) error {
+	f.mu.Lock()
+	config := f.config
+	timeout := f.rand.Float64() < config.TimeoutRate
+	fail := f.rand.Float64() < config.ErrorRate
+	f.mu.Unlock()
+
+	if config.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.Latency):
+		}
+	}
+	if timeout {
+		if _, ok := ctx.Deadline(); ok {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.Timeout):
+			return context.DeadlineExceeded
+		}
+	}
+	if fail {
+		return ErrInjected
+	}
+	return nil
+}
+
+// Set 设置数据
+func (f *FaultyCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.Set(ctx, key, val, expiration)
+}
+
+// Get 获取数据
+func (f *FaultyCache) Get(ctx context.Context, key string, val interface{}) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.Get(ctx, key, val)
+}
+
+// MultiSet 批量设置数据
+func (f *FaultyCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.MultiSet(ctx, valMap, expiration)
+}
+
+// MultiGet 批量获取数据
+func (f *FaultyCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.MultiGet(ctx, keys, valueMap)
+}
+
+// Del 删除数据
+func (f *FaultyCache) Del(ctx context.Context, keys ...string) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (f *FaultyCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (f *FaultyCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (f *FaultyCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增
+func (f *FaultyCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if err := f.inject(ctx); err != nil {
+		return 0, err
+	}
+	return cache.Extend(f.Cache).IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 分片批量删除大量键
+func (f *FaultyCache) DelMany(ctx context.Context, keys []string, opts cache.DelManyOptions) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).DelMany(ctx, keys, opts)
+}
+
+// Describe 获取缓存条目的元数据
+func (f *FaultyCache) Describe(ctx context.Context, key string) (cache.EntryInfo, error) {
+	if err := f.inject(ctx); err != nil {
+		return cache.EntryInfo{}, err
+	}
+	return cache.Extend(f.Cache).Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (f *FaultyCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader cache.Loader, opts ...cache.GetOrSetOption) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (f *FaultyCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).DelDelayed(ctx, key, delay)
+}
+
+// TTL 获取剩余过期时间
+func (f *FaultyCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := f.inject(ctx); err != nil {
+		return 0, err
+	}
+	return f.Cache.TTL(ctx, key)
+}
+
+// Expire 重设过期时间
+func (f *FaultyCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return f.Cache.Expire(ctx, key, ttl)
+}
+
+// Exists 键是否存在
+func (f *FaultyCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := f.inject(ctx); err != nil {
+		return false, err
+	}
+	return cache.Extend(f.Cache).Exists(ctx, key)
+}
+
+// SetNX 仅在键不存在时设置数据
+func (f *FaultyCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	if err := f.inject(ctx); err != nil {
+		return false, err
+	}
+	return cache.Extend(f.Cache).SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签
+func (f *FaultyCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 删除标签下的所有键
+func (f *FaultyCache) InvalidateTag(ctx context.Context, tag string) (*cache.TagInvalidationReport, error) {
+	if err := f.inject(ctx); err != nil {
+		return nil, err
+	}
+	return cache.Extend(f.Cache).InvalidateTag(ctx, tag)
+}
+
+// PreviewInvalidateTag 返回标签下的键
+func (f *FaultyCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	if err := f.inject(ctx); err != nil {
+		return nil, err
+	}
+	return cache.Extend(f.Cache).PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除匹配模式的键
+func (f *FaultyCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := f.inject(ctx); err != nil {
+		return 0, err
+	}
+	return cache.Extend(f.Cache).DelPattern(ctx, pattern)
+}
+
+// Copy 复制键到目标键
+func (f *FaultyCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键
+func (f *FaultyCache) Rename(ctx context.Context, src, dst string) error {
+	if err := f.inject(ctx); err != nil {
+		return err
+	}
+	return cache.Extend(f.Cache).Rename(ctx, src, dst)
+}
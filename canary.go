@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+)
+
+// CanaryResult 编码对比结果
+type CanaryResult struct {
+	// Type 被编码的值类型
+	Type string
+	// Match 两种编码往返解码后的结果是否一致
+	Match bool
+	// PrimarySize 主编码的数据大小
+	PrimarySize int
+	// CandidateSize 候选编码的数据大小
+	CandidateSize int
+	// Err 候选编码编码或解码失败的错误
+	Err error
+}
+
+// CanaryReportFunc 编码对比结果上报函数，通常用于记录指标
+// 注意：该函数会在写入路径上同步调用，必须是线程安全且轻量的
+type CanaryReportFunc func(result CanaryResult)
+
+// codecCanary 编码对比配置
+type codecCanary struct {
+	candidate  Encoding
+	sampleRate float64
+	report     CanaryReportFunc
+}
+
+// WithCodecCanary 开启编码对比模式
+// 按sampleRate比例对写入的值同时使用主编码和候选编码进行往返编解码并比较结果，
+// 通过report上报差异，用于在切换编码前验证新编码的兼容性，不影响实际写入的数据
+func WithCodecCanary(candidate Encoding, sampleRate float64, report CanaryReportFunc) CacheOption {
+	return func(o *cacheOptions) {
+		if candidate == nil || report == nil || sampleRate <= 0 {
+			return
+		}
+		o.canary = &codecCanary{
+			candidate:  candidate,
+			sampleRate: sampleRate,
+			report:     report,
+		}
+	}
+}
+
+// compare 对值进行抽样对比，primaryData为主编码的结果
+func (c *codecCanary) compare(primary Encoding, v interface{}, primaryData []byte) {
+	if rand.Float64() >= c.sampleRate {
+		return
+	}
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return
+	}
+	result := CanaryResult{Type: t.String(), PrimarySize: len(primaryData)}
+	defer func() {
+		c.report(result)
+	}()
+
+	primaryValue := reflect.New(t.Elem()).Interface()
+	if err := Unmarshal(primary, primaryData, primaryValue); err != nil {
+		result.Err = fmt.Errorf("主编码往返解码错误: %w", err)
+		return
+	}
+	candidateData, err := Marshal(c.candidate, v)
+	if err != nil {
+		result.Err = fmt.Errorf("候选编码编码错误: %w", err)
+		return
+	}
+	result.CandidateSize = len(candidateData)
+	candidateValue := reflect.New(t.Elem()).Interface()
+	if err = Unmarshal(c.candidate, candidateData, candidateValue); err != nil {
+		result.Err = fmt.Errorf("候选编码解码错误: %w", err)
+		return
+	}
+	result.Match = reflect.DeepEqual(primaryValue, candidateValue)
+}
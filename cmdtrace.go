@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CommandRecord 一条发往Redis的命令记录，键是加前缀、哈希标签等转换之后的最终键
+// 只记录命令名称和键，不记录值，避免敏感数据进入日志
+type CommandRecord struct {
+	// Command 命令名称，如get、set、evalsha
+	Command string `json:"command"`
+	// Keys 命令访问的键
+	Keys []string `json:"keys,omitempty"`
+	// Pipeline 是否通过管道发送
+	Pipeline bool `json:"pipeline,omitempty"`
+	// Duration 命令耗时，管道中为整个管道的耗时
+	Duration time.Duration `json:"duration"`
+	// Err 命令错误，未找到(redis.Nil)不记为错误
+	Err error `json:"-"`
+}
+
+// CommandTraceFunc 命令记录的接收函数，ctx为发起命令的上下文，可从中取出span或请求ID
+// 在执行命令的goroutine中同步调用，实现必须是线程安全的并且应尽快返回
+type CommandTraceFunc func(ctx context.Context, rec CommandRecord)
+
+// commandTraceHook 记录Redis命令的钩子，将命令作为事件写入当前span，并调用接收函数
+type commandTraceHook struct {
+	fn CommandTraceFunc
+}
+
+// NewCommandTraceHook 创建记录Redis命令的钩子，通过client.AddHook添加
+// 每条命令作为redis.command事件写入上下文中的span，fn不为空时同时调用fn，用于调试键转换问题
+func NewCommandTraceHook(fn CommandTraceFunc) redis.Hook {
+	return &commandTraceHook{fn: fn}
+}
+
+// DialHook 不记录连接
+func (h *commandTraceHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 记录单条命令
+func (h *commandTraceHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(ctx, cmd, false, time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook 逐条记录管道中的命令
+func (h *commandTraceHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		d := time.Since(start)
+		for _, cmd := range cmds {
+			h.record(ctx, cmd, true, d)
+		}
+		return err
+	}
+}
+
+// record 记录命令
+func (h *commandTraceHook) record(ctx context.Context, cmd redis.Cmder, pipeline bool, d time.Duration) {
+	rec := CommandRecord{
+		Command:  strings.ToLower(cmd.FullName()),
+		Keys:     commandKeys(cmd.Args()),
+		Pipeline: pipeline,
+		Duration: d,
+	}
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		rec.Err = err
+	}
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		attrs := []attribute.KeyValue{
+			attribute.String("db.operation", rec.Command),
+			attribute.StringSlice("db.redis.keys", rec.Keys),
+			attribute.Bool("db.redis.pipeline", rec.Pipeline),
+			attribute.Int64("db.redis.duration_us", rec.Duration.Microseconds()),
+		}
+		if rec.Err != nil {
+			attrs = append(attrs, attribute.String("error", rec.Err.Error()))
+		}
+		span.AddEvent("redis.command", trace.WithAttributes(attrs...))
+	}
+	if h.fn != nil {
+		h.fn(ctx, rec)
+	}
+}
+
+// commandKeys 按命令格式取出参数中的键，未知命令按第一个参数是键处理
+func commandKeys(args []interface{}) []string {
+	if len(args) < 2 {
+		return nil
+	}
+	name := strings.ToLower(fmt.Sprint(args[0]))
+	switch name {
+	case "ping", "info", "scan", "script", "memory", "client", "cluster", "config", "hello", "auth", "select",
+		"publish", "subscribe", "unsubscribe", "psubscribe", "punsubscribe", "flushdb", "flushall", "dbsize":
+		return nil
+	case "mget", "del", "unlink", "exists", "touch", "watch":
+		return argStrings(args[1:])
+	case "mset", "msetnx":
+		keys := make([]string, 0, len(args)/2)
+		for i := 1; i < len(args); i += 2 {
+			keys = append(keys, fmt.Sprint(args[i]))
+		}
+		return keys
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		if len(args) < 3 {
+			return nil
+		}
+		n, err := strconv.Atoi(fmt.Sprint(args[2]))
+		if err != nil || n <= 0 || 3+n > len(args) {
+			return nil
+		}
+		return argStrings(args[3 : 3+n])
+	default:
+		return []string{fmt.Sprint(args[1])}
+	}
+}
+
+// argStrings 将参数转换为字符串
+func argStrings(args []interface{}) []string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = fmt.Sprint(arg)
+	}
+	return s
+}
+
+// addCommandTraceHook 启用TraceCommands时为客户端添加命令记录钩子
+func (c *Config) addCommandTraceHook(client redis.UniversalClient) {
+	if c.TraceCommands {
+		client.AddHook(NewCommandTraceHook(c.CommandTrace))
+	}
+}
@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CacheOption 缓存实例选项
+type CacheOption func(*cacheOptions)
+
+// cacheOptions 缓存实例的通用选项，各缓存实现通过嵌入该结构共享行为
+type cacheOptions struct {
+	valueCodec
+	ttlPolicy
+	// slidingTTL 滑动过期时间，大于0时每次成功Get刷新过期时间
+	slidingTTL time.Duration
+	// unlink Redis删除时使用UNLINK代替DEL
+	unlink bool
+	// pipelining Redis批量设置和批量删除是否使用管道
+	pipelining bool
+	// proxy Redis代理模式，代理不支持的命令替换为等价命令
+	proxy ProxyMode
+	// sampler 键访问采样器，为空时不采样
+	sampler *AccessSampler
+	// flight 合并GetOrSet对同一个键的并发加载
+	flight *flightGroup
+	// skew 写入偏差检测配置，为空时不检测
+	skew *skewCheck
+	// pool 批量获取解码目标对象的对象池，为空时使用newObject创建
+	pool *sync.Pool
+	// decodeWorkers 批量获取解码的最大并发数，不大于1时顺序解码
+	decodeWorkers int
+	// asyncWrites 内存缓存写入后不等待缓冲生效
+	asyncWrites bool
+	// noPlaceholder 禁用未找到占位符，空数据按原样写入和读取
+	noPlaceholder bool
+	// placeholder 未找到占位符，为空时使用包级NotFoundPlaceholderBytes
+	placeholder []byte
+	// validator 解码后的校验函数，为空时不校验
+	validator func(key string, v interface{}) error
+	// refreshError 提前刷新失败的回调，为空时忽略
+	refreshError func(key string, err error)
+}
+
+// newCacheOptions 根据配置和选项创建缓存实例选项
+func newCacheOptions(config *Config, encoding Encoding, opts []CacheOption) cacheOptions {
+	o := cacheOptions{
+		valueCodec: valueCodec{
+			encoding:       encoding,
+			envelope:       config.Envelope,
+			envelopePolicy: config.EnvelopePolicy,
+		},
+		ttlPolicy:  newTTLPolicy(config),
+		unlink:     config.UseUnlink,
+		pipelining: config.compat().pipelining(),
+		proxy:      config.proxyMode(),
+		sampler:    config.AccessSampler,
+		flight:     &flightGroup{},
+
+		noPlaceholder: config.DisableNotFoundPlaceholder,
+	}
+	if config.NotFoundPlaceholder != "" {
+		o.placeholder = []byte(config.NotFoundPlaceholder)
+	}
+	o.apply(opts...)
+	if config.ReadYourWrites {
+		o.asyncWrites = false
+	}
+	return o
+}
+
+// apply 应用选项
+func (o *cacheOptions) apply(opts ...CacheOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithFallbackEncoding 设置回退编码，主编码解码失败时尝试使用旧编码解码
+// 用于编码迁移(如JSON迁移到msgpack)期间无需清空缓存
+func WithFallbackEncoding(old Encoding) CacheOption {
+	return func(o *cacheOptions) {
+		o.fallback = old
+	}
+}
+
+// WithFallbackRewrite 使用回退编码解码成功后，用主编码重写该条目并保留剩余过期时间
+func WithFallbackRewrite() CacheOption {
+	return func(o *cacheOptions) {
+		o.rewriteFallback = true
+	}
+}
+
+// WithUnlink Redis删除时使用UNLINK代替DEL，大值在后台线程释放，不会阻塞Redis事件循环
+func WithUnlink() CacheOption {
+	return func(o *cacheOptions) {
+		o.unlink = true
+	}
+}
+
+// redisDel 删除Redis中的键，根据选项使用UNLINK或DEL，代理不支持UNLINK时使用DEL
+func (o *cacheOptions) redisDel(ctx context.Context, client redis.Cmdable, cacheKeys ...string) *redis.IntCmd {
+	if o.unlink && o.proxy.supports("unlink") {
+		return client.Unlink(ctx, cacheKeys...)
+	}
+	return client.Del(ctx, cacheKeys...)
+}
+
+// placeholderEnabled 是否启用未找到占位符
+func (o *cacheOptions) placeholderEnabled() bool {
+	return !o.noPlaceholder
+}
+
+// placeholderBytes 写入的未找到占位符
+func (o *cacheOptions) placeholderBytes() []byte {
+	if o.placeholder != nil {
+		return o.placeholder
+	}
+	return NotFoundPlaceholderBytes
+}
+
+// isPlaceholder 数据是否为未找到占位符，禁用占位符时空数据也按普通数据处理
+func (o *cacheOptions) isPlaceholder(data []byte) bool {
+	if o.noPlaceholder {
+		return false
+	}
+	return len(data) == 0 || bytes.Equal(data, o.placeholderBytes())
+}
+
+// WithNotFoundPlaceholder 设置当前缓存实例的未找到占位符，覆盖包级NotFoundPlaceholder
+// 与其他服务共享键且默认占位符"*"可能是合法的编码结果时使用，placeholder为空时使用默认占位符
+func WithNotFoundPlaceholder(placeholder string) CacheOption {
+	return func(o *cacheOptions) {
+		o.placeholder = nil
+		if placeholder != "" {
+			o.placeholder = []byte(placeholder)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// valueCodec 缓存值编解码器，在Encoding基础上处理信封、回退编码等通用逻辑
+type valueCodec struct {
+	encoding Encoding
+	// envelope 写入时是否使用信封
+	envelope bool
+	// envelopePolicy 旧格式或新版本信封的处理策略
+	envelopePolicy EnvelopePolicy
+	// fallback 回退编码
+	fallback Encoding
+	// rewriteFallback 回退解码成功后是否用主编码重写
+	rewriteFallback bool
+	// canary 编码对比配置
+	canary *codecCanary
+}
+
+// encode 编码数据，空数据不封装，以便按占位符处理
+// 提前过期模式的值总是封装，以便记录加载耗时和过期时间；RawValue不编码
+func (vc *valueCodec) encode(v interface{}) ([]byte, error) {
+	var extra []envelopeField
+	if e, ok := v.(*earlyEntry); ok {
+		v, extra = e.value, e.fields()
+	}
+	buf, isRaw := rawBytes(v)
+	if isRaw {
+		// 内存缓存直接保存写入的切片，复制一份避免调用方修改
+		buf = bytes.Clone(buf)
+	} else {
+		var err error
+		if buf, err = Marshal(vc.encoding, v); err != nil {
+			return nil, err
+		}
+		if vc.canary != nil && len(buf) > 0 {
+			vc.canary.compare(vc.encoding, v, buf)
+		}
+	}
+	if (vc.envelope || len(extra) > 0) && len(buf) > 0 {
+		buf = sealEnvelope(buf, append(append(vc.codecFields(), sourceFields(v)...), extra...)...)
+	}
+	return buf, nil
+}
+
+// codecFields 记录编码名称的信封头部字段，编码未实现Name方法时为空
+func (vc *valueCodec) codecFields() []envelopeField {
+	named, ok := vc.encoding.(interface{ Name() string })
+	if !ok || named.Name() == "" || len(named.Name()) > 255 {
+		return nil
+	}
+	return []envelopeField{{tag: envelopeTagCodec, value: []byte(named.Name())}}
+}
+
+// sourceFields 记录数据源更新时间的信封头部字段，值未实现SourceTimestamped时为空
+func sourceFields(v interface{}) []envelopeField {
+	ts := sourceUpdatedAt(v)
+	if ts.IsZero() {
+		return nil
+	}
+	return []envelopeField{{tag: envelopeTagSourceUpdatedAt, value: binary.BigEndian.AppendUint64(nil, uint64(ts.UnixMilli()))}}
+}
+
+// decode 解码数据
+// 按信封策略视为未命中时返回ErrCacheNotFound，调用方应原样返回
+func (vc *valueCodec) decode(data []byte, v interface{}) error {
+	_, err := vc.decodeValue(data, v)
+	return err
+}
+
+// decodeValue 解码数据，needRewrite表示使用了回退编码且需要用主编码重写
+func (vc *valueCodec) decodeValue(data []byte, v interface{}) (needRewrite bool, err error) {
+	if e, ok := v.(*earlyEntry); ok {
+		v = e.value
+		if env, ok, _ := openEnvelope(data); ok {
+			e.load(env)
+		}
+	}
+	payload, err := vc.open(data)
+	if err != nil {
+		return false, err
+	}
+	if raw, ok := v.(*RawValue); ok {
+		*raw = bytes.Clone(payload)
+		return false, nil
+	}
+	err = Unmarshal(vc.encoding, payload, v)
+	if err == nil || vc.fallback == nil {
+		return false, err
+	}
+	if fallbackErr := Unmarshal(vc.fallback, payload, v); fallbackErr != nil {
+		return false, errors.Join(err, fmt.Errorf("回退编码解码错误: %w", fallbackErr))
+	}
+	return vc.rewriteFallback, nil
+}
+
+// open 解析信封，返回实际数据
+// 读取时总是识别信封，即使本实例未启用写入信封，以兼容滚动发布期间新版本写入的数据
+func (vc *valueCodec) open(data []byte) ([]byte, error) {
+	env, ok, err := openEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !ok && !vc.envelope:
+		// 未启用信封时旧格式即当前格式
+		return data, nil
+	case !ok:
+		if err := vc.incompatible("旧格式数据"); err != nil {
+			return nil, err
+		}
+		return data, nil
+	case env.version > envelopeVersion:
+		if err := vc.incompatible(fmt.Sprintf("信封版本%d高于当前版本%d", env.version, envelopeVersion)); err != nil {
+			return nil, err
+		}
+	}
+	return env.payload, nil
+}
+
+// incompatible 根据策略处理不兼容的数据格式，返回nil表示继续解码
+func (vc *valueCodec) incompatible(reason string) error {
+	switch vc.envelopePolicy {
+	case EnvelopeTreatAsMiss:
+		return ErrCacheNotFound
+	case EnvelopeError:
+		return fmt.Errorf("%w: %s", ErrEnvelopeVersion, reason)
+	default:
+		return nil
+	}
+}
+
+// isMiss 是否为未命中错误，第三方存储和加载函数可能直接返回redis.Nil
+func isMiss(err error) bool {
+	return errors.Is(err, ErrCacheNotFound) || errors.Is(err, redis.Nil)
+}
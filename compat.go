@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrIncompatible 服务端不支持当前配置需要的命令或特性
+var ErrIncompatible = errors.New("服务端不兼容")
+
+// ServerFlavor Redis协议兼容的服务端类型
+type ServerFlavor string
+
+const (
+	// FlavorRedis Redis
+	FlavorRedis ServerFlavor = "redis"
+	// FlavorValkey Valkey
+	FlavorValkey ServerFlavor = "valkey"
+	// FlavorDragonfly Dragonfly
+	FlavorDragonfly ServerFlavor = "dragonfly"
+)
+
+// CompatConfig 服务端兼容性配置，用于Valkey、Dragonfly等Redis协议兼容的服务端
+type CompatConfig struct {
+	// Flavor 期望的服务端类型，为空时不限制，开启探测后与实际类型不一致时连接失败
+	// 设置为dragonfly时不使用CLIENT TRACKING
+	Flavor ServerFlavor `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+	// DisableClientTracking 不使用也不探测CLIENT TRACKING，依赖它的客户端缓存功能不可用
+	DisableClientTracking bool `json:"disable_client_tracking,omitempty" yaml:"disable_client_tracking,omitempty"`
+	// DisableIdentity 建立连接时不发送CLIENT SETINFO
+	DisableIdentity bool `json:"disable_identity,omitempty" yaml:"disable_identity,omitempty"`
+	// DisablePipelining 批量设置和批量删除逐条发送命令，不使用管道
+	DisablePipelining bool `json:"disable_pipelining,omitempty" yaml:"disable_pipelining,omitempty"`
+	// ProbeFeatures Connect时探测服务端类型、版本和命令支持，缺少当前配置需要的命令时返回ErrIncompatible
+	ProbeFeatures bool `json:"probe_features,omitempty" yaml:"probe_features,omitempty"`
+}
+
+// clientTracking 是否允许使用CLIENT TRACKING
+func (c *CompatConfig) clientTracking() bool {
+	if c == nil {
+		return true
+	}
+	return !c.DisableClientTracking && c.Flavor != FlavorDragonfly
+}
+
+// disableIdentity 是否禁用CLIENT SETINFO
+func (c *CompatConfig) disableIdentity() bool {
+	return c != nil && c.DisableIdentity
+}
+
+// pipelining 是否使用管道
+func (c *CompatConfig) pipelining() bool {
+	return c == nil || !c.DisablePipelining
+}
+
+// WithoutPipelining Redis批量设置和批量删除逐条发送命令，用于不支持管道的服务端或代理
+func WithoutPipelining() CacheOption {
+	return func(o *cacheOptions) {
+		o.pipelining = false
+	}
+}
+
+// redisSetEach 逐条设置键值对，paris为MultiSet构造的键值对，每个键的过期时间单独抖动
+func (o *cacheOptions) redisSetEach(ctx context.Context, client redis.Cmdable, paris []interface{}, expiration time.Duration) error {
+	for i := 0; i+1 < len(paris); i += 2 {
+		key, _ := paris[i].([]byte)
+		if err := client.Set(ctx, string(key), paris[i+1], o.jittered(expiration)).Err(); err != nil {
+			return fmt.Errorf("%w: 逐条设置错误: %w, 缓存键=%s", ErrBackend, err, key)
+		}
+	}
+	return nil
+}
+
+// compat 获取当前缓存类型的兼容性配置
+func (c *Config) compat() *CompatConfig {
+	switch {
+	case c.Type == RedisCache && c.Redis != nil:
+		return c.Redis.Compat
+	case c.Type == RedisClusterCache && c.RedisCluster != nil:
+		return c.RedisCluster.Compat
+	}
+	return nil
+}
+
+// ServerFeatures 探测到的服务端特性
+type ServerFeatures struct {
+	// Flavor 服务端类型
+	Flavor ServerFlavor `json:"flavor"`
+	// Version 服务端版本
+	Version string `json:"version"`
+	// Protocol 连接使用的RESP协议版本
+	Protocol int `json:"protocol"`
+	// ClientTracking 是否支持CLIENT TRACKING，禁用或使用RESP2时为false
+	ClientTracking bool `json:"client_tracking"`
+	// commands 服务端支持的命令，小写
+	commands map[string]bool
+}
+
+// Supports 服务端是否支持该命令
+func (f *ServerFeatures) Supports(command string) bool {
+	return f.commands[strings.ToLower(command)]
+}
+
+// ProbeServer 探测服务端类型、版本和支持的命令
+// clientTracking为true时通过CLIENT TRACKING off探测是否支持客户端缓存
+func ProbeServer(ctx context.Context, client redis.UniversalClient, clientTracking bool) (*ServerFeatures, error) {
+	info, err := client.Info(ctx, "server").Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 获取服务端信息错误: %w", ErrBackend, err)
+	}
+	f := parseServerInfo(info)
+
+	commands, err := client.Command(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 获取命令列表错误: %w", ErrBackend, err)
+	}
+	f.commands = make(map[string]bool, len(commands))
+	for name := range commands {
+		f.commands[strings.ToLower(name)] = true
+	}
+
+	if clientTracking && f.Supports("client") {
+		f.ClientTracking = client.Do(ctx, "CLIENT", "TRACKING", "off").Err() == nil
+	}
+	return f, nil
+}
+
+// parseInfoFields 解析INFO输出中的字段，忽略分节标题
+func parseInfoFields(info string) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		if k, v, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":"); ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// parseServerInfo 解析INFO server的输出
+// Valkey和Dragonfly同时返回redis_version用于兼容，需要优先识别各自的版本字段
+func parseServerInfo(info string) *ServerFeatures {
+	fields := parseInfoFields(info)
+	switch {
+	case fields["dragonfly_version"] != "":
+		return &ServerFeatures{Flavor: FlavorDragonfly, Version: fields["dragonfly_version"]}
+	case fields["valkey_version"] != "":
+		return &ServerFeatures{Flavor: FlavorValkey, Version: fields["valkey_version"]}
+	case fields["server_name"] == "valkey":
+		return &ServerFeatures{Flavor: FlavorValkey, Version: fields["redis_version"]}
+	default:
+		return &ServerFeatures{Flavor: FlavorRedis, Version: fields["redis_version"]}
+	}
+}
+
+// requiredCommands 当前选项需要服务端支持的命令
+func (o *cacheOptions) requiredCommands() []string {
+	commands := []string{"get", "set", "mget", "mset", "del", "expire", "eval"}
+	if o.slidingTTL > 0 {
+		commands = append(commands, "getex")
+	}
+	if o.unlink {
+		commands = append(commands, "unlink")
+	}
+	return commands
+}
+
+// compatProbe 提供者连接时的特性探测
+type compatProbe struct {
+	config   *CompatConfig
+	protocol int
+	required []string
+	mu       sync.Mutex
+	features *ServerFeatures
+}
+
+// newCompatProbe 创建特性探测，未开启探测时返回nil
+func newCompatProbe(config *Config, options cacheOptions) *compatProbe {
+	compat := config.compat()
+	if compat == nil || !compat.ProbeFeatures {
+		return nil
+	}
+	return &compatProbe{config: compat, protocol: config.protocol(), required: options.requiredCommands()}
+}
+
+// run 探测服务端特性并检查是否满足配置要求
+func (p *compatProbe) run(ctx context.Context, client redis.UniversalClient) error {
+	if p == nil {
+		return nil
+	}
+	// RESP2下CLIENT TRACKING需要额外的重定向连接，只在RESP3下使用
+	f, err := ProbeServer(ctx, client, p.config.clientTracking() && p.protocol == ProtocolRESP3)
+	if err != nil {
+		return err
+	}
+	f.Protocol = p.protocol
+	if p.config.Flavor != "" && p.config.Flavor != f.Flavor {
+		return fmt.Errorf("%w: 期望服务端类型为%s, 实际为%s %s", ErrIncompatible, p.config.Flavor, f.Flavor, f.Version)
+	}
+	var missing []string
+	for _, command := range p.required {
+		if !f.Supports(command) {
+			missing = append(missing, command)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s %s不支持命令%v", ErrIncompatible, f.Flavor, f.Version, missing)
+	}
+	p.mu.Lock()
+	p.features = f
+	p.mu.Unlock()
+	return nil
+}
+
+// get 获取最近一次探测的结果
+func (p *compatProbe) get() *ServerFeatures {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.features
+}
+
+// ServerFeatures 获取Connect时探测到的服务端特性，未开启探测或尚未连接时返回nil
+func (p *redisProvider) ServerFeatures() *ServerFeatures {
+	return p.probe.get()
+}
+
+// ServerFeatures 获取Connect时探测到的服务端特性，集群模式下为最后探测的主节点，未开启探测或尚未连接时返回nil
+func (p *redisClusterProvider) ServerFeatures() *ServerFeatures {
+	return p.probe.get()
+}
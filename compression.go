@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression 压缩算法
+type Compression string
+
+const (
+	// CompressionGzip gzip压缩，兼容性最好
+	CompressionGzip Compression = "gzip"
+	// CompressionSnappy snappy压缩，速度最快，压缩率较低
+	CompressionSnappy Compression = "snappy"
+	// CompressionZstd zstd压缩，压缩率和速度比较均衡
+	CompressionZstd Compression = "zstd"
+)
+
+// compressionMagic 压缩数据的头部标记，后面是1字节的算法标识
+// 0xff不是JSON的合法开头，作为单个msgpack值时只有1字节，不会与未压缩的数据混淆
+var compressionMagic = []byte{0xff, 'c', 'z'}
+
+// 压缩算法标识，写入头部，不能修改
+const (
+	compressionIDGzip   byte = 1
+	compressionIDSnappy byte = 2
+	compressionIDZstd   byte = 3
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// zstdCodec 获取共享的zstd编解码器，EncodeAll和DecodeAll可以并发调用
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder, zstdErr
+}
+
+// compressionEncoding 压缩编码包装
+type compressionEncoding struct {
+	encoding Encoding
+	id       byte
+	algo     Compression
+	minSize  int
+}
+
+// WithCompression 为编码添加压缩，编码后不小于minSize字节的值使用algo压缩并写入头部
+// 读取时根据头部自动识别是否压缩及压缩算法，因此可以随时开启、关闭或更换算法，已有的数据仍然可读
+func WithCompression(e Encoding, algo Compression, minSize int) (Encoding, error) {
+	var id byte
+	switch algo {
+	case CompressionGzip:
+		id = compressionIDGzip
+	case CompressionSnappy:
+		id = compressionIDSnappy
+	case CompressionZstd:
+		id = compressionIDZstd
+	default:
+		return nil, fmt.Errorf("不支持的压缩算法: %s", algo)
+	}
+	if algo == CompressionZstd {
+		if _, _, err := zstdCodec(); err != nil {
+			return nil, fmt.Errorf("创建zstd编解码器失败: %w", err)
+		}
+	}
+	return &compressionEncoding{encoding: e, id: id, algo: algo, minSize: minSize}, nil
+}
+
+// Marshal 编码数据，达到阈值时压缩
+func (c *compressionEncoding) Marshal(v interface{}) ([]byte, error) {
+	data, err := Marshal(c.encoding, v)
+	if err != nil || len(data) < c.minSize || len(data) == 0 {
+		return data, err
+	}
+	compressed, err := compress(c.id, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s压缩失败: %w", c.algo, err)
+	}
+	// 压缩后没有变小时保存原始数据
+	if len(compressed) >= len(data) {
+		return data, nil
+	}
+	return compressed, nil
+}
+
+// Unmarshal 解码数据，带压缩头部时先解压
+func (c *compressionEncoding) Unmarshal(data []byte, v interface{}) error {
+	data, err := decompress(data)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(c.encoding, data, v)
+}
+
+// Name 编码名称，与内层编码相同，压缩只影响存储格式
+func (c *compressionEncoding) Name() string {
+	if named, ok := c.encoding.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// compress 压缩数据并写入头部
+func compress(id byte, data []byte) ([]byte, error) {
+	header := append(append(make([]byte, 0, len(compressionMagic)+1+len(data)/2), compressionMagic...), id)
+	switch id {
+	case compressionIDGzip:
+		buf := bytes.NewBuffer(header)
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case compressionIDSnappy:
+		return append(header, s2.EncodeSnappy(nil, data)...), nil
+	case compressionIDZstd:
+		enc, _, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(data, header), nil
+	default:
+		return nil, fmt.Errorf("未知的压缩算法标识: %d", id)
+	}
+}
+
+// isCompressed 数据是否带压缩头部
+func isCompressed(data []byte) bool {
+	n := len(compressionMagic)
+	return len(data) > n && bytes.Equal(data[:n], compressionMagic)
+}
+
+// decompress 根据头部解压数据，没有压缩头部时原样返回
+func decompress(data []byte) ([]byte, error) {
+	if !isCompressed(data) {
+		return data, nil
+	}
+	n := len(compressionMagic)
+	id, payload := data[n], data[n+1:]
+	switch id {
+	case compressionIDGzip:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("gzip解压失败: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("gzip解压失败: %w", err)
+		}
+		return out, nil
+	case compressionIDSnappy:
+		out, err := s2.Decode(nil, payload)
+		if err != nil {
+			return nil, fmt.Errorf("snappy解压失败: %w", err)
+		}
+		return out, nil
+	case compressionIDZstd:
+		_, dec, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		out, err := dec.DecodeAll(payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd解压失败: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("未知的压缩算法标识: %d", id)
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	random := make([]byte, 512)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+	tests := []struct {
+		name       string
+		algo       Compression
+		value      string
+		compressed bool
+	}{
+		{"gzip", CompressionGzip, strings.Repeat("gzip", 100), true},
+		{"snappy", CompressionSnappy, strings.Repeat("snappy", 100), true},
+		{"zstd", CompressionZstd, strings.Repeat("zstd", 100), true},
+		{"below min size", CompressionZstd, "small", false},
+		// 随机数据压缩后不会变小，保存原始数据
+		{"incompressible", CompressionGzip, hex.EncodeToString(random), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := WithCompression(&JSONEncoding{}, tt.algo, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := encoding.Marshal(&tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if isCompressed(data) != tt.compressed {
+				t.Errorf("isCompressed() = %v, want %v", isCompressed(data), tt.compressed)
+			}
+			var got string
+			if err := encoding.Unmarshal(data, &got); err != nil || got != tt.value {
+				t.Errorf("Unmarshal() = %q, %v, want %q", got, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestCompressionReadsOtherAlgorithms(t *testing.T) {
+	value := strings.Repeat("mixed", 100)
+	reader, err := WithCompression(&JSONEncoding{}, CompressionGzip, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 更换算法或关闭压缩后，已有的数据仍然可读
+	writers := map[string]Encoding{"plain": &JSONEncoding{}}
+	for _, algo := range []Compression{CompressionSnappy, CompressionZstd} {
+		if writers[string(algo)], err = WithCompression(&JSONEncoding{}, algo, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, writer := range writers {
+		t.Run(name, func(t *testing.T) {
+			data, err := writer.Marshal(&value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if err := reader.Unmarshal(data, &got); err != nil || got != value {
+				t.Errorf("Unmarshal() = %q, %v, want %q", got, err, value)
+			}
+		})
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	if _, err := WithCompression(&JSONEncoding{}, "lz4", 0); err == nil {
+		t.Error("WithCompression(lz4) error = nil, want unsupported")
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown id", append(append([]byte{}, compressionMagic...), 9, 1, 2)},
+		{"corrupt gzip", append(append([]byte{}, compressionMagic...), compressionIDGzip, 1, 2, 3)},
+		{"corrupt zstd", append(append([]byte{}, compressionMagic...), compressionIDZstd, 1, 2, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decompress(tt.data); err == nil {
+				t.Error("decompress() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package consul 基于Consul KV的缓存后端
+// Consul KV没有原生过期时间，通过带删除行为的会话模拟：会话过期后Consul自动删除其持有的键，
+// 同时将过期时间写入KVPair.Flags，读取时精确判断过期，避免会话过期延迟导致读到过期数据
+package consul
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/smart-unicom/cache"
+)
+
+const (
+	// maxTxnOps Consul单个事务允许的最大操作数
+	maxTxnOps = 64
+	// minSessionTTL Consul会话允许的最小过期时间
+	minSessionTTL = 10 * time.Second
+	// maxSessionTTL Consul会话允许的最大过期时间
+	maxSessionTTL = 24 * time.Hour
+)
+
+// Store Consul KV存储，实现cache.Store
+type Store struct {
+	client *api.Client
+	now    func() time.Time
+}
+
+var _ cache.Store = (*Store)(nil)
+
+// NewStore 创建Consul KV存储
+func NewStore(client *api.Client) *Store {
+	return &Store{client: client, now: time.Now}
+}
+
+// NewCache 创建Consul KV缓存
+func NewCache(client *api.Client, keyPrefix string, encode cache.Encoding, newObject func() interface{}, opts ...cache.CacheOption) cache.Cache {
+	return cache.NewStoreCache(NewStore(client), keyPrefix, encode, newObject, opts...)
+}
+
+// expired 判断键值对是否已过期，Flags为0表示永不过期
+func (s *Store) expired(kv *api.KVPair) bool {
+	return kv.Flags != 0 && s.now().UnixMilli() >= int64(kv.Flags)
+}
+
+// Get 获取值，已过期但尚未被会话删除的键视为未找到
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	kv, _, err := s.client.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil || s.expired(kv) {
+		return nil, cache.ErrCacheNotFound
+	}
+	return kv.Value, nil
+}
+
+// Set 设置值
+// ttl大于0时创建会话持有该键，会话过期后键被删除；Consul会话过期时间范围为10秒到24小时，
+// 超出范围的部分由Flags中的过期时间保证读取语义，超过24小时的键会在24小时后被提前删除
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// 先删除再写入，释放旧会话对该键的持有
+	ops := api.KVTxnOps{{Verb: api.KVDelete, Key: key}}
+	if ttl <= 0 {
+		ops = append(ops, &api.KVTxnOp{Verb: api.KVSet, Key: key, Value: value})
+	} else {
+		sessionTTL := min(max(ttl, minSessionTTL), maxSessionTTL)
+		session, _, err := s.client.Session().Create(&api.SessionEntry{
+			Name:      "cache:" + key,
+			TTL:       sessionTTL.String(),
+			Behavior:  api.SessionBehaviorDelete,
+			LockDelay: time.Millisecond,
+		}, (&api.WriteOptions{}).WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("创建会话错误: %w", err)
+		}
+		ops = append(ops, &api.KVTxnOp{
+			Verb:    api.KVLock,
+			Key:     key,
+			Value:   value,
+			Flags:   uint64(s.now().Add(ttl).UnixMilli()),
+			Session: session,
+		})
+	}
+	return s.txn(ctx, ops)
+}
+
+// Del 删除值，按事务批量删除
+func (s *Store) Del(ctx context.Context, keys ...string) error {
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := min(start+maxTxnOps, len(keys))
+		ops := make(api.KVTxnOps, 0, end-start)
+		for _, key := range keys[start:end] {
+			ops = append(ops, &api.KVTxnOp{Verb: api.KVDelete, Key: key})
+		}
+		if err := s.txn(ctx, ops); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txn 执行事务
+func (s *Store) txn(ctx context.Context, ops api.KVTxnOps) error {
+	ok, resp, _, err := s.client.KV().Txn(ops, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if resp != nil && len(resp.Errors) > 0 {
+			return fmt.Errorf("事务执行失败: %s", resp.Errors[0].What)
+		}
+		return fmt.Errorf("事务执行失败")
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// buildCopyKeys 构建复制和重命名的源键与目标键
+func buildCopyKeys(keyPrefix, src, dst string) (string, string, error) {
+	srcKey, err := BuildCacheKey(keyPrefix, src)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, src)
+	}
+	dstKey, err := BuildCacheKey(keyPrefix, dst)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, dst)
+	}
+	return srcKey, dstKey, nil
+}
+
+// Copy 复制原始数据到目标键，目标键已存在时覆盖
+// 底层存储不支持复制，先读取源键再写入目标键，两步不是原子的
+func (m *memoryCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	srcKey, dstKey, err := buildCopyKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), src, dst)
+	if err != nil {
+		return err
+	}
+	return m.copyKey(srcKey, dstKey, dst, preserveTTL)
+}
+
+// Rename 复制原始数据和剩余过期时间到目标键后删除源键，两步不是原子的
+func (m *memoryCache) Rename(ctx context.Context, src, dst string) error {
+	srcKey, dstKey, err := buildCopyKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), src, dst)
+	if err != nil {
+		return err
+	}
+	if err = m.copyKey(srcKey, dstKey, dst, true); err != nil {
+		return err
+	}
+	if srcKey != dstKey {
+		m.client.Del(srcKey)
+	}
+	return nil
+}
+
+// copyKey 复制存储中的原始数据，不保留过期时间时按过期时间策略使用默认过期时间
+func (m *memoryCache) copyKey(srcKey, dstKey, dst string, preserveTTL bool) error {
+	data, ok := m.client.Get(srcKey)
+	if !ok {
+		return ErrCacheNotFound
+	}
+	var ttl time.Duration
+	if preserveTTL {
+		if ttl, ok = m.client.GetTTL(srcKey); !ok {
+			return ErrCacheNotFound
+		}
+	} else {
+		var err error
+		if ttl, err = m.expiration(0, dst); err != nil {
+			return err
+		}
+	}
+	if !m.client.SetWithTTL(dstKey, data, 0, ttl) {
+		return fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
+	}
+	if !m.asyncWrites {
+		m.client.Wait()
+	}
+	return nil
+}
+
+// Copy 使用COPY复制到目标键，目标键已存在时覆盖，需要Redis 6.2及以上
+// 代理模式下读取源键后写入目标键，两步不是原子的
+func (c *redisCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	return c.redisCopy(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst, preserveTTL, !c.proxy.supports("copy"))
+}
+
+// Rename 使用RENAME重命名，保留剩余过期时间，目标键已存在时覆盖
+// 代理模式下读取源键后写入目标键再删除源键，不是原子的
+func (c *redisCache) Rename(ctx context.Context, src, dst string) error {
+	return c.redisRename(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst, !c.proxy.supports("rename"))
+}
+
+// Copy 使用COPY复制到目标键，源键和目标键不在同一个槽时读取源键后写入目标键
+func (c *redisClusterCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	return c.redisCopy(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst, preserveTTL, false)
+}
+
+// Rename 使用RENAME重命名，源键和目标键不在同一个槽时读取源键后写入目标键再删除源键
+func (c *redisClusterCache) Rename(ctx context.Context, src, dst string) error {
+	return c.redisRename(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst, false)
+}
+
+// isCrossSlot 是否是集群模式下多键命令的跨槽错误
+func isCrossSlot(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "CROSSSLOT")
+}
+
+// redisCopy 使用COPY REPLACE复制，不保留过期时间时复制后重设目标键的过期时间
+// emulate为true或跨槽时改为读取源键后写入目标键
+func (o *cacheOptions) redisCopy(ctx context.Context, client redis.UniversalClient, keyPrefix, src, dst string, preserveTTL, emulate bool) error {
+	srcKey, dstKey, err := buildCopyKeys(keyPrefix, src, dst)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if !preserveTTL {
+		if ttl, err = o.expiration(0, dst); err != nil {
+			return err
+		}
+	}
+	if emulate {
+		return o.redisCopyEach(ctx, client, srcKey, dstKey, preserveTTL, ttl)
+	}
+
+	n, err := client.Do(ctx, "copy", srcKey, dstKey, "REPLACE").Int64()
+	if isCrossSlot(err) {
+		return o.redisCopyEach(ctx, client, srcKey, dstKey, preserveTTL, ttl)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: 客户端复制错误: %w, 源缓存键=%s, 目标缓存键=%s", ErrBackend, err, srcKey, dstKey)
+	}
+	if n == 0 {
+		return ErrCacheNotFound
+	}
+	switch {
+	case preserveTTL:
+		return nil
+	case ttl > 0:
+		err = client.PExpire(ctx, dstKey, ttl).Err()
+	default:
+		err = client.Persist(ctx, dstKey).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置过期时间错误: %w, 缓存键=%s", ErrBackend, err, dstKey)
+	}
+	return nil
+}
+
+// redisCopyEach 读取源键的值和剩余过期时间后写入目标键，preserveTTL为false时使用ttl
+func (o *cacheOptions) redisCopyEach(ctx context.Context, client redis.UniversalClient, srcKey, dstKey string, preserveTTL bool, ttl time.Duration) error {
+	var (
+		getCmd *redis.StringCmd
+		ttlCmd *redis.DurationCmd
+	)
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		getCmd = pipe.Get(ctx, srcKey)
+		ttlCmd = pipe.PTTL(ctx, srcKey)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%w: 客户端获取错误: %w, 缓存键=%s", ErrBackend, err, srcKey)
+	}
+	if preserveTTL {
+		switch ttl = ttlCmd.Val(); {
+		case ttl == -2:
+			// 读取后过期
+			return ErrCacheNotFound
+		case ttl < 0:
+			ttl = 0
+		}
+	}
+	data, _ := getCmd.Bytes()
+	if err = client.Set(ctx, dstKey, data, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, dstKey)
+	}
+	return nil
+}
+
+// redisRename 使用RENAME重命名，emulate为true或跨槽时改为复制后删除源键
+func (o *cacheOptions) redisRename(ctx context.Context, client redis.UniversalClient, keyPrefix, src, dst string, emulate bool) error {
+	srcKey, dstKey, err := buildCopyKeys(keyPrefix, src, dst)
+	if err != nil {
+		return err
+	}
+	if !emulate {
+		err = client.Rename(ctx, srcKey, dstKey).Err()
+		switch {
+		case err == nil:
+			return nil
+		case strings.Contains(err.Error(), "no such key"):
+			return ErrCacheNotFound
+		case !isCrossSlot(err):
+			return fmt.Errorf("%w: 客户端重命名错误: %w, 源缓存键=%s, 目标缓存键=%s", ErrBackend, err, srcKey, dstKey)
+		}
+	}
+	if err = o.redisCopyEach(ctx, client, srcKey, dstKey, true, 0); err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	if err = o.redisDel(ctx, client, srcKey).Err(); err != nil {
+		return fmt.Errorf("%w: 客户端删除错误: %w, 缓存键=%s", ErrBackend, err, srcKey)
+	}
+	return nil
+}
+
+// Copy 读取源键后写入目标键，两步不是原子的；保留过期时间需要存储实现StoreTTLGetter，否则返回ErrNotSupported
+func (c *storeCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	srcKey, dstKey, err := buildCopyKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst)
+	if err != nil {
+		return err
+	}
+	return c.copyKey(ctx, srcKey, dstKey, dst, preserveTTL)
+}
+
+// Rename 复制到目标键后删除源键，不是原子的；需要存储实现StoreTTLGetter，否则返回ErrNotSupported
+func (c *storeCache) Rename(ctx context.Context, src, dst string) error {
+	srcKey, dstKey, err := buildCopyKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), src, dst)
+	if err != nil {
+		return err
+	}
+	if err = c.copyKey(ctx, srcKey, dstKey, dst, true); err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	if err = c.store.Del(ctx, srcKey); err != nil {
+		return fmt.Errorf("%w: 存储删除错误: %w, 缓存键=%s", ErrBackend, err, srcKey)
+	}
+	return nil
+}
+
+// copyKey 读取存储中的原始数据后写入目标键
+func (c *storeCache) copyKey(ctx context.Context, srcKey, dstKey, dst string, preserveTTL bool) error {
+	var (
+		data []byte
+		ttl  time.Duration
+		err  error
+	)
+	if preserveTTL {
+		getter, ok := c.store.(StoreTTLGetter)
+		if !ok {
+			return fmt.Errorf("%w: 保留过期时间需要存储支持读取过期时间", ErrNotSupported)
+		}
+		data, ttl, err = getter.GetWithTTL(ctx, srcKey)
+	} else {
+		if ttl, err = c.expiration(0, dst); err != nil {
+			return err
+		}
+		data, err = c.store.Get(ctx, srcKey)
+	}
+	if err != nil {
+		if isMiss(err) {
+			return ErrCacheNotFound
+		}
+		return fmt.Errorf("%w: 存储获取错误: %w, 缓存键=%s", ErrBackend, err, srcKey)
+	}
+	if err = c.store.Set(ctx, dstKey, data, ttl); err != nil {
+		return fmt.Errorf("%w: 存储设置错误: %w, 缓存键=%s", ErrBackend, err, dstKey)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPasswordReloadInterval 默认的密码文件检查间隔
+const defaultPasswordReloadInterval = 10 * time.Second
+
+// filePassword 从密钥文件读取的密码，文件更新后新建立的连接自动使用新密码
+// 适用于Kubernetes Secret挂载、Vault Agent等定期轮换密码的场景
+type filePassword struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	password  string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// newFilePassword 创建密码文件读取器
+func newFilePassword(path string, interval time.Duration) *filePassword {
+	if interval <= 0 {
+		interval = defaultPasswordReloadInterval
+	}
+	return &filePassword{path: path, interval: interval}
+}
+
+// credentials 实现go-redis的CredentialsProviderContext
+// 每次建立新连接时调用，距上次检查超过interval时根据文件修改时间重新加载
+func (f *filePassword) credentials(_ context.Context) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if !f.checkedAt.IsZero() && now.Sub(f.checkedAt) < f.interval {
+		return "", f.password, nil
+	}
+	f.checkedAt = now
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if f.password != "" {
+			// 轮换过程中文件可能短暂不可用，继续使用旧密码
+			return "", f.password, nil
+		}
+		return "", "", fmt.Errorf("读取密码文件失败: %w", err)
+	}
+	if info.ModTime().Equal(f.modTime) && f.password != "" {
+		return "", f.password, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if f.password != "" {
+			return "", f.password, nil
+		}
+		return "", "", fmt.Errorf("读取密码文件失败: %w", err)
+	}
+	f.password = strings.TrimSpace(string(data))
+	f.modTime = info.ModTime()
+	return "", f.password, nil
+}
+
+// credentialsProvider 根据配置选择凭证提供函数，CredentialsProvider优先于PasswordFile
+func credentialsProvider(provider func(ctx context.Context) (string, string, error), passwordFile string,
+	interval time.Duration) func(ctx context.Context) (string, string, error) {
+	if provider != nil {
+		return provider
+	}
+	if passwordFile != "" {
+		return newFilePassword(passwordFile, interval).credentials
+	}
+	return nil
+}
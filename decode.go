@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// parallelDecodeThreshold 批量获取并发解码的最少条目数，条目较少时协程调度的开销大于收益
+const parallelDecodeThreshold = 32
+
+// WithDecodeWorkers 设置批量获取(MultiGet)解码的最大并发数
+// 一次批量获取数百个中等大小的JSON值时，解码占据了大部分耗时，并发解码可以明显降低延迟
+// n不大于1时顺序解码，条目少于32个时总是顺序解码；编码实现必须是线程安全的
+func WithDecodeWorkers(n int) CacheOption {
+	return func(o *cacheOptions) {
+		o.decodeWorkers = n
+	}
+}
+
+// decodeJob 批量获取中待解码的条目
+type decodeJob struct {
+	// key 写入结果map的键
+	key string
+	// userKey 调用方传入的键，用于校验
+	userKey string
+	// data 存储中的原始数据
+	data []byte
+	// object 解码目标对象
+	object interface{}
+	// err 解码错误
+	err error
+}
+
+// decodeJobs 解码批量获取的条目，错误记录在各条目中
+// 设置了解码并发数时使用有界的工作协程并发解码，decode不能修改条目以外的共享状态
+func (o *cacheOptions) decodeJobs(jobs []decodeJob, decode func(job *decodeJob) error) {
+	workers := min(o.decodeWorkers, len(jobs))
+	if workers <= 1 || len(jobs) < parallelDecodeThreshold {
+		for i := range jobs {
+			jobs[i].err = decode(&jobs[i])
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(jobs) {
+					return
+				}
+				jobs[i].err = decode(&jobs[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
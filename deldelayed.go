@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// delDelayed 延迟双删的通用实现：立即删除，delay后再删除一次
+// 用于更新数据库时，删除缓存与并发读请求用旧数据回填之间的竞争，第二次删除清除被回填的旧数据
+// 第二次删除在后台执行，不受ctx取消的影响，错误被忽略；进程在delay内退出时第二次删除不会执行
+func delDelayed(ctx context.Context, c Cache, key string, delay time.Duration) error {
+	if err := c.Del(ctx, key); err != nil {
+		return err
+	}
+	if delay <= 0 {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+	time.AfterFunc(delay, func() {
+		_ = c.Del(ctx, key)
+	})
+	return nil
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (m *memoryCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, m, key, delay)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *redisCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, c, key, delay)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *redisClusterCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, c, key, delay)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *storeCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, c, key, delay)
+}
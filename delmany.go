@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DelManyOptions 批量删除选项
+type DelManyOptions struct {
+	// ChunkSize 每个管道包含的键数量，默认500
+	ChunkSize int
+	// Concurrency 同时执行的管道数量，默认4
+	Concurrency int
+	// OnProgress 每个分片完成后回调，deleted为已处理的键数量，total为有效键总数
+	// 可能在多个goroutine中调用，但调用是串行的
+	OnProgress func(deleted, total int)
+}
+
+// setDefaults 设置默认值
+func (o *DelManyOptions) setDefaults() {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = 500
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 4
+	}
+}
+
+// progress 串行化进度回调
+type progress struct {
+	mu      sync.Mutex
+	done    int
+	total   int
+	onEvent func(deleted, total int)
+}
+
+// add 增加已处理数量并触发回调
+func (p *progress) add(n int) {
+	if p.onEvent == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.onEvent(p.done, p.total)
+}
+
+// chunkKeys 按大小切分键
+func chunkKeys(keys []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(keys)+size-1)/size)
+	for len(keys) > size {
+		chunks = append(chunks, keys[:size])
+		keys = keys[size:]
+	}
+	if len(keys) > 0 {
+		chunks = append(chunks, keys)
+	}
+	return chunks
+}
+
+// redisDelMany 分片并发删除，每个分片使用一个管道逐个UNLINK，避免集群模式下跨槽错误
+// 禁用管道时分片内逐条UNLINK
+func (o *cacheOptions) redisDelMany(ctx context.Context, client redis.Cmdable, cacheKeys []string, opts DelManyOptions) error {
+	opts.setDefaults()
+	p := &progress{total: len(cacheKeys), onEvent: opts.OnProgress}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, opts.Concurrency)
+	for _, chunk := range chunkKeys(cacheKeys, opts.ChunkSize) {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return errors.Join(append(errs, ctx.Err())...)
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(chunk []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := o.redisUnlinkChunk(ctx, client, chunk); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%w: 批量删除错误: %w, 键数量=%d", ErrBackend, err, len(chunk)))
+				mu.Unlock()
+				return
+			}
+			p.add(len(chunk))
+		}(chunk)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// redisUnlinkChunk 删除一个分片的键，代理不支持UNLINK时使用DEL
+func (o *cacheOptions) redisUnlinkChunk(ctx context.Context, client redis.Cmdable, chunk []string) error {
+	del := client.Unlink
+	if !o.proxy.supports("unlink") {
+		del = client.Del
+	}
+	if !o.pipelining {
+		for _, key := range chunk {
+			if err := del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pipe := client.Pipeline()
+	pipeDel := pipe.Unlink
+	if !o.proxy.supports("unlink") {
+		pipeDel = pipe.Del
+	}
+	for _, key := range chunk {
+		pipeDel(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DelMany 按分片批量删除大量键
+func (m *memoryCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), keys)
+	p := &progress{total: len(cacheKeys), onEvent: opts.OnProgress}
+	opts.setDefaults()
+	for _, chunk := range chunkKeys(cacheKeys, opts.ChunkSize) {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(err, keyErrs.errOrNil())
+		}
+		for _, cacheKey := range chunk {
+			m.client.Del(cacheKey)
+		}
+		p.add(len(chunk))
+	}
+	return keyErrs.errOrNil()
+}
+
+// DelMany 批量删除大量键，按分片使用管道UNLINK并限制并发
+func (c *redisCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	return errors.Join(c.redisDelMany(ctx, c.client, cacheKeys, opts), keyErrs.errOrNil())
+}
+
+// DelMany 批量删除大量键，按分片使用管道UNLINK并限制并发
+func (c *redisClusterCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	return errors.Join(c.redisDelMany(ctx, c.client, cacheKeys, opts), keyErrs.errOrNil())
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// delPatternBatchSize 按模式删除时每批SCAN和删除的键数量
+const delPatternBatchSize = 500
+
+// MemoryKeyLister 可以列出所有键的内存存储，内存缓存的DelPattern需要底层存储实现该接口
+// ristretto不支持遍历键，使用lru或deterministic引擎时可用
+type MemoryKeyLister interface {
+	// Keys 返回当前所有键的快照，可能包含已过期但未清理的键
+	Keys() []interface{}
+}
+
+// Keys 返回当前所有键的快照
+func (s *lruStore) Keys() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]interface{}, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Keys 返回当前所有键的快照
+func (s *deterministicStore) Keys() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]interface{}, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// buildPattern 构建包含键前缀的匹配模式，键前缀中的特殊字符被转义
+func buildPattern(keyPrefix, pattern string) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("%w: 匹配模式不能为空", ErrKeyBuild)
+	}
+	if keyPrefix == "" {
+		return pattern, nil
+	}
+	return escapeGlob(keyPrefix) + ":" + pattern, nil
+}
+
+// DelPattern 删除匹配模式的键，底层存储未实现MemoryKeyLister时返回ErrNotSupported
+func (m *memoryCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	lister, ok := m.client.(MemoryKeyLister)
+	if !ok {
+		return 0, fmt.Errorf("%w: DelPattern需要可遍历键的内存引擎", ErrNotSupported)
+	}
+	fullPattern, err := buildPattern(KeyPrefixFromContext(ctx, m.KeyPrefix), pattern)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, key := range lister.Keys() {
+		cacheKey, ok := key.(string)
+		if !ok || !globMatch(fullPattern, cacheKey) {
+			continue
+		}
+		if _, exists := m.client.Get(cacheKey); !exists {
+			continue
+		}
+		m.client.Del(cacheKey)
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DelPattern 使用SCAN分批扫描并删除匹配模式的键，不会阻塞Redis
+func (c *redisCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := c.proxy.check("scan"); err != nil {
+		return 0, err
+	}
+	return c.redisDelPattern(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), pattern)
+}
+
+// DelPattern 在每个主节点上使用SCAN分批扫描并删除匹配模式的键
+func (c *redisClusterCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	return c.redisDelPattern(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), pattern)
+}
+
+// redisDelPattern 扫描单机或集群每个主节点并删除匹配的键，返回删除的键数量
+func (o *cacheOptions) redisDelPattern(ctx context.Context, client redis.UniversalClient, keyPrefix, pattern string) (int64, error) {
+	fullPattern, err := buildPattern(keyPrefix, pattern)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		mu      sync.Mutex
+		deleted int64
+	)
+	delNode := func(ctx context.Context, node *redis.Client) error {
+		n, err := o.redisDelNodePattern(ctx, node, fullPattern)
+		mu.Lock()
+		deleted += n
+		mu.Unlock()
+		return err
+	}
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, delNode)
+	case *redis.Client:
+		err = delNode(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", client)
+	}
+	if err != nil {
+		return deleted, fmt.Errorf("%w: 按模式删除错误: %w, 模式=%s", ErrBackend, err, fullPattern)
+	}
+	return deleted, nil
+}
+
+// redisDelNodePattern 扫描单个节点并逐批删除匹配的键
+func (o *cacheOptions) redisDelNodePattern(ctx context.Context, node *redis.Client, pattern string) (int64, error) {
+	var (
+		cursor  uint64
+		deleted int64
+	)
+	for {
+		keys, next, err := node.Scan(ctx, cursor, pattern, delPatternBatchSize).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			// 逐个删除，避免集群模式下跨槽错误
+			cmds := make([]*redis.IntCmd, len(keys))
+			_, err = node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for i, key := range keys {
+					cmds[i] = o.redisDel(ctx, pipe, key)
+				}
+				return nil
+			})
+			for _, cmd := range cmds {
+				deleted += cmd.Val()
+			}
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// DelPattern 存储后端不支持按模式删除
+func (c *storeCache) DelPattern(_ context.Context, _ string) (int64, error) {
+	return 0, fmt.Errorf("%w: DelPattern", ErrNotSupported)
+}
+
+// globMatch Redis风格的通配符匹配，支持*、?、[abc]、[^a]、[a-z]和反斜杠转义
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			matched, rest, ok := matchClass(pattern[1:], s[0])
+			if !ok || !matched {
+				return false
+			}
+			pattern, s = rest, s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass 匹配字符类，pattern为[之后的部分，返回是否匹配和]之后的模式
+func matchClass(pattern string, c byte) (matched bool, rest string, ok bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == ']':
+			return matched != negate, pattern[i+1:], true
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			if pattern[i] == c {
+				matched = true
+			}
+		case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+			lo, hi := pattern[i], pattern[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			i += 2
+		default:
+			if pattern[i] == c {
+				matched = true
+			}
+		}
+	}
+	// 没有闭合的]，按Redis的行为视为不匹配
+	return false, "", false
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EntryKind 缓存条目的类型
+type EntryKind string
+
+const (
+	// EntryValue 普通数据
+	EntryValue EntryKind = "value"
+	// EntryNotFound 未找到占位符
+	EntryNotFound EntryKind = "not_found"
+	// EntryTombstone 墓碑标记
+	EntryTombstone EntryKind = "tombstone"
+)
+
+// TTLUnknown 后端不支持查询剩余过期时间
+const TTLUnknown time.Duration = -1
+
+// EntryInfo 缓存条目的元数据，用于排查键中实际存储的内容
+type EntryInfo struct {
+	// CacheKey 实际的缓存键，包含前缀
+	CacheKey string `json:"cache_key"`
+	// Kind 条目类型
+	Kind EntryKind `json:"kind"`
+	// Size 存储大小(字节)，包含信封头部
+	Size int `json:"size"`
+	// TTL 剩余过期时间，0表示永不过期，后端不支持查询时为TTLUnknown
+	TTL time.Duration `json:"ttl"`
+	// Compressed 数据是否带WithCompression的压缩头部，使用信封时也可能由信封标志位标记
+	Compressed bool `json:"compressed,omitempty"`
+	// Enveloped 是否使用了值信封，以下字段只在使用信封时有值
+	Enveloped bool `json:"enveloped"`
+	// Version 信封版本
+	Version uint8 `json:"version,omitempty"`
+	// Codec 写入时使用的编码名称，编码未实现Name方法时为空
+	Codec string `json:"codec,omitempty"`
+	// WrittenAt 写入时间
+	WrittenAt time.Time `json:"written_at,omitempty"`
+	// SourceUpdatedAt 写入时数据源的更新时间，值未实现SourceTimestamped时为零值
+	SourceUpdatedAt time.Time `json:"source_updated_at,omitempty"`
+}
+
+// StoreTTLGetter 支持同时读取值和剩余过期时间的存储，Describe使用
+// 永不过期时ttl返回0，不存在时返回ErrCacheNotFound
+type StoreTTLGetter interface {
+	GetWithTTL(ctx context.Context, key string) (value []byte, ttl time.Duration, err error)
+}
+
+// describeEntry 根据存储的原始数据生成条目元数据
+func (o *cacheOptions) describeEntry(cacheKey string, data []byte, ttl time.Duration) (EntryInfo, error) {
+	info := EntryInfo{CacheKey: cacheKey, Kind: EntryValue, Size: len(data), TTL: ttl}
+	switch {
+	case bytes.Equal(data, TombstonePlaceholderBytes):
+		info.Kind = EntryTombstone
+		return info, nil
+	case o.isPlaceholder(data):
+		info.Kind = EntryNotFound
+		return info, nil
+	}
+	env, ok, err := openEnvelope(data)
+	if err != nil {
+		return info, fmt.Errorf("%w: %w, 缓存键=%s", ErrDecode, err, cacheKey)
+	}
+	if !ok {
+		info.Compressed = isCompressed(data)
+		return info, nil
+	}
+	info.Enveloped = true
+	info.Version = env.version
+	info.Codec = env.codec()
+	info.Compressed = env.compressed() || isCompressed(env.payload)
+	info.WrittenAt = env.writtenAt()
+	info.SourceUpdatedAt = env.sourceUpdatedAt()
+	return info, nil
+}
+
+// Describe 获取缓存条目的元数据
+func (m *memoryCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return EntryInfo{}, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	data, ok := m.client.Get(cacheKey)
+	if !ok {
+		return EntryInfo{}, ErrCacheNotFound
+	}
+	dataBytes, ok := data.([]byte)
+	if !ok {
+		return EntryInfo{}, fmt.Errorf("%w: 数据类型错误, 键=%s, 类型=%T", ErrDecode, key, data)
+	}
+	ttl, _ := m.client.GetTTL(cacheKey)
+	return m.describeEntry(cacheKey, dataBytes, ttl)
+}
+
+// Describe 获取缓存条目的元数据
+func (c *redisCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	return c.redisDescribe(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// Describe 获取缓存条目的元数据
+func (c *redisClusterCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	return c.redisDescribe(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// redisDescribe 读取Redis中的值和剩余过期时间，不刷新滑动过期时间
+func (o *cacheOptions) redisDescribe(ctx context.Context, client redis.Cmdable, keyPrefix, key string) (EntryInfo, error) {
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return EntryInfo{}, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+
+	var getCmd *redis.StringCmd
+	var ttlCmd *redis.DurationCmd
+	if o.pipelining {
+		// 命令的错误在各自的结果中处理
+		_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			getCmd = pipe.Get(ctx, cacheKey)
+			ttlCmd = pipe.PTTL(ctx, cacheKey)
+			return nil
+		})
+	} else {
+		getCmd = client.Get(ctx, cacheKey)
+		ttlCmd = client.PTTL(ctx, cacheKey)
+	}
+	dataBytes, err := getCmd.Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return EntryInfo{}, ErrCacheNotFound
+		}
+		return EntryInfo{}, fmt.Errorf("%w: 客户端获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	ttl, err := ttlCmd.Result()
+	if err != nil {
+		return EntryInfo{}, fmt.Errorf("%w: 客户端获取过期时间错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	switch {
+	case ttl == -2:
+		// 两条命令之间键已过期
+		return EntryInfo{}, ErrCacheNotFound
+	case ttl < 0:
+		ttl = 0
+	}
+	return o.describeEntry(cacheKey, dataBytes, ttl)
+}
+
+// Describe 获取缓存条目的元数据，存储未实现StoreTTLGetter时TTL为TTLUnknown
+func (c *storeCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return EntryInfo{}, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	var dataBytes []byte
+	ttl := TTLUnknown
+	if getter, ok := c.store.(StoreTTLGetter); ok {
+		dataBytes, ttl, err = getter.GetWithTTL(ctx, cacheKey)
+	} else {
+		dataBytes, err = c.store.Get(ctx, cacheKey)
+	}
+	if err != nil {
+		if isMiss(err) {
+			return EntryInfo{}, ErrCacheNotFound
+		}
+		return EntryInfo{}, fmt.Errorf("%w: 存储获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return c.describeEntry(cacheKey, dataBytes, ttl)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribeCompressed(t *testing.T) {
+	encoding, err := WithCompression(&JSONEncoding{}, CompressionSnappy, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		envelope bool
+		value    string
+		want     bool
+	}{
+		{"compressed", false, strings.Repeat("a", 256), true},
+		{"enveloped compressed", true, strings.Repeat("a", 256), true},
+		{"below min size", false, "a", false},
+		{"enveloped below min size", true, "a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestMemoryCache(t).(*memoryCache)
+			c.encoding, c.envelope = encoding, tt.envelope
+			ctx := context.Background()
+			if err := c.Set(ctx, "k", &tt.value, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := c.Describe(ctx, "k")
+			if err != nil {
+				t.Fatalf("Describe() error = %v", err)
+			}
+			if info.Compressed != tt.want || info.Enveloped != tt.envelope {
+				t.Errorf("Describe() = %+v, want Compressed=%v Enveloped=%v", info, tt.want, tt.envelope)
+			}
+			var got string
+			if err := c.Get(ctx, "k", &got); err != nil || got != tt.value {
+				t.Errorf("Get() = %q, %v, want %q", got, err, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DoctorOptions 诊断报告的选项
+type DoctorOptions struct {
+	// ScanCount 每批SCAN的键数量，默认1000
+	ScanCount int
+	// ScanLimit 每个节点最多统计的前缀键数量，默认100000，超过时报告中Truncated为true
+	ScanLimit int64
+	// SlowLogCount 每个节点读取的慢查询条数，默认128
+	SlowLogCount int64
+}
+
+// setDefaults 设置默认值
+func (o *DoctorOptions) setDefaults() {
+	if o.ScanCount <= 0 {
+		o.ScanCount = 1000
+	}
+	if o.ScanLimit <= 0 {
+		o.ScanLimit = 100000
+	}
+	if o.SlowLogCount <= 0 {
+		o.SlowLogCount = 128
+	}
+}
+
+// DoctorNode 单个节点的诊断信息
+type DoctorNode struct {
+	// Addr 节点地址
+	Addr string `json:"addr"`
+	// UsedMemory 已使用内存字节数
+	UsedMemory int64 `json:"used_memory"`
+	// UsedMemoryPeak 已使用内存峰值字节数
+	UsedMemoryPeak int64 `json:"used_memory_peak"`
+	// MaxMemory 内存上限字节数，0表示不限制
+	MaxMemory int64 `json:"max_memory"`
+	// MaxMemoryPolicy 内存淘汰策略
+	MaxMemoryPolicy string `json:"max_memory_policy"`
+	// FragmentationRatio 内存碎片率
+	FragmentationRatio float64 `json:"fragmentation_ratio"`
+	// EvictedKeys 因内存上限被淘汰的键累计数量
+	EvictedKeys int64 `json:"evicted_keys"`
+	// ExpiredKeys 过期删除的键累计数量
+	ExpiredKeys int64 `json:"expired_keys"`
+	// KeyspaceHits 键读取命中累计次数
+	KeyspaceHits int64 `json:"keyspace_hits"`
+	// KeyspaceMisses 键读取未命中累计次数
+	KeyspaceMisses int64 `json:"keyspace_misses"`
+	// Keys 数据库中的键总数
+	Keys int64 `json:"keys"`
+	// PrefixKeys 匹配键前缀的键数量
+	PrefixKeys int64 `json:"prefix_keys"`
+	// Truncated 前缀键数量超过ScanLimit，PrefixKeys只是下限
+	Truncated bool `json:"truncated,omitempty"`
+	// MemoryDoctor MEMORY DOCTOR的输出
+	MemoryDoctor string `json:"memory_doctor,omitempty"`
+	// SlowLogs 参数中包含前缀键的慢查询
+	SlowLogs []redis.SlowLog `json:"slow_logs,omitempty"`
+	// Errors 收集部分信息失败的错误，如服务端不支持MEMORY DOCTOR
+	Errors []string `json:"errors,omitempty"`
+}
+
+// DoctorReport 诊断报告，汇总内存、键空间、慢查询和淘汰信息
+type DoctorReport struct {
+	// KeyPrefix 统计使用的键前缀
+	KeyPrefix string `json:"key_prefix"`
+	// Nodes 各节点的诊断信息，集群模式下为每个主节点，按地址排序
+	Nodes []DoctorNode `json:"nodes"`
+	// UsedMemory 所有节点已使用内存之和
+	UsedMemory int64 `json:"used_memory"`
+	// EvictedKeys 所有节点淘汰键数量之和
+	EvictedKeys int64 `json:"evicted_keys"`
+	// Keys 所有节点键总数之和
+	Keys int64 `json:"keys"`
+	// PrefixKeys 所有节点前缀键数量之和
+	PrefixKeys int64 `json:"prefix_keys"`
+	// SlowLogs 所有节点参数中包含前缀键的慢查询数量之和
+	SlowLogs int `json:"slow_logs"`
+	// Duration 耗时
+	Duration time.Duration `json:"duration"`
+}
+
+// DoctorProvider 支持诊断报告的提供者，由Redis单机和集群提供者实现
+type DoctorProvider interface {
+	// Doctor 生成提供者键前缀的诊断报告
+	Doctor(ctx context.Context) (*DoctorReport, error)
+}
+
+// Doctor 生成键前缀的诊断报告，集群模式下汇总每个主节点
+// 前缀键数量通过SCAN统计，键数量很多时耗时较长，可以通过ScanLimit限制
+func Doctor(ctx context.Context, client redis.UniversalClient, keyPrefix string, opts DoctorOptions) (*DoctorReport, error) {
+	opts.setDefaults()
+	start := time.Now()
+	report := &DoctorReport{KeyPrefix: keyPrefix}
+	pattern := "*"
+	slowLogPrefix := ""
+	if keyPrefix != "" {
+		pattern = escapeGlob(keyPrefix) + ":*"
+		slowLogPrefix = keyPrefix + ":"
+	}
+
+	var mu sync.Mutex
+	visit := func(ctx context.Context, node *redis.Client) error {
+		n, err := diagnoseNode(ctx, node, pattern, slowLogPrefix, opts)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		report.Nodes = append(report.Nodes, n)
+		return nil
+	}
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, visit)
+	case *redis.Client:
+		err = visit(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", client)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: 生成诊断报告错误: %w", ErrBackend, err)
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Addr < report.Nodes[j].Addr })
+	for _, n := range report.Nodes {
+		report.UsedMemory += n.UsedMemory
+		report.EvictedKeys += n.EvictedKeys
+		report.Keys += n.Keys
+		report.PrefixKeys += n.PrefixKeys
+		report.SlowLogs += len(n.SlowLogs)
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// diagnoseNode 收集单个节点的诊断信息，只有INFO失败时返回错误
+func diagnoseNode(ctx context.Context, node *redis.Client, pattern, slowLogPrefix string, opts DoctorOptions) (DoctorNode, error) {
+	n := DoctorNode{Addr: node.Options().Addr}
+	info, err := node.Info(ctx).Result()
+	if err != nil {
+		return n, fmt.Errorf("获取节点信息错误: %w, 地址=%s", err, n.Addr)
+	}
+	fields := parseInfoFields(info)
+	n.UsedMemory = infoInt(fields, "used_memory")
+	n.UsedMemoryPeak = infoInt(fields, "used_memory_peak")
+	n.MaxMemory = infoInt(fields, "maxmemory")
+	n.MaxMemoryPolicy = fields["maxmemory_policy"]
+	n.FragmentationRatio, _ = strconv.ParseFloat(fields["mem_fragmentation_ratio"], 64)
+	n.EvictedKeys = infoInt(fields, "evicted_keys")
+	n.ExpiredKeys = infoInt(fields, "expired_keys")
+	n.KeyspaceHits = infoInt(fields, "keyspace_hits")
+	n.KeyspaceMisses = infoInt(fields, "keyspace_misses")
+	n.Keys = keyspaceKeys(fields["db"+strconv.Itoa(node.Options().DB)])
+
+	if n.MemoryDoctor, err = node.Do(ctx, "MEMORY", "DOCTOR").Text(); err != nil {
+		n.Errors = append(n.Errors, fmt.Sprintf("MEMORY DOCTOR: %s", err))
+	}
+	if n.PrefixKeys, n.Truncated, err = countKeys(ctx, node, pattern, opts); err != nil {
+		n.Errors = append(n.Errors, fmt.Sprintf("SCAN: %s", err))
+	}
+	logs, err := node.SlowLogGet(ctx, opts.SlowLogCount).Result()
+	if err != nil {
+		n.Errors = append(n.Errors, fmt.Sprintf("SLOWLOG GET: %s", err))
+	}
+	for _, log := range logs {
+		if slowLogTouches(log, slowLogPrefix) {
+			n.SlowLogs = append(n.SlowLogs, log)
+		}
+	}
+	return n, nil
+}
+
+// countKeys 通过SCAN统计匹配的键数量，达到上限时停止
+func countKeys(ctx context.Context, node *redis.Client, pattern string, opts DoctorOptions) (count int64, truncated bool, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = node.Scan(ctx, cursor, pattern, int64(opts.ScanCount)).Result()
+		if err != nil {
+			return count, false, err
+		}
+		count += int64(len(keys))
+		if count >= opts.ScanLimit && cursor != 0 {
+			return count, true, nil
+		}
+		if cursor == 0 {
+			return count, false, nil
+		}
+	}
+}
+
+// slowLogTouches 慢查询的参数(不含命令名)中是否包含前缀键，前缀为空时总是包含
+func slowLogTouches(log redis.SlowLog, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	for i := 1; i < len(log.Args); i++ {
+		if strings.HasPrefix(log.Args[i], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// infoInt 读取INFO中的整数字段，不存在或格式错误时为0
+func infoInt(fields map[string]string, name string) int64 {
+	v, _ := strconv.ParseInt(fields[name], 10, 64)
+	return v
+}
+
+// keyspaceKeys 解析INFO keyspace中数据库的键数量，格式为keys=1,expires=0,avg_ttl=0
+func keyspaceKeys(db string) int64 {
+	for _, part := range strings.Split(db, ",") {
+		if v, ok := strings.CutPrefix(part, "keys="); ok {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// Doctor 生成Redis键前缀的诊断报告，代理模式下不支持
+func (p *redisProvider) Doctor(ctx context.Context) (*DoctorReport, error) {
+	if err := p.redisConfig.ProxyMode.check("info"); err != nil {
+		return nil, err
+	}
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Doctor(ctx, p.client, p.keyPrefix, DoctorOptions{})
+}
+
+// Doctor 生成Redis集群键前缀的诊断报告
+func (p *redisClusterProvider) Doctor(ctx context.Context) (*DoctorReport, error) {
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Doctor(ctx, p.client, p.keyPrefix, DoctorOptions{})
+}
@@ -0,0 +1,335 @@
+// Package dynamodb 基于DynamoDB的缓存后端，使用单表和原生TTL属性
+// 适用于无法访问Redis的无服务器部署，导入该包即注册dynamodb缓存类型:
+//
+//	import _ "github.com/smart-unicom/cache/dynamodb"
+//
+//	config := &cache.Config{
+//		Type:  dynamodb.CacheType,
+//		Extra: map[string]string{"table": "cache", "region": "ap-southeast-1"},
+//	}
+//
+// 表的分区键为字符串类型的k，并需要在表上启用TTL，TTL属性为ttl
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/smart-unicom/cache"
+)
+
+// CacheType DynamoDB缓存类型
+const CacheType cache.CacheType = "dynamodb"
+
+const (
+	// attrKey 分区键属性
+	attrKey = "k"
+	// attrValue 值属性
+	attrValue = "v"
+	// attrCounter 计数器属性
+	attrCounter = "n"
+	// attrTTL 过期时间属性，Unix秒
+	attrTTL = "ttl"
+	// maxBatchGet BatchGetItem单次最多读取的键数量
+	maxBatchGet = 100
+	// maxBatchWrite BatchWriteItem单次最多写入的条目数量
+	maxBatchWrite = 25
+)
+
+func init() {
+	cache.RegisterStore(CacheType, newStoreFromConfig)
+}
+
+// Store DynamoDB存储，实现cache.Store
+type Store struct {
+	client         *dynamodb.Client
+	table          string
+	consistentRead bool
+	now            func() time.Time
+}
+
+var (
+	_ cache.Store            = (*Store)(nil)
+	_ cache.StoreMultiGetter = (*Store)(nil)
+	_ cache.StoreMultiSetter = (*Store)(nil)
+	_ cache.StoreIncrementer = (*Store)(nil)
+	_ cache.StoreSetNXer     = (*Store)(nil)
+)
+
+// NewStore 创建DynamoDB存储，consistentRead为true时使用强一致性读取
+func NewStore(client *dynamodb.Client, table string, consistentRead bool) *Store {
+	return &Store{
+		client:         client,
+		table:          table,
+		consistentRead: consistentRead,
+		now:            time.Now,
+	}
+}
+
+// NewCache 创建DynamoDB缓存
+func NewCache(client *dynamodb.Client, table string, keyPrefix string, encode cache.Encoding, newObject func() interface{}, opts ...cache.CacheOption) cache.Cache {
+	return cache.NewStoreCache(NewStore(client, table, false), keyPrefix, encode, newObject, opts...)
+}
+
+// newStoreFromConfig 根据Config.Extra创建存储
+// 支持的配置: table(必填)、region、endpoint、consistent_read
+func newStoreFromConfig(config *cache.Config) (cache.Store, error) {
+	table := config.ExtraString("table")
+	if table == "" {
+		return nil, errors.New("DynamoDB表名不能为空")
+	}
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if region := config.ExtraString("region"); region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("加载AWS配置失败: %w", err)
+	}
+	client := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if endpoint := config.ExtraString("endpoint"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	consistentRead, _ := strconv.ParseBool(config.ExtraString("consistent_read"))
+	return NewStore(client, table, consistentRead), nil
+}
+
+// keyAttr 构造主键
+func keyAttr(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{attrKey: &types.AttributeValueMemberS{Value: key}}
+}
+
+// item 构造条目
+func (s *Store) item(key string, value []byte, ttl time.Duration) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		attrKey:   &types.AttributeValueMemberS{Value: key},
+		attrValue: &types.AttributeValueMemberB{Value: value},
+	}
+	if ttl > 0 {
+		item[attrTTL] = &types.AttributeValueMemberN{Value: strconv.FormatInt(s.expireAt(ttl), 10)}
+	}
+	return item
+}
+
+// expireAt 计算过期的Unix秒，不足1秒按1秒计算
+func (s *Store) expireAt(ttl time.Duration) int64 {
+	return s.now().Add(ttl + time.Second - 1).Unix()
+}
+
+// decodeItem 解析条目，DynamoDB按TTL删除可能延迟数小时，已过期的条目视为不存在
+func (s *Store) decodeItem(item map[string]types.AttributeValue) ([]byte, bool) {
+	if item == nil {
+		return nil, false
+	}
+	if attr, ok := item[attrTTL].(*types.AttributeValueMemberN); ok {
+		expireAt, err := strconv.ParseInt(attr.Value, 10, 64)
+		if err == nil && s.now().Unix() >= expireAt {
+			return nil, false
+		}
+	}
+	switch v := item[attrValue].(type) {
+	case *types.AttributeValueMemberB:
+		return v.Value, true
+	}
+	if n, ok := item[attrCounter].(*types.AttributeValueMemberN); ok {
+		return []byte(n.Value), true
+	}
+	return nil, false
+}
+
+// Get 获取值
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	value, _, err := s.GetWithTTL(ctx, key)
+	return value, err
+}
+
+// GetWithTTL 获取值和剩余过期时间，永不过期时ttl为0
+// 过期时间属性精确到秒
+func (s *Store) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            keyAttr(key),
+		ConsistentRead: aws.Bool(s.consistentRead),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	value, ok := s.decodeItem(out.Item)
+	if !ok {
+		return nil, 0, cache.ErrCacheNotFound
+	}
+	var ttl time.Duration
+	if attr, ok := out.Item[attrTTL].(*types.AttributeValueMemberN); ok {
+		if expireAt, err := strconv.ParseInt(attr.Value, 10, 64); err == nil {
+			ttl = time.Unix(expireAt, 0).Sub(s.now())
+		}
+	}
+	return value, ttl, nil
+}
+
+// Set 设置值
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      s.item(key, value, ttl),
+	})
+	return err
+}
+
+// Del 删除值，使用BatchWriteItem批量删除
+func (s *Store) Del(ctx context.Context, keys ...string) error {
+	requests := make([]types.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: keyAttr(key)}})
+	}
+	return s.batchWrite(ctx, requests)
+}
+
+// MultiGet 批量获取，使用BatchGetItem
+func (s *Store) MultiGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	for start := 0; start < len(keys); start += maxBatchGet {
+		end := min(start+maxBatchGet, len(keys))
+		attrs := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, key := range keys[start:end] {
+			attrs = append(attrs, keyAttr(key))
+		}
+		request := map[string]types.KeysAndAttributes{
+			s.table: {Keys: attrs, ConsistentRead: aws.Bool(s.consistentRead)},
+		}
+		// 被限流的键在UnprocessedKeys中返回，需要重试
+		for len(request) > 0 {
+			out, err := s.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range out.Responses[s.table] {
+				key, ok := item[attrKey].(*types.AttributeValueMemberS)
+				if !ok {
+					continue
+				}
+				if value, ok := s.decodeItem(item); ok {
+					values[key.Value] = value
+				}
+			}
+			request = out.UnprocessedKeys
+		}
+	}
+	return values, nil
+}
+
+// MultiSet 批量设置，使用BatchWriteItem
+func (s *Store) MultiSet(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	requests := make([]types.WriteRequest, 0, len(values))
+	for key, value := range values {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: s.item(key, value, ttl)}})
+	}
+	return s.batchWrite(ctx, requests)
+}
+
+// batchWrite 分批执行写入请求并重试未处理的条目
+func (s *Store) batchWrite(ctx context.Context, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += maxBatchWrite {
+		end := min(start+maxBatchWrite, len(requests))
+		pending := map[string][]types.WriteRequest{s.table: requests[start:end]}
+		for len(pending) > 0 {
+			out, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+			pending = out.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+// SetNX 仅在键不存在时设置值，已过期但尚未被TTL删除的条目视为不存在
+func (s *Store) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(s.table),
+		Item:                     s.item(key, value, ttl),
+		ConditionExpression:      aws.String("attribute_not_exists(#k) OR #t <= :now"),
+		ExpressionAttributeNames: map[string]string{"#k": attrKey, "#t": attrTTL},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(s.now().Unix(), 10)},
+		},
+	})
+	if err == nil {
+		return true, nil
+	}
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return false, nil
+	}
+	return false, err
+}
+
+// IncrWithTTL 原子自增，仅在键新建时设置过期时间
+// 先对已存在的键自增，键不存在时以条件写入创建，并发创建冲突时重试
+func (s *Store) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	deltaAttr := &types.AttributeValueMemberN{Value: strconv.FormatInt(delta, 10)}
+	for {
+		out, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(s.table),
+			Key:                       keyAttr(key),
+			UpdateExpression:          aws.String("ADD #n :d"),
+			ConditionExpression:       aws.String("attribute_exists(#k)"),
+			ExpressionAttributeNames:  map[string]string{"#n": attrCounter, "#k": attrKey},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":d": deltaAttr},
+			ReturnValues:              types.ReturnValueUpdatedNew,
+		})
+		if err == nil {
+			return counterValue(out.Attributes)
+		}
+		var conditionErr *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionErr) {
+			return 0, err
+		}
+
+		expr := "SET #n = :d"
+		names := map[string]string{"#n": attrCounter, "#k": attrKey}
+		values := map[string]types.AttributeValue{":d": deltaAttr}
+		if ttl > 0 {
+			expr += ", #t = :t"
+			names["#t"] = attrTTL
+			values[":t"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(s.expireAt(ttl), 10)}
+		}
+		out, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(s.table),
+			Key:                       keyAttr(key),
+			UpdateExpression:          aws.String(expr),
+			ConditionExpression:       aws.String("attribute_not_exists(#k)"),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+			ReturnValues:              types.ReturnValueUpdatedNew,
+		})
+		if err == nil {
+			return counterValue(out.Attributes)
+		}
+		if !errors.As(err, &conditionErr) {
+			return 0, err
+		}
+		// 其他调用方同时创建了该键，重新自增
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// counterValue 解析更新后的计数器值
+func counterValue(attrs map[string]types.AttributeValue) (int64, error) {
+	n, ok := attrs[attrCounter].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("%w: 计数器属性缺失", cache.ErrDecode)
+	}
+	return cache.ParseCounter([]byte(n.Value))
+}
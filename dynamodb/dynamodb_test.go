@@ -0,0 +1,374 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/smart-unicom/cache"
+)
+
+// attrJSON DynamoDB JSON协议中的属性值
+type attrJSON map[string]interface{}
+
+// fakeTable 实现测试用到的DynamoDB JSON协议子集的单表服务
+type fakeTable struct {
+	mu    sync.Mutex
+	items map[string]map[string]attrJSON
+	// unprocessed 为true时批量请求的最后一个条目第一次不处理
+	unprocessed bool
+	// batches 记录每次批量请求的条目数量
+	batches []int
+}
+
+func (f *fakeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Key                       map[string]attrJSON
+		Item                      map[string]attrJSON
+		ConditionExpression       string
+		UpdateExpression          string
+		ExpressionAttributeValues map[string]attrJSON
+		RequestItems              map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	var resp interface{}
+	switch op {
+	case "GetItem":
+		resp = map[string]interface{}{"Item": f.items[keyOf(req.Key)]}
+	case "PutItem":
+		if req.ConditionExpression != "" && !f.absentOrExpired(req.Item, req.ExpressionAttributeValues) {
+			conditionFailed(w)
+			return
+		}
+		f.items[keyOf(req.Item)] = req.Item
+		resp = struct{}{}
+	case "UpdateItem":
+		resp = f.update(w, req.Key, req.ConditionExpression, req.UpdateExpression, req.ExpressionAttributeValues)
+		if resp == nil {
+			return
+		}
+	case "BatchGetItem":
+		resp = f.batchGet(req.RequestItems)
+	case "BatchWriteItem":
+		resp = f.batchWrite(req.RequestItems)
+	default:
+		http.Error(w, "unsupported "+op, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func keyOf(item map[string]attrJSON) string {
+	s, _ := item[attrKey]["S"].(string)
+	return s
+}
+
+func numberOf(attr attrJSON) int64 {
+	s, _ := attr["N"].(string)
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func conditionFailed(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+}
+
+// absentOrExpired 对应SetNX的条件表达式
+func (f *fakeTable) absentOrExpired(item map[string]attrJSON, values map[string]attrJSON) bool {
+	old, ok := f.items[keyOf(item)]
+	if !ok {
+		return true
+	}
+	ttl, ok := old[attrTTL]
+	return ok && numberOf(ttl) <= numberOf(values[":now"])
+}
+
+// update 对应IncrWithTTL的两种更新表达式，条件失败时返回nil
+func (f *fakeTable) update(w http.ResponseWriter, key map[string]attrJSON, cond, expr string, values map[string]attrJSON) interface{} {
+	k := keyOf(key)
+	old, exists := f.items[k]
+	if (cond == "attribute_exists(#k)") != exists {
+		conditionFailed(w)
+		return nil
+	}
+	item := map[string]attrJSON{attrKey: key[attrKey]}
+	for name, attr := range old {
+		item[name] = attr
+	}
+	d := numberOf(values[":d"])
+	if strings.HasPrefix(expr, "ADD") {
+		d += numberOf(item[attrCounter])
+	}
+	item[attrCounter] = attrJSON{"N": strconv.FormatInt(d, 10)}
+	if t, ok := values[":t"]; ok {
+		item[attrTTL] = t
+	}
+	f.items[k] = item
+	return map[string]interface{}{"Attributes": map[string]attrJSON{attrCounter: item[attrCounter]}}
+}
+
+func (f *fakeTable) batchGet(requests map[string]json.RawMessage) interface{} {
+	responses := make(map[string][]map[string]attrJSON)
+	unprocessed := make(map[string]interface{})
+	for table, raw := range requests {
+		var r struct{ Keys []map[string]attrJSON }
+		_ = json.Unmarshal(raw, &r)
+		f.batches = append(f.batches, len(r.Keys))
+		if f.unprocessed && len(r.Keys) > 1 {
+			f.unprocessed = false
+			unprocessed[table] = map[string]interface{}{"Keys": r.Keys[len(r.Keys)-1:]}
+			r.Keys = r.Keys[:len(r.Keys)-1]
+		}
+		for _, key := range r.Keys {
+			if item, ok := f.items[keyOf(key)]; ok {
+				responses[table] = append(responses[table], item)
+			}
+		}
+	}
+	return map[string]interface{}{"Responses": responses, "UnprocessedKeys": unprocessed}
+}
+
+func (f *fakeTable) batchWrite(requests map[string]json.RawMessage) interface{} {
+	type writeRequest struct {
+		PutRequest    *struct{ Item map[string]attrJSON } `json:",omitempty"`
+		DeleteRequest *struct{ Key map[string]attrJSON }  `json:",omitempty"`
+	}
+	unprocessed := make(map[string][]writeRequest)
+	for table, raw := range requests {
+		var writes []writeRequest
+		_ = json.Unmarshal(raw, &writes)
+		f.batches = append(f.batches, len(writes))
+		if f.unprocessed && len(writes) > 1 {
+			f.unprocessed = false
+			unprocessed[table] = writes[len(writes)-1:]
+			writes = writes[:len(writes)-1]
+		}
+		for _, write := range writes {
+			if write.PutRequest != nil {
+				f.items[keyOf(write.PutRequest.Item)] = write.PutRequest.Item
+			} else if write.DeleteRequest != nil {
+				delete(f.items, keyOf(write.DeleteRequest.Key))
+			}
+		}
+	}
+	return map[string]interface{}{"UnprocessedItems": unprocessed}
+}
+
+// newTestStore 创建连接到fakeTable的存储，返回可推进的时钟
+func newTestStore(t *testing.T) (*Store, *fakeTable, *time.Time) {
+	t.Helper()
+	fake := &fakeTable{items: make(map[string]map[string]attrJSON)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(srv.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	s := NewStore(client, "cache", false)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	return s, fake, &now
+}
+
+func TestDecodeItem(t *testing.T) {
+	s := NewStore(nil, "cache", false)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	ttl := func(sec int64) types.AttributeValue {
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(sec, 10)}
+	}
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		want string
+		ok   bool
+	}{
+		{"不存在", nil, "", false},
+		{"二进制值", map[string]types.AttributeValue{attrValue: &types.AttributeValueMemberB{Value: []byte("v")}}, "v", true},
+		{"计数器", map[string]types.AttributeValue{attrCounter: &types.AttributeValueMemberN{Value: "42"}}, "42", true},
+		{"未过期", map[string]types.AttributeValue{attrValue: &types.AttributeValueMemberB{Value: []byte("v")}, attrTTL: ttl(now.Unix() + 1)}, "v", true},
+		{"已过期未删除", map[string]types.AttributeValue{attrValue: &types.AttributeValueMemberB{Value: []byte("v")}, attrTTL: ttl(now.Unix())}, "", false},
+		{"缺少值", map[string]types.AttributeValue{attrKey: &types.AttributeValueMemberS{Value: "k"}}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.decodeItem(tt.item)
+			if ok != tt.ok || string(got) != tt.want {
+				t.Errorf("decodeItem() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExpireAt(t *testing.T) {
+	s := NewStore(nil, "cache", false)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	tests := []struct {
+		ttl  time.Duration
+		want int64
+	}{
+		{time.Second, now.Unix() + 1},
+		{1500 * time.Millisecond, now.Unix() + 2},
+		{time.Millisecond, now.Unix() + 1},
+		{time.Minute, now.Unix() + 60},
+	}
+	for _, tt := range tests {
+		if got := s.expireAt(tt.ttl); got != tt.want {
+			t.Errorf("expireAt(%v) = %d, want %d", tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestStoreGetSet(t *testing.T) {
+	s, _, now := newTestStore(t)
+	ctx := context.Background()
+	if err := s.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "forever", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(20 * time.Second)
+
+	if val, ttl, err := s.GetWithTTL(ctx, "k"); err != nil || string(val) != "v" || ttl != 40*time.Second {
+		t.Errorf("GetWithTTL() = %q, %v, %v, want v, 40s", val, ttl, err)
+	}
+	if val, ttl, err := s.GetWithTTL(ctx, "forever"); err != nil || string(val) != "v" || ttl != 0 {
+		t.Errorf("GetWithTTL(forever) = %q, %v, %v, want v, 0", val, ttl, err)
+	}
+	*now = now.Add(time.Minute)
+	for _, key := range []string{"k", "missing"} {
+		if _, err := s.Get(ctx, key); !errors.Is(err, cache.ErrCacheNotFound) {
+			t.Errorf("Get(%s) error = %v, want ErrCacheNotFound", key, err)
+		}
+	}
+}
+
+func TestStoreSetNX(t *testing.T) {
+	s, _, now := newTestStore(t)
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		value   string
+		advance time.Duration
+		want    bool
+		stored  string
+	}{
+		{"新键", "a", 0, true, "a"},
+		{"已存在", "b", 0, false, "a"},
+		{"过期未删除", "c", 2 * time.Minute, true, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.advance)
+			ok, err := s.SetNX(ctx, "k", []byte(tt.value), time.Minute)
+			if err != nil || ok != tt.want {
+				t.Fatalf("SetNX() = %v, %v, want %v", ok, err, tt.want)
+			}
+			if got, err := s.Get(ctx, "k"); err != nil || string(got) != tt.stored {
+				t.Errorf("Get() = %q, %v, want %s", got, err, tt.stored)
+			}
+		})
+	}
+}
+
+func TestStoreIncrWithTTL(t *testing.T) {
+	s, fake, _ := newTestStore(t)
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		delta int64
+		want  int64
+	}{
+		{"新建", 2, 2},
+		{"累加", 3, 5},
+		{"负数", -6, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := s.IncrWithTTL(ctx, "n", tt.delta, time.Minute); err != nil || got != tt.want {
+				t.Errorf("IncrWithTTL() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+	if got := numberOf(fake.items["n"][attrTTL]); got != s.expireAt(time.Minute) {
+		t.Errorf("计数器过期时间 = %d, want %d", got, s.expireAt(time.Minute))
+	}
+	if got, err := s.Get(ctx, "n"); err != nil || string(got) != "-1" {
+		t.Errorf("Get() = %q, %v, want -1", got, err)
+	}
+}
+
+func TestStoreBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		n           int
+		unprocessed bool
+		wantWrites  []int
+		wantGets    []int
+	}{
+		{"单批", 3, false, []int{3}, []int{3}},
+		{"分批", maxBatchGet + 1, false, []int{25, 25, 25, 25, 1}, []int{100, 1}},
+		{"重试未处理条目", 3, true, []int{3, 1}, []int{3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake, _ := newTestStore(t)
+			ctx := context.Background()
+			values := make(map[string][]byte, tt.n)
+			keys := make([]string, 0, tt.n)
+			for i := 0; i < tt.n; i++ {
+				key := fmt.Sprintf("k%d", i)
+				values[key] = []byte(key)
+				keys = append(keys, key)
+			}
+			fake.unprocessed = tt.unprocessed
+			if err := s.MultiSet(ctx, values, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(fake.batches) != fmt.Sprint(tt.wantWrites) {
+				t.Errorf("BatchWriteItem批次 = %v, want %v", fake.batches, tt.wantWrites)
+			}
+
+			fake.batches, fake.unprocessed = nil, tt.unprocessed
+			got, err := s.MultiGet(ctx, keys)
+			if err != nil || len(got) != tt.n {
+				t.Fatalf("MultiGet() = %d entries, %v, want %d", len(got), err, tt.n)
+			}
+			if fmt.Sprint(fake.batches) != fmt.Sprint(tt.wantGets) {
+				t.Errorf("BatchGetItem批次 = %v, want %v", fake.batches, tt.wantGets)
+			}
+
+			if err := s.Del(ctx, keys...); err != nil {
+				t.Fatal(err)
+			}
+			if len(fake.items) != 0 {
+				t.Errorf("Del后剩余%d个条目", len(fake.items))
+			}
+		})
+	}
+}
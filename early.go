@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// earlyEntry 提前过期模式下读写的值，写入时在信封中记录加载耗时和过期时间，读取时解析这两个字段
+type earlyEntry struct {
+	value interface{}
+	// delta 加载耗时
+	delta time.Duration
+	// expireAt 过期时间，为零值表示永不过期
+	expireAt time.Time
+}
+
+// newEarlyEntry 创建加载后写入的值，ttl不大于0时不记录过期时间
+func newEarlyEntry(value interface{}, delta, ttl time.Duration) *earlyEntry {
+	e := &earlyEntry{value: value, delta: delta}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+// fields 信封头部字段
+func (e *earlyEntry) fields() []envelopeField {
+	fields := []envelopeField{{tag: envelopeTagComputeCost, value: binary.BigEndian.AppendUint64(nil, uint64(e.delta.Milliseconds()))}}
+	if !e.expireAt.IsZero() {
+		fields = append(fields, envelopeField{tag: envelopeTagExpireAt, value: binary.BigEndian.AppendUint64(nil, uint64(e.expireAt.UnixMilli()))})
+	}
+	return fields
+}
+
+// load 读取信封中的加载耗时和过期时间
+func (e *earlyEntry) load(env *envelope) {
+	e.delta = env.computeCost()
+	e.expireAt = env.expireAt()
+}
+
+// due 按XFetch算法判断是否需要提前刷新: now - delta*beta*ln(rand) >= expireAt
+// 加载越慢、越接近过期，提前刷新的概率越大；没有记录加载耗时或过期时间时不提前刷新
+func (e *earlyEntry) due(beta float64) bool {
+	if e.expireAt.IsZero() || e.delta <= 0 {
+		return false
+	}
+	// 1-rand.Float64()的取值范围为(0,1]，避免ln(0)
+	gap := -float64(e.delta) * beta * math.Log(1-rand.Float64())
+	return !time.Now().Add(time.Duration(min(gap, float64(math.MaxInt64/2)))).Before(e.expireAt)
+}
+
+// WithRefreshErrorHandler 设置提前刷新失败的回调，如加载函数返回错误或panic(包装ErrLoaderPanic)
+// 提前刷新在后台执行，失败时保留缓存中的数据，数据源中不存在不算失败
+// 注意：该函数在后台goroutine中调用，必须是线程安全的
+func WithRefreshErrorHandler(fn func(key string, err error)) CacheOption {
+	return func(o *cacheOptions) {
+		o.refreshError = fn
+	}
+}
+
+// refreshEarly 在后台提前刷新，同一个键的并发刷新只加载一次，加载失败时保留缓存中的数据
+// 加载函数panic时不会传播到后台goroutine之外，与加载错误一样交给刷新失败回调
+func (o *cacheOptions) refreshEarly(ctx context.Context, c Cache, cacheKey, key string, ttl time.Duration, loader Loader, admit func(val interface{}) bool) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		_, err := o.flight.do(cacheKey+"\x00early", func() (buf []byte, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					buf, err = nil, fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+				}
+			}()
+			return o.load(ctx, c, key, ttl, loader, true, true, admit)
+		})
+		if err == nil || errors.Is(err, ErrNotFoundCached) || errors.Is(err, ErrNotFound) {
+			return
+		}
+		if o.refreshError != nil {
+			o.refreshError(key, err)
+		}
+	}()
+}
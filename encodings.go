@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// 内置编码名称，用于Config.Encoding
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+	EncodingGob     = "gob"
+	EncodingProto   = "proto"
+)
+
+func init() {
+	RegisterCodec(&JSONEncoding{})
+	RegisterCodec(&MsgpackEncoding{})
+	RegisterCodec(&GobEncoding{})
+	RegisterCodec(&ProtoEncoding{})
+}
+
+// JSONEncoding JSON编码，Config未指定编码时的默认编码
+type JSONEncoding struct{}
+
+// Marshal 编码数据
+func (JSONEncoding) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 解码数据
+func (JSONEncoding) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name 编码名称
+func (JSONEncoding) Name() string {
+	return EncodingJSON
+}
+
+// MsgpackEncoding MessagePack编码，比JSON更紧凑，编解码更快
+type MsgpackEncoding struct{}
+
+// Marshal 编码数据
+func (MsgpackEncoding) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal 解码数据
+func (MsgpackEncoding) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// Name 编码名称
+func (MsgpackEncoding) Name() string {
+	return EncodingMsgpack
+}
+
+// GobEncoding Go的gob编码，只适用于Go服务之间共享的缓存
+type GobEncoding struct{}
+
+// Marshal 编码数据
+func (GobEncoding) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 解码数据
+func (GobEncoding) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Name 编码名称
+func (GobEncoding) Name() string {
+	return EncodingGob
+}
+
+// ProtoEncoding Protocol Buffers编码，值必须实现proto.Message
+type ProtoEncoding struct{}
+
+// Marshal 编码数据
+func (ProtoEncoding) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto编码的值必须实现proto.Message: %T", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal 解码数据
+func (ProtoEncoding) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("proto编码的值必须实现proto.Message: %T", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Name 编码名称
+func (ProtoEncoding) Name() string {
+	return EncodingProto
+}
+
+// resolveEncoding 确定提供者使用的编码，调用方传入的编码优先，否则按Config.Encoding名称查找已注册的编码，默认为JSON
+func resolveEncoding(config *Config, encoding Encoding) (Encoding, error) {
+	if encoding != nil {
+		return encoding, nil
+	}
+	name := strings.ToLower(config.Encoding)
+	if name == "" {
+		name = EncodingJSON
+	}
+	codec := GetCodec(name)
+	if codec == nil {
+		return nil, fmt.Errorf("不支持的编码: %s", config.Encoding)
+	}
+	return codec, nil
+}
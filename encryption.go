@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// ErrNotEncrypted 启用加密后读取到未加密的数据
+var ErrNotEncrypted = errors.New("数据未加密")
+
+// encryptionMagic 加密数据的头部标记，后面是4字节的密钥ID和随机数
+var encryptionMagic = []byte{0xff, 'e', 'n'}
+
+// encryptionKeyIDSize 密钥ID长度，取密钥SHA-256的前4字节
+const encryptionKeyIDSize = 4
+
+// encryptionEncoding AES-GCM加密编码包装
+type encryptionEncoding struct {
+	encoding Encoding
+	// current 加密使用的密钥
+	current *encryptionKey
+	// keys 按密钥ID索引的所有密钥，包括current，用于解密
+	keys map[[encryptionKeyIDSize]byte]*encryptionKey
+}
+
+// encryptionKey 加密密钥
+type encryptionKey struct {
+	id   [encryptionKeyIDSize]byte
+	aead cipher.AEAD
+}
+
+// newEncryptionKey 根据密钥创建AES-GCM
+func newEncryptionKey(key []byte) (*encryptionKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("无效的加密密钥: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建AES-GCM失败: %w", err)
+	}
+	k := &encryptionKey{aead: aead}
+	sum := sha256.Sum256(key)
+	copy(k.id[:], sum[:encryptionKeyIDSize])
+	return k, nil
+}
+
+// WithEncryption 为编码添加AES-GCM加密，key为16、24或32字节，分别对应AES-128、AES-192、AES-256
+// 加密数据的头部记录密钥ID，轮换密钥时将旧密钥放入previousKeys，旧密钥加密的数据仍然可读，新写入的数据使用key加密
+// 读取未加密的数据时返回ErrNotEncrypted；与WithCompression同时使用时应先压缩再加密
+func WithEncryption(e Encoding, key []byte, previousKeys ...[]byte) (Encoding, error) {
+	current, err := newEncryptionKey(key)
+	if err != nil {
+		return nil, err
+	}
+	enc := &encryptionEncoding{
+		encoding: e,
+		current:  current,
+		keys:     map[[encryptionKeyIDSize]byte]*encryptionKey{current.id: current},
+	}
+	for _, previous := range previousKeys {
+		k, err := newEncryptionKey(previous)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := enc.keys[k.id]; !ok {
+			enc.keys[k.id] = k
+		}
+	}
+	return enc, nil
+}
+
+// Marshal 编码数据并加密，空数据不加密，以便按占位符处理
+func (c *encryptionEncoding) Marshal(v interface{}) ([]byte, error) {
+	data, err := Marshal(c.encoding, v)
+	if err != nil || len(data) == 0 {
+		return data, err
+	}
+	aead := c.current.aead
+	headerSize := len(encryptionMagic) + encryptionKeyIDSize
+	out := make([]byte, headerSize+aead.NonceSize(), headerSize+aead.NonceSize()+len(data)+aead.Overhead())
+	copy(out, encryptionMagic)
+	copy(out[len(encryptionMagic):], c.current.id[:])
+	if _, err = rand.Read(out[headerSize:]); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	// 头部作为附加数据参与认证，防止篡改密钥ID
+	return aead.Seal(out, out[headerSize:], data, out[:headerSize]), nil
+}
+
+// Unmarshal 根据头部中的密钥ID解密后解码
+func (c *encryptionEncoding) Unmarshal(data []byte, v interface{}) error {
+	headerSize := len(encryptionMagic) + encryptionKeyIDSize
+	if len(data) < headerSize || !bytes.Equal(data[:len(encryptionMagic)], encryptionMagic) {
+		return ErrNotEncrypted
+	}
+	var id [encryptionKeyIDSize]byte
+	copy(id[:], data[len(encryptionMagic):headerSize])
+	k, ok := c.keys[id]
+	if !ok {
+		return fmt.Errorf("未知的加密密钥ID: %x", id)
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(data) < headerSize+nonceSize {
+		return errors.New("加密数据长度不足")
+	}
+	plain, err := k.aead.Open(nil, data[headerSize:headerSize+nonceSize], data[headerSize+nonceSize:], data[:headerSize])
+	if err != nil {
+		return fmt.Errorf("解密失败: %w", err)
+	}
+	return Unmarshal(c.encoding, plain, v)
+}
+
+// Name 编码名称，与内层编码相同
+func (c *encryptionEncoding) Name() string {
+	if named, ok := c.encoding.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		keySize int
+		value   string
+	}{
+		{"aes-128", 16, "secret-value"},
+		{"aes-192", 24, "secret-value"},
+		{"aes-256", 32, strings.Repeat("secret-value", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := WithEncryption(&JSONEncoding{}, bytes.Repeat([]byte{1}, tt.keySize))
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := encoding.Marshal(&tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			second, _ := encoding.Marshal(&tt.value)
+			if bytes.Contains(first, []byte(tt.value)) || bytes.Equal(first, second) {
+				t.Error("密文包含明文或两次加密结果相同")
+			}
+			var got string
+			if err := encoding.Unmarshal(first, &got); err != nil || got != tt.value {
+				t.Errorf("Unmarshal() = %q, %v, want %q", got, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	oldKey, newKey, otherKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32), bytes.Repeat([]byte{3}, 32)
+	value := "v"
+	old, _ := WithEncryption(&JSONEncoding{}, oldKey)
+	oldData, err := old.Marshal(&value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		prev    [][]byte
+		wantErr bool
+	}{
+		{"current key", oldKey, nil, false},
+		{"previous key", newKey, [][]byte{oldKey}, false},
+		{"unknown key", newKey, [][]byte{otherKey}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := WithEncryption(&JSONEncoding{}, tt.key, tt.prev...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			err = encoding.Unmarshal(oldData, &got)
+			if (err != nil) != tt.wantErr || (!tt.wantErr && got != value) {
+				t.Errorf("Unmarshal() = %q, %v, want error %v", got, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptionErrors(t *testing.T) {
+	if _, err := WithEncryption(&JSONEncoding{}, []byte("short")); err == nil {
+		t.Error("WithEncryption(short key) error = nil, want invalid key")
+	}
+	encoding, err := WithEncryption(&JSONEncoding{}, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := "v"
+	data, _ := encoding.Marshal(&value)
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+	headerTampered := bytes.Clone(data)
+	headerTampered[len(encryptionMagic)] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"plain", []byte(`"v"`), ErrNotEncrypted},
+		{"tampered payload", tampered, nil},
+		{"tampered key id", headerTampered, nil},
+		{"truncated", data[:len(encryptionMagic)+encryptionKeyIDSize+2], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			err := encoding.Unmarshal(tt.data, &got)
+			if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
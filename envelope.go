@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrEnvelopeVersion 值信封格式不兼容
+var ErrEnvelopeVersion = errors.New("值信封版本不兼容")
+
+// EnvelopePolicy 遇到旧格式(无信封)或更新版本信封时的处理策略
+type EnvelopePolicy string
+
+const (
+	// EnvelopeIgnoreUnknown 尽量解码：旧格式按原始数据解码，新版本信封跳过未知头部直接解码数据
+	EnvelopeIgnoreUnknown EnvelopePolicy = "ignore_unknown"
+	// EnvelopeTreatAsMiss 视为未命中，返回ErrCacheNotFound，由上游重新加载
+	EnvelopeTreatAsMiss EnvelopePolicy = "treat_as_miss"
+	// EnvelopeError 返回ErrEnvelopeVersion错误
+	EnvelopeError EnvelopePolicy = "error"
+)
+
+// 信封格式:
+//
+//	magic(3字节) | 版本(1字节) | 头部长度(2字节, 大端) | 头部字段 | 数据
+//
+// 头部字段为TLV格式: 标签(1字节) | 长度(1字节) | 值
+// 同一版本内新增的字段旧程序会忽略，只有不兼容的变化才升级版本
+var envelopeMagic = []byte{0xC1, 'C', 'E'}
+
+const (
+	// envelopeVersion 当前信封版本
+	envelopeVersion = 1
+	// envelopePrefixLen magic、版本和头部长度的总长度
+	envelopePrefixLen = 6
+)
+
+// 信封头部字段标签
+const (
+	// envelopeTagWrittenAt 写入时间，Unix毫秒
+	envelopeTagWrittenAt uint8 = 1
+	// envelopeTagCodec 写入时使用的编码名称，编码实现了Name方法时写入
+	envelopeTagCodec uint8 = 2
+	// envelopeTagFlags 数据标志位，见envelopeFlagCompressed
+	envelopeTagFlags uint8 = 3
+	// envelopeTagSourceUpdatedAt 数据源更新时间，Unix毫秒，值实现了SourceTimestamped时写入
+	envelopeTagSourceUpdatedAt uint8 = 4
+	// envelopeTagComputeCost 加载耗时，毫秒，GetOrSet开启提前过期时写入
+	envelopeTagComputeCost uint8 = 5
+	// envelopeTagExpireAt 过期时间，Unix毫秒，GetOrSet开启提前过期时写入
+	envelopeTagExpireAt uint8 = 6
+)
+
+// 信封数据标志位
+const (
+	// envelopeFlagCompressed 数据已压缩
+	envelopeFlagCompressed uint8 = 1 << 0
+)
+
+// envelope 解析后的值信封
+type envelope struct {
+	version uint8
+	fields  map[uint8][]byte
+	payload []byte
+}
+
+// writtenAt 获取写入时间
+func (e *envelope) writtenAt() time.Time {
+	v, ok := e.fields[envelopeTagWrittenAt]
+	if !ok || len(v) != 8 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(v)))
+}
+
+// sourceUpdatedAt 获取数据源更新时间
+func (e *envelope) sourceUpdatedAt() time.Time {
+	v, ok := e.fields[envelopeTagSourceUpdatedAt]
+	if !ok || len(v) != 8 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(v)))
+}
+
+// computeCost 获取加载耗时
+func (e *envelope) computeCost() time.Duration {
+	v, ok := e.fields[envelopeTagComputeCost]
+	if !ok || len(v) != 8 {
+		return 0
+	}
+	return time.Duration(binary.BigEndian.Uint64(v)) * time.Millisecond
+}
+
+// expireAt 获取过期时间
+func (e *envelope) expireAt() time.Time {
+	v, ok := e.fields[envelopeTagExpireAt]
+	if !ok || len(v) != 8 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(v)))
+}
+
+// codec 获取写入时使用的编码名称
+func (e *envelope) codec() string {
+	return string(e.fields[envelopeTagCodec])
+}
+
+// compressed 数据是否已压缩
+func (e *envelope) compressed() bool {
+	v := e.fields[envelopeTagFlags]
+	return len(v) == 1 && v[0]&envelopeFlagCompressed != 0
+}
+
+// envelopeField 信封头部字段
+type envelopeField struct {
+	tag   uint8
+	value []byte
+}
+
+// sealEnvelope 将数据封装到信封中，自动写入写入时间
+func sealEnvelope(payload []byte, fields ...envelopeField) []byte {
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(time.Now().UnixMilli()))
+	fields = append([]envelopeField{{tag: envelopeTagWrittenAt, value: ts}}, fields...)
+
+	headerLen := 0
+	for _, f := range fields {
+		headerLen += 2 + len(f.value)
+	}
+	buf := make([]byte, 0, envelopePrefixLen+headerLen+len(payload))
+	buf = append(buf, envelopeMagic...)
+	buf = append(buf, envelopeVersion)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(headerLen))
+	for _, f := range fields {
+		buf = append(buf, f.tag, uint8(len(f.value)))
+		buf = append(buf, f.value...)
+	}
+	return append(buf, payload...)
+}
+
+// openEnvelope 解析信封，数据不是信封格式时ok返回false
+func openEnvelope(data []byte) (env *envelope, ok bool, err error) {
+	if len(data) < envelopePrefixLen || !bytes.HasPrefix(data, envelopeMagic) {
+		return nil, false, nil
+	}
+	version := data[len(envelopeMagic)]
+	headerLen := int(binary.BigEndian.Uint16(data[len(envelopeMagic)+1:]))
+	if len(data) < envelopePrefixLen+headerLen {
+		return nil, true, fmt.Errorf("值信封头部不完整, 长度=%d", len(data))
+	}
+
+	env = &envelope{
+		version: version,
+		fields:  make(map[uint8][]byte),
+		payload: data[envelopePrefixLen+headerLen:],
+	}
+	header := data[envelopePrefixLen : envelopePrefixLen+headerLen]
+	for len(header) >= 2 {
+		tag, n := header[0], int(header[1])
+		if len(header) < 2+n {
+			return nil, true, fmt.Errorf("值信封头部字段不完整, 标签=%d", tag)
+		}
+		env.fields[tag] = header[2 : 2+n]
+		header = header[2+n:]
+	}
+	return env, true, nil
+}
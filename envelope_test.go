@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	updatedAt := time.UnixMilli(1700000000000)
+	data := sealEnvelope([]byte(`"v"`),
+		envelopeField{tag: envelopeTagCodec, value: []byte("json")},
+		envelopeField{tag: envelopeTagFlags, value: []byte{envelopeFlagCompressed}},
+		envelopeField{tag: envelopeTagSourceUpdatedAt, value: binary.BigEndian.AppendUint64(nil, uint64(updatedAt.UnixMilli()))},
+		envelopeField{tag: 200, value: []byte("unknown")},
+	)
+	env, ok, err := openEnvelope(data)
+	if err != nil || !ok {
+		t.Fatalf("openEnvelope() = %v, %v", ok, err)
+	}
+	if string(env.payload) != `"v"` || env.version != envelopeVersion {
+		t.Errorf("payload = %q, version = %d", env.payload, env.version)
+	}
+	if env.codec() != "json" || !env.compressed() || !env.sourceUpdatedAt().Equal(updatedAt) {
+		t.Errorf("codec = %q, compressed = %v, sourceUpdatedAt = %v", env.codec(), env.compressed(), env.sourceUpdatedAt())
+	}
+	if since := time.Since(env.writtenAt()); since < 0 || since > time.Minute {
+		t.Errorf("writtenAt = %v, want now", env.writtenAt())
+	}
+}
+
+func TestOpenEnvelope(t *testing.T) {
+	sealed := sealEnvelope([]byte("payload"), envelopeField{tag: envelopeTagCodec, value: []byte("json")})
+	// 头部声明的字段长度超出头部
+	badField := append(append([]byte{}, envelopeMagic...), envelopeVersion, 0, 2, envelopeTagCodec, 5)
+	tests := []struct {
+		name    string
+		data    []byte
+		ok      bool
+		wantErr bool
+	}{
+		{"sealed", sealed, true, false},
+		{"legacy", []byte(`"v"`), false, false},
+		{"short", envelopeMagic, false, false},
+		{"truncated header", sealed[:envelopePrefixLen+3], true, true},
+		{"truncated field", badField, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok, err := openEnvelope(tt.data)
+			if ok != tt.ok || (err != nil) != tt.wantErr {
+				t.Errorf("openEnvelope() = %v, %v, want ok %v, error %v", ok, err, tt.ok, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvelopePolicy(t *testing.T) {
+	newer := sealEnvelope([]byte(`"new"`))
+	newer[len(envelopeMagic)] = envelopeVersion + 1
+	legacy := []byte(`"old"`)
+	tests := []struct {
+		name    string
+		policy  EnvelopePolicy
+		data    []byte
+		want    string
+		wantErr error
+	}{
+		{"legacy ignore", EnvelopeIgnoreUnknown, legacy, "old", nil},
+		{"legacy miss", EnvelopeTreatAsMiss, legacy, "", ErrCacheNotFound},
+		{"legacy error", EnvelopeError, legacy, "", ErrEnvelopeVersion},
+		{"newer ignore", EnvelopeIgnoreUnknown, newer, "new", nil},
+		{"newer miss", EnvelopeTreatAsMiss, newer, "", ErrCacheNotFound},
+		{"newer error", EnvelopeError, newer, "", ErrEnvelopeVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vc := valueCodec{encoding: &JSONEncoding{}, envelope: true, envelopePolicy: tt.policy}
+			var got string
+			err := vc.decode(tt.data, &got)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) || got != tt.want {
+				t.Errorf("decode() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// 未启用信封时旧格式即当前格式，不受策略影响
+	vc := valueCodec{encoding: &JSONEncoding{}, envelopePolicy: EnvelopeError}
+	var got string
+	if err := vc.decode(legacy, &got); err != nil || got != "old" {
+		t.Errorf("未启用信封 decode() = %q, %v, want old", got, err)
+	}
+}
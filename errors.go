@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEncode 编码错误，可通过errors.Is判断
+	ErrEncode = errors.New("编码错误")
+	// ErrDecode 解码错误
+	ErrDecode = errors.New("解码错误")
+	// ErrKeyBuild 构建缓存键错误
+	ErrKeyBuild = errors.New("构建缓存键错误")
+	// ErrBackend 缓存后端(Redis、内存等)操作错误
+	ErrBackend = errors.New("缓存后端错误")
+)
+
+// KeyError 单个键的错误
+type KeyError struct {
+	// Key 出错的键
+	Key string
+	// Err 具体错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("键=%s: %v", e.Key, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// MultiKeyError 多个键的错误集合
+type MultiKeyError []*KeyError
+
+// Error 实现error接口
+func (e MultiKeyError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, ke := range e {
+		msgs = append(msgs, ke.Error())
+	}
+	return fmt.Sprintf("%d个键处理失败: [%s]", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap 返回所有底层错误，支持errors.Is和errors.As
+func (e MultiKeyError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, ke := range e {
+		errs = append(errs, ke)
+	}
+	return errs
+}
+
+// Keys 返回所有出错的键
+func (e MultiKeyError) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for _, ke := range e {
+		keys = append(keys, ke.Key)
+	}
+	return keys
+}
+
+// buildCacheKeys 批量构造缓存键，跳过无效的键并收集其错误
+func buildCacheKeys(keyPrefix string, keys []string) ([]string, MultiKeyError) {
+	var keyErrs MultiKeyError
+	cacheKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		cacheKey, err := BuildCacheKey(keyPrefix, key)
+		if err != nil {
+			keyErrs = append(keyErrs, &KeyError{Key: key, Err: fmt.Errorf("%w: %w", ErrKeyBuild, err)})
+			continue
+		}
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	return cacheKeys, keyErrs
+}
+
+// errOrNil 没有错误时返回nil，避免返回非nil的空接口值
+func (e MultiKeyError) errOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,173 @@
+// Package etcd 基于etcd的缓存后端，过期时间通过租约实现
+// 适用于控制面等希望缓存数据保存在已有etcd集群并保留其一致性保证的场景
+package etcd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/smart-unicom/cache"
+)
+
+// maxTxnOps etcd单个事务默认允许的最大操作数
+const maxTxnOps = 128
+
+// Store etcd存储，实现cache.Store
+type Store struct {
+	client *clientv3.Client
+}
+
+var (
+	_ cache.Store            = (*Store)(nil)
+	_ cache.StoreMultiGetter = (*Store)(nil)
+	_ cache.StoreIncrementer = (*Store)(nil)
+	_ cache.StoreSetNXer     = (*Store)(nil)
+)
+
+// NewStore 创建etcd存储
+func NewStore(client *clientv3.Client) *Store {
+	return &Store{client: client}
+}
+
+// NewCache 创建etcd缓存
+func NewCache(client *clientv3.Client, keyPrefix string, encode cache.Encoding, newObject func() interface{}, opts ...cache.CacheOption) cache.Cache {
+	return cache.NewStoreCache(NewStore(client), keyPrefix, encode, newObject, opts...)
+}
+
+// leaseOption 为过期时间创建租约，ttl为0时不使用租约
+// etcd租约以秒为单位，不足1秒按1秒计算
+func (s *Store) leaseOption(ctx context.Context, ttl time.Duration) ([]clientv3.OpOption, error) {
+	if ttl <= 0 {
+		return nil, nil
+	}
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	lease, err := s.client.Grant(ctx, seconds)
+	if err != nil {
+		return nil, err
+	}
+	return []clientv3.OpOption{clientv3.WithLease(lease.ID)}, nil
+}
+
+// Get 获取值
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, cache.ErrCacheNotFound
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// Set 设置值，过期时间通过租约实现
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	opts, err := s.leaseOption(ctx, ttl)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Put(ctx, key, string(value), opts...)
+	return err
+}
+
+// SetNX 仅在键不存在时设置值，通过比较创建版本的事务实现
+func (s *Store) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	opts, err := s.leaseOption(ctx, ttl)
+	if err != nil {
+		return false, err
+	}
+	txn, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(value), opts...)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	return txn.Succeeded, nil
+}
+
+// Del 删除值，按事务批量删除
+func (s *Store) Del(ctx context.Context, keys ...string) error {
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := min(start+maxTxnOps, len(keys))
+		ops := make([]clientv3.Op, 0, end-start)
+		for _, key := range keys[start:end] {
+			ops = append(ops, clientv3.OpDelete(key))
+		}
+		if _, err := s.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiGet 批量获取，按事务读取保证同一批次的一致性
+func (s *Store) MultiGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := min(start+maxTxnOps, len(keys))
+		ops := make([]clientv3.Op, 0, end-start)
+		for _, key := range keys[start:end] {
+			ops = append(ops, clientv3.OpGet(key))
+		}
+		resp, err := s.client.Txn(ctx).Then(ops...).Commit()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Responses {
+			for _, kv := range r.GetResponseRange().Kvs {
+				values[string(kv.Key)] = kv.Value
+			}
+		}
+	}
+	return values, nil
+}
+
+// IncrWithTTL 原子自增，通过比较修订版本的事务重试实现，仅在键新建时设置过期时间
+func (s *Store) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	for {
+		resp, err := s.client.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+
+		var (
+			value int64
+			cmp   clientv3.Cmp
+			opts  []clientv3.OpOption
+		)
+		if len(resp.Kvs) == 0 {
+			value = delta
+			cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+			if opts, err = s.leaseOption(ctx, ttl); err != nil {
+				return 0, err
+			}
+		} else {
+			kv := resp.Kvs[0]
+			current, err := cache.ParseCounter(kv.Value)
+			if err != nil {
+				return 0, err
+			}
+			value = current + delta
+			cmp = clientv3.Compare(clientv3.ModRevision(key), "=", kv.ModRevision)
+			if kv.Lease != 0 {
+				opts = []clientv3.OpOption{clientv3.WithLease(clientv3.LeaseID(kv.Lease))}
+			}
+		}
+
+		txn, err := s.client.Txn(ctx).If(cmp).Then(clientv3.OpPut(key, strconv.FormatInt(value, 10), opts...)).Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txn.Succeeded {
+			return value, nil
+		}
+		// 并发修改导致比较失败，重新读取后重试
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
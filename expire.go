@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StoreExpirer 支持单独重设过期时间的存储，ttl为0表示永不过期，键不存在时返回ErrCacheNotFound
+// 未实现时Expire先读取再写回，两步不是原子的
+type StoreExpirer interface {
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// TTL 获取剩余过期时间，永不过期时返回0
+// 过期时间由底层存储记录：ristretto在条目中保存过期时间并通过GetTTL查询，lru和deterministic引擎同样实现了GetTTL，
+// 因此不另外维护过期时间映射表；映射表还需要与ristretto的准入拒绝和淘汰同步，否则会残留已被淘汰的键
+func (m *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, ok := m.client.GetTTL(cacheKey)
+	if !ok {
+		return 0, ErrCacheNotFound
+	}
+	return ttl, nil
+}
+
+// Expire 重设过期时间，ttl按过期时间策略修正
+// 底层存储不支持单独修改过期时间，使用原值重新写入
+func (m *memoryCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = m.expiration(ttl, key)
+	if err != nil {
+		return err
+	}
+	data, ok := m.client.Get(cacheKey)
+	if !ok {
+		return ErrCacheNotFound
+	}
+	if !m.client.SetWithTTL(cacheKey, data, 0, ttl) {
+		return fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
+	}
+	m.client.Wait()
+	return nil
+}
+
+// TTL 获取剩余过期时间，永不过期时返回0
+func (c *redisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return redisTTL(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// Expire 重设过期时间，ttl按过期时间策略修正
+func (c *redisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.redisExpire(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, ttl)
+}
+
+// TTL 获取剩余过期时间，永不过期时返回0
+func (c *redisClusterCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return redisTTL(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// Expire 重设过期时间，ttl按过期时间策略修正
+func (c *redisClusterCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.redisExpire(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, ttl)
+}
+
+// redisTTL 使用PTTL读取剩余过期时间
+func redisTTL(ctx context.Context, client redis.Cmdable, keyPrefix, key string) (time.Duration, error) {
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err := client.PTTL(ctx, cacheKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%w: 客户端获取过期时间错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	switch {
+	case ttl == -2:
+		return 0, ErrCacheNotFound
+	case ttl < 0:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// redisExpire 使用PEXPIRE重设过期时间，修正后为0(永不过期)时使用PERSIST
+func (o *cacheOptions) redisExpire(ctx context.Context, client redis.Cmdable, keyPrefix, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = o.expiration(ttl, key)
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		ok, err := client.PExpire(ctx, cacheKey, ttl).Result()
+		if err != nil {
+			return fmt.Errorf("%w: 客户端设置过期时间错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+		}
+		if !ok {
+			return ErrCacheNotFound
+		}
+		return nil
+	}
+	// 键不存在和键本来就永不过期时PERSIST都返回false，需要再确认键是否存在
+	ok, err := client.Persist(ctx, cacheKey).Result()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置过期时间错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	if ok {
+		return nil
+	}
+	n, err := client.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端查询键错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	if n == 0 {
+		return ErrCacheNotFound
+	}
+	return nil
+}
+
+// TTL 获取剩余过期时间，存储未实现StoreTTLGetter时返回ErrNotSupported
+func (c *storeCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	getter, ok := c.store.(StoreTTLGetter)
+	if !ok {
+		return 0, fmt.Errorf("%w: TTL", ErrNotSupported)
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	_, ttl, err := getter.GetWithTTL(ctx, cacheKey)
+	if err != nil {
+		if isMiss(err) {
+			return 0, ErrCacheNotFound
+		}
+		return 0, fmt.Errorf("%w: 存储获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return ttl, nil
+}
+
+// Expire 重设过期时间，ttl按过期时间策略修正，存储未实现StoreExpirer时读取原值后重新写入
+func (c *storeCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = c.expiration(ttl, key)
+	if err != nil {
+		return err
+	}
+	if expirer, ok := c.store.(StoreExpirer); ok {
+		err = expirer.Expire(ctx, cacheKey, ttl)
+	} else {
+		var data []byte
+		if data, err = c.store.Get(ctx, cacheKey); err == nil {
+			err = c.store.Set(ctx, cacheKey, data, ttl)
+		}
+	}
+	if err != nil {
+		if isMiss(err) {
+			return ErrCacheNotFound
+		}
+		return fmt.Errorf("%w: 存储设置过期时间错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
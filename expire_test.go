@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryTTLAndExpire(t *testing.T) {
+	// 使用默认的ristretto引擎
+	st, err := newMemoryStore(defaultMemoryConfig(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	c := NewMemoryCacheWithStore(st, "test", &JSONEncoding{}, func() interface{} { return new(string) })
+	ctx := context.Background()
+
+	val := "v"
+	if err := c.Set(ctx, "k", &val, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, err := c.TTL(ctx, "k"); err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL() = %v, %v, want (0, 1m]", ttl, err)
+	}
+
+	if err := c.Expire(ctx, "k", time.Hour); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	if ttl, err := c.TTL(ctx, "k"); err != nil || ttl <= time.Minute || ttl > time.Hour {
+		t.Errorf("Expire后TTL() = %v, %v, want (1m, 1h]", ttl, err)
+	}
+
+	if _, err := c.TTL(ctx, "missing"); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("TTL(missing) error = %v, want ErrCacheNotFound", err)
+	}
+	if err := c.Expire(ctx, "missing", time.Hour); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("Expire(missing) error = %v, want ErrCacheNotFound", err)
+	}
+}
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 扩展接口：Cache只包含核心的读写方法，其他能力由可选的扩展接口提供，外部实现可以按需实现
+// 内置的内存、Redis、Redis集群和存储缓存以及各包装器实现了所有扩展接口(ExtendedCache)，
+// 通过Extend获取，底层缓存未实现某个扩展接口时对应方法返回ErrNotSupported
+
+// MultiNotFoundSetter 支持批量写入未找到占位符的缓存
+type MultiNotFoundSetter interface {
+	// MultiSetCacheWithNotFound 批量设置未找到的缓存
+	MultiSetCacheWithNotFound(ctx context.Context, keys []string) error
+}
+
+// TombstoneDeleter 支持删除后写入墓碑标记的缓存
+type TombstoneDeleter interface {
+	// DelWithTombstone 删除数据并写入短期墓碑标记
+	DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// Incrementer 支持原子自增的缓存
+type Incrementer interface {
+	// IncrWithTTL 原子自增，仅在键新建时设置过期时间
+	IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// BatchDeleter 支持分片批量删除大量键的缓存
+type BatchDeleter interface {
+	// DelMany 分片批量删除大量键
+	DelMany(ctx context.Context, keys []string, opts DelManyOptions) error
+}
+
+// Describer 支持查询条目元数据的缓存
+type Describer interface {
+	// Describe 获取缓存条目的元数据
+	Describe(ctx context.Context, key string) (EntryInfo, error)
+}
+
+// DelayedDeleter 支持延迟双删的缓存
+type DelayedDeleter interface {
+	// DelDelayed 立即删除数据，delay后再删除一次
+	DelDelayed(ctx context.Context, key string, delay time.Duration) error
+}
+
+// ExistenceChecker 支持判断键是否存在的缓存
+type ExistenceChecker interface {
+	// Exists 键是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// NXSetter 支持仅在键不存在时写入的缓存
+type NXSetter interface {
+	// SetNX 仅在键不存在时设置数据，写入成功时返回true
+	SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error)
+}
+
+// Tagger 支持按标签批量失效的缓存
+type Tagger interface {
+	// SetWithTags 设置数据并加入标签
+	SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error
+	// InvalidateTag 删除标签下的所有键，返回键数量和释放的估算字节数
+	InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error)
+	// PreviewInvalidateTag 演练标签失效，返回标签下的键(不含键前缀)，不删除数据
+	PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error)
+}
+
+// PatternDeleter 支持按匹配模式删除键的缓存
+type PatternDeleter interface {
+	// DelPattern 删除匹配模式(不含键前缀)的键，返回删除的键数量
+	DelPattern(ctx context.Context, pattern string) (int64, error)
+}
+
+// Copier 支持复制和重命名键的缓存
+type Copier interface {
+	// Copy 复制键到目标键
+	Copy(ctx context.Context, src, dst string, preserveTTL bool) error
+	// Rename 重命名键并保留剩余过期时间
+	Rename(ctx context.Context, src, dst string) error
+}
+
+// ExtendedCache 实现了所有扩展接口的缓存
+type ExtendedCache interface {
+	Cache
+	MultiNotFoundSetter
+	TombstoneDeleter
+	Incrementer
+	BatchDeleter
+	Describer
+	DelayedDeleter
+	ExistenceChecker
+	NXSetter
+	Tagger
+	PatternDeleter
+	Copier
+}
+
+// Extend 获取缓存的扩展方法，c已实现ExtendedCache时原样返回
+// 否则返回的实例按方法检查c实现的扩展接口，未实现的方法返回ErrNotSupported
+func Extend(c Cache) ExtendedCache {
+	if ext, ok := c.(ExtendedCache); ok {
+		return ext
+	}
+	return forwarder{c}
+}
+
+// unsupported 缓存未实现扩展方法的错误
+func unsupported(c Cache, method string) error {
+	return fmt.Errorf("%w: %T未实现%s", ErrNotSupported, c, method)
+}
+
+// forwarder 将扩展方法转发给被包装的缓存，被包装的缓存未实现对应的扩展接口时返回ErrNotSupported
+// 各包装器嵌入forwarder，未覆盖的扩展方法原样转发
+type forwarder struct {
+	Cache
+}
+
+// MultiSetCacheWithNotFound 转发批量设置未找到的缓存
+func (f forwarder) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	ext, ok := f.Cache.(MultiNotFoundSetter)
+	if !ok {
+		return unsupported(f.Cache, "MultiSetCacheWithNotFound")
+	}
+	return ext.MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 转发删除并写入墓碑标记
+func (f forwarder) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	ext, ok := f.Cache.(TombstoneDeleter)
+	if !ok {
+		return unsupported(f.Cache, "DelWithTombstone")
+	}
+	return ext.DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 转发原子自增
+func (f forwarder) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ext, ok := f.Cache.(Incrementer)
+	if !ok {
+		return 0, unsupported(f.Cache, "IncrWithTTL")
+	}
+	return ext.IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 转发分片批量删除
+func (f forwarder) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	ext, ok := f.Cache.(BatchDeleter)
+	if !ok {
+		return unsupported(f.Cache, "DelMany")
+	}
+	return ext.DelMany(ctx, keys, opts)
+}
+
+// Describe 转发查询条目元数据
+func (f forwarder) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	ext, ok := f.Cache.(Describer)
+	if !ok {
+		return EntryInfo{}, unsupported(f.Cache, "Describe")
+	}
+	return ext.Describe(ctx, key)
+}
+
+// DelDelayed 转发延迟双删
+func (f forwarder) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	ext, ok := f.Cache.(DelayedDeleter)
+	if !ok {
+		return unsupported(f.Cache, "DelDelayed")
+	}
+	return ext.DelDelayed(ctx, key, delay)
+}
+
+// Exists 转发判断键是否存在
+func (f forwarder) Exists(ctx context.Context, key string) (bool, error) {
+	ext, ok := f.Cache.(ExistenceChecker)
+	if !ok {
+		return false, unsupported(f.Cache, "Exists")
+	}
+	return ext.Exists(ctx, key)
+}
+
+// SetNX 转发仅在键不存在时设置数据
+func (f forwarder) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	ext, ok := f.Cache.(NXSetter)
+	if !ok {
+		return false, unsupported(f.Cache, "SetNX")
+	}
+	return ext.SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 转发设置数据并加入标签
+func (f forwarder) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	ext, ok := f.Cache.(Tagger)
+	if !ok {
+		return unsupported(f.Cache, "SetWithTags")
+	}
+	return ext.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 转发删除标签下的所有键
+func (f forwarder) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	ext, ok := f.Cache.(Tagger)
+	if !ok {
+		return nil, unsupported(f.Cache, "InvalidateTag")
+	}
+	return ext.InvalidateTag(ctx, tag)
+}
+
+// PreviewInvalidateTag 转发标签失效演练
+func (f forwarder) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	ext, ok := f.Cache.(Tagger)
+	if !ok {
+		return nil, unsupported(f.Cache, "PreviewInvalidateTag")
+	}
+	return ext.PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 转发按匹配模式删除
+func (f forwarder) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	ext, ok := f.Cache.(PatternDeleter)
+	if !ok {
+		return 0, unsupported(f.Cache, "DelPattern")
+	}
+	return ext.DelPattern(ctx, pattern)
+}
+
+// Copy 转发复制键
+func (f forwarder) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	ext, ok := f.Cache.(Copier)
+	if !ok {
+		return unsupported(f.Cache, "Copy")
+	}
+	return ext.Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 转发重命名键
+func (f forwarder) Rename(ctx context.Context, src, dst string) error {
+	ext, ok := f.Cache.(Copier)
+	if !ok {
+		return unsupported(f.Cache, "Rename")
+	}
+	return ext.Rename(ctx, src, dst)
+}
+
+var (
+	_ ExtendedCache = (*memoryCache)(nil)
+	_ ExtendedCache = (*redisCache)(nil)
+	_ ExtendedCache = (*redisClusterCache)(nil)
+	_ ExtendedCache = (*storeCache)(nil)
+	_ ExtendedCache = forwarder{}
+)
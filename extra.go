@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ExtraString 获取Config.Extra中的配置并转换为字符串，不存在时返回空字符串
+// 数字和布尔值按默认格式转换，便于在配置文件中直接写consistent_read: true等
+func (c *Config) ExtraString(name string) string {
+	v, ok := c.Extra[name]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// DecodeExtra 将Config.Extra解码到结构体v，字段使用json标签匹配
+// 用于后端定义自己的配置结构，支持嵌套的配置段
+func (c *Config) DecodeExtra(v interface{}) error {
+	data, err := json.Marshal(normalizeExtra(c.Extra))
+	if err != nil {
+		return fmt.Errorf("编码Extra配置失败: %w", err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解码Extra配置失败: %w", err)
+	}
+	return nil
+}
+
+// normalizeExtra 将YAML解码产生的map[interface{}]interface{}转换为map[string]interface{}，以便编码为JSON
+func normalizeExtra(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[k] = normalizeExtra(val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = normalizeExtra(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, val := range t {
+			s[i] = normalizeExtra(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
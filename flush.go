@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrFlushNotForced 未设置Force且不是演练模式时拒绝清空
+var ErrFlushNotForced = errors.New("清空命名空间需要显式设置Force或使用DryRun")
+
+// FlushOptions 清空命名空间的选项
+type FlushOptions struct {
+	// Force 必须显式设置为true才会真正删除
+	Force bool
+	// DryRun 演练模式，只扫描并报告将要删除的键，不做删除
+	DryRun bool
+	// BatchSize 每批SCAN和UNLINK的键数量，默认500
+	BatchSize int
+	// RateLimit 每个节点每秒最多删除的键数量，0表示不限制
+	RateLimit int
+	// SampleLimit 报告中最多返回的键数量，默认100
+	SampleLimit int
+}
+
+// FlushReport 清空命名空间的报告
+type FlushReport struct {
+	// DryRun 是否为演练模式
+	DryRun bool
+	// Nodes 扫描的节点数量
+	Nodes int
+	// Matched 匹配前缀的键数量
+	Matched int64
+	// Deleted 实际删除的键数量
+	Deleted int64
+	// SampleKeys 匹配的键样例
+	SampleKeys []string
+	// Duration 耗时
+	Duration time.Duration
+}
+
+// NamespaceFlusher 支持清空命名空间的提供者，由Redis单机和集群提供者实现
+type NamespaceFlusher interface {
+	// FlushNamespace 删除提供者键前缀下的所有键
+	FlushNamespace(ctx context.Context, opts FlushOptions) (*FlushReport, error)
+}
+
+// FlushNamespace 删除键前缀下的所有键
+// 集群模式下扫描每个主节点，使用UNLINK异步释放内存，避免阻塞Redis
+func FlushNamespace(ctx context.Context, client redis.UniversalClient, keyPrefix string, opts FlushOptions) (*FlushReport, error) {
+	if keyPrefix == "" {
+		return nil, errors.New("键前缀为空，拒绝清空整个数据库")
+	}
+	if !opts.Force && !opts.DryRun {
+		return nil, ErrFlushNotForced
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.SampleLimit <= 0 {
+		opts.SampleLimit = 100
+	}
+
+	start := time.Now()
+	report := &FlushReport{DryRun: opts.DryRun}
+	pattern := escapeGlob(keyPrefix) + ":*"
+	var mu sync.Mutex
+	flushNode := func(ctx context.Context, node *redis.Client) error {
+		matched, deleted, samples, err := flushNodeKeys(ctx, node, pattern, opts)
+		mu.Lock()
+		defer mu.Unlock()
+		report.Nodes++
+		report.Matched += matched
+		report.Deleted += deleted
+		for _, key := range samples {
+			if len(report.SampleKeys) < opts.SampleLimit {
+				report.SampleKeys = append(report.SampleKeys, key)
+			}
+		}
+		return err
+	}
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, flushNode)
+	case *redis.Client:
+		err = flushNode(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", client)
+	}
+	report.Duration = time.Since(start)
+	if err != nil {
+		return report, fmt.Errorf("%w: 清空命名空间错误: %w", ErrBackend, err)
+	}
+	return report, nil
+}
+
+// flushNodeKeys 扫描并删除单个节点上匹配的键
+func flushNodeKeys(ctx context.Context, node *redis.Client, pattern string, opts FlushOptions) (matched, deleted int64, samples []string, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = node.Scan(ctx, cursor, pattern, int64(opts.BatchSize)).Result()
+		if err != nil {
+			return matched, deleted, samples, err
+		}
+		matched += int64(len(keys))
+		for _, key := range keys {
+			if len(samples) >= opts.SampleLimit {
+				break
+			}
+			samples = append(samples, key)
+		}
+
+		if !opts.DryRun && len(keys) > 0 {
+			batchStart := time.Now()
+			// 逐个UNLINK，避免集群模式下跨槽错误
+			pipe := node.Pipeline()
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+			cmds, execErr := pipe.Exec(ctx)
+			if execErr != nil {
+				return matched, deleted, samples, execErr
+			}
+			for _, cmd := range cmds {
+				deleted += cmd.(*redis.IntCmd).Val()
+			}
+			if opts.RateLimit > 0 {
+				wait := time.Duration(len(keys))*time.Second/time.Duration(opts.RateLimit) - time.Since(batchStart)
+				if wait > 0 {
+					select {
+					case <-ctx.Done():
+						return matched, deleted, samples, ctx.Err()
+					case <-time.After(wait):
+					}
+				}
+			}
+		}
+
+		if cursor == 0 {
+			return matched, deleted, samples, nil
+		}
+	}
+}
+
+// escapeGlob 转义SCAN匹配模式中的特殊字符
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FlushNamespace 删除Redis键前缀下的所有键
+func (p *redisProvider) FlushNamespace(ctx context.Context, opts FlushOptions) (*FlushReport, error) {
+	if err := p.redisConfig.ProxyMode.check("scan"); err != nil {
+		return nil, err
+	}
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return FlushNamespace(ctx, p.client, p.keyPrefix, opts)
+}
+
+// FlushNamespace 删除Redis集群键前缀下的所有键
+func (p *redisClusterProvider) FlushNamespace(ctx context.Context, opts FlushOptions) (*FlushReport, error) {
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return FlushNamespace(ctx, p.client, p.keyPrefix, opts)
+}
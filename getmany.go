@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// Result 批量获取中单个键的结果，值和错误二选一
+type Result[T any] struct {
+	// Value 获取到的值，仅在Err为nil时有效
+	Value T
+	// Err 该键的错误，可能是ErrCacheNotFound、ErrPlaceholder、ErrTombstone、ErrDecode或后端错误
+	Err error
+}
+
+// Found 是否成功获取到值
+func (r Result[T]) Found() bool {
+	return r.Err == nil
+}
+
+// Miss 是否未命中
+func (r Result[T]) Miss() bool {
+	return errors.Is(r.Err, ErrCacheNotFound)
+}
+
+// Placeholder 是否为缓存穿透占位符或墓碑标记
+func (r Result[T]) Placeholder() bool {
+	return errors.Is(r.Err, ErrPlaceholder) || errors.Is(r.Err, ErrTombstone)
+}
+
+// GetMany 批量获取缓存，每个键单独返回值或错误
+// 与MultiGet静默跳过未命中和解码失败的键不同，调用方可以精确区分每个键的结果
+// 仅在上下文取消时返回错误，此时已获取的结果仍然返回
+func GetMany[T any](ctx context.Context, c Cache, keys []string) (map[string]Result[T], error) {
+	results := make(map[string]Result[T], len(keys))
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if _, ok := results[key]; ok {
+			continue
+		}
+		var r Result[T]
+		r.Err = c.Get(ctx, key, &r.Value)
+		if r.Err != nil {
+			var zero T
+			r.Value = zero
+		}
+		results[key] = r
+	}
+	return results, nil
+}
@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound 加载函数返回该错误表示数据源中不存在该数据
+	// GetOrSet收到该错误时写入未找到占位符并返回ErrNotFoundCached，禁用占位符时原样返回ErrNotFound
+	ErrNotFound = errors.New("数据不存在")
+	// ErrNotFoundCached 数据不存在且已缓存未找到占位符，与ErrPlaceholder相同
+	ErrNotFoundCached = ErrPlaceholder
+	// ErrLoaderPanic 合并加载时加载函数panic，等待同一个键加载结果的其他调用返回该错误
+	// 执行加载函数的调用原样panic
+	ErrLoaderPanic = errors.New("加载函数panic")
+)
+
+// Loader 读穿加载函数，缓存未命中时从数据源加载数据
+// 返回ErrNotFound或nil值表示数据不存在，返回的值与Set的参数相同，非指针类型会自动取地址
+type Loader func(ctx context.Context) (interface{}, error)
+
+// CacheSource 读取结果的来源，用于按来源统计指标和设置X-Cache等响应头
+type CacheSource string
+
+const (
+	// SourceNone 未得到结果，如读取或加载失败
+	SourceNone CacheSource = ""
+	// SourceL1 命中单级缓存或两级缓存的L1
+	SourceL1 CacheSource = "l1"
+	// SourceL2 命中两级缓存的L2
+	SourceL2 CacheSource = "l2"
+	// SourceLoader 缓存未命中，由加载函数从数据源加载
+	SourceLoader CacheSource = "loader"
+)
+
+// getOrSetOptions GetOrSet的调用选项
+type getOrSetOptions struct {
+	// forceRefresh 忽略缓存中的数据，总是调用加载函数并覆盖缓存
+	forceRefresh bool
+	// source 记录结果来源，为空时不记录
+	source *CacheSource
+	// earlyBeta 提前过期的beta，为0时不提前刷新
+	earlyBeta float64
+	// admit 回填前的写入准入判断，返回false时不回填，为空时总是回填，见WithWriteLimit
+	admit func(val interface{}) bool
+}
+
+// GetOrSetOption GetOrSet的调用选项
+type GetOrSetOption func(*getOrSetOptions)
+
+// WithForceRefresh 忽略缓存中的数据，调用加载函数并用结果覆盖缓存(包括未找到占位符和墓碑标记)
+// 用于用户触发的刷新，避免先Del再Get时其他请求在两者之间用旧数据回填
+// 同一个键的并发强制刷新只加载一次，但不会合并到强制刷新之前已开始的普通加载
+func WithForceRefresh() GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		o.forceRefresh = true
+	}
+}
+
+// WithSource 将结果来源写入source，命中未找到占位符时也记录命中的缓存层级
+// 返回错误(ErrNotFoundCached除外)时为SourceNone；同一个键并发加载时等待的调用也记为SourceLoader
+func WithSource(source *CacheSource) GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		o.source = source
+	}
+}
+
+// WithEarlyExpiration 开启XFetch提前过期：命中时按加载耗时和剩余过期时间以一定概率在后台提前刷新，热点键不会在负载下同时过期
+// 加载耗时和过期时间记录在值信封中；beta越大越早刷新，不大于0时为1；两级缓存只对L2的命中生效
+func WithEarlyExpiration(beta float64) GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		if beta <= 0 {
+			beta = 1
+		}
+		o.earlyBeta = beta
+	}
+}
+
+// withBackfillAdmit 回填前调用admit判断是否写入，用于在读穿的回填上应用写入限制
+func withBackfillAdmit(admit func(val interface{}) bool) GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		o.admit = admit
+	}
+}
+
+// setSource 记录结果来源
+func (o *getOrSetOptions) setSource(source CacheSource) {
+	if o.source != nil {
+		*o.source = source
+	}
+}
+
+// newGetOrSetOptions 应用调用选项
+func newGetOrSetOptions(opts []GetOrSetOption) getOrSetOptions {
+	var o getOrSetOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// flightCall 进行中的加载
+type flightCall struct {
+	wg  sync.WaitGroup
+	val []byte
+	err error
+	// dups 等待该加载结果的其他调用数
+	dups int
+}
+
+// flightGroup 合并同一个键的并发加载
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do 执行加载，同一个键同时只执行一次，其他调用等待并共享结果
+// fn panic时等待的调用返回ErrLoaderPanic，当前调用在释放等待者后重新panic
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	var recovered interface{}
+	func() {
+		defer func() {
+			if recovered = recover(); recovered != nil {
+				c.val, c.err = nil, fmt.Errorf("%w: %v", ErrLoaderPanic, recovered)
+			}
+		}()
+		c.val, c.err = fn()
+	}()
+
+	// 先删除再唤醒等待者，之后的调用重新加载
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	c.wg.Done()
+
+	if recovered != nil {
+		panic(recovered)
+	}
+	return c.val, c.err
+}
+
+// getOrSet 读穿的通用实现，c为具体的缓存实现，cacheKey用于合并并发加载
+// 命中未找到占位符时返回ErrNotFoundCached，不调用加载函数；禁用占位符时加载到不存在的数据返回ErrNotFound
+// 命中墓碑标记时调用加载函数但不回填缓存；其他读取错误(如后端不可用、解码失败)时调用加载函数并尝试回填
+// 回填失败不影响返回加载的数据；强制刷新时不读取缓存，总是加载并覆盖
+func (o *cacheOptions) getOrSet(ctx context.Context, c Cache, cacheKey, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	options := newGetOrSetOptions(opts)
+	options.setSource(SourceNone)
+	backfill := true
+	if options.forceRefresh {
+		// 强制刷新与普通加载分开合并，避免返回点击刷新之前开始加载的数据
+		cacheKey += "\x00refresh"
+	} else {
+		target := dest
+		var entry *earlyEntry
+		if options.earlyBeta > 0 {
+			entry = &earlyEntry{value: dest}
+			target = entry
+		}
+		err := c.Get(ctx, key, target)
+		switch {
+		case err == nil:
+			options.setSource(SourceL1)
+			if o.skew != nil {
+				o.skew.verify(ctx, c, key, loader)
+			}
+			if entry != nil && entry.due(options.earlyBeta) {
+				o.refreshEarly(ctx, c, cacheKey, key, ttl, loader, options.admit)
+			}
+			return nil
+		case errors.Is(err, ErrPlaceholder):
+			options.setSource(SourceL1)
+			return ErrNotFoundCached
+		}
+		backfill = !errors.Is(err, ErrTombstone)
+	}
+
+	buf, err := o.flight.do(cacheKey, func() ([]byte, error) {
+		return o.load(ctx, c, key, ttl, loader, backfill, options.earlyBeta > 0, options.admit)
+	})
+	if errors.Is(err, ErrNotFoundCached) {
+		options.setSource(SourceLoader)
+	}
+	if err != nil {
+		return err
+	}
+	if err = o.decode(buf, dest); err != nil {
+		return fmt.Errorf("%w: %w, 键=%s, 类型=%T", ErrDecode, err, key, dest)
+	}
+	options.setSource(SourceLoader)
+	return nil
+}
+
+// load 调用加载函数，backfill为true时回填缓存，返回编码后的数据
+// early为true时记录加载耗时，回填的值在信封中记录加载耗时和过期时间；admit不为空时回填前判断是否写入
+func (o *cacheOptions) load(ctx context.Context, c Cache, key string, ttl time.Duration, loader Loader, backfill, early bool, admit func(val interface{}) bool) ([]byte, error) {
+	start := time.Now()
+	val, err := loader(ctx)
+	delta := time.Since(start)
+	if errors.Is(err, ErrNotFound) || (err == nil && val == nil) {
+		if !o.placeholderEnabled() {
+			return nil, ErrNotFound
+		}
+		if backfill {
+			_ = c.SetCacheWithNotFound(ctx, key)
+		}
+		return nil, ErrNotFoundCached
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isPointer(val) {
+		ptr := reflect.New(reflect.TypeOf(val))
+		ptr.Elem().Set(reflect.ValueOf(val))
+		val = ptr.Interface()
+	}
+	buf, err := o.encode(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	if backfill && (admit == nil || admit(val)) {
+		if early {
+			_ = c.Set(ctx, key, newEarlyEntry(val, delta, ttl), ttl)
+		} else {
+			_ = c.Set(ctx, key, val, ttl)
+		}
+	}
+	if len(buf) == 0 && o.placeholderEnabled() {
+		return nil, ErrNotFoundCached
+	}
+	return buf, nil
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (m *memoryCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	return m.getOrSet(ctx, m, cacheKey, key, dest, ttl, loader, opts...)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *redisCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	return c.getOrSet(ctx, c, cacheKey, key, dest, ttl, loader, opts...)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *redisClusterCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	return c.getOrSet(ctx, c, cacheKey, key, dest, ttl, loader, opts...)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *storeCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	return c.getOrSet(ctx, c, cacheKey, key, dest, ttl, loader, opts...)
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitDups 等待键的加载有n个等待者
+func waitDups(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		c, ok := g.calls[key]
+		done := ok && c.dups >= n
+		g.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("等待者数量未达到%d", n)
+}
+
+func TestFlightGroupLoaderPanic(t *testing.T) {
+	g := &flightGroup{}
+	release := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		_, _ = g.do("k", func() ([]byte, error) {
+			<-release
+			panic("boom")
+		})
+	}()
+	waitDups(t, g, "k", 0)
+
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, err := g.do("k", func() ([]byte, error) { return []byte("unused"), nil })
+		waiterErr <- err
+	}()
+	waitDups(t, g, "k", 1)
+	close(release)
+
+	if r := <-panicked; r != "boom" {
+		t.Errorf("加载调用的panic = %v, want boom", r)
+	}
+	select {
+	case err := <-waiterErr:
+		if !errors.Is(err, ErrLoaderPanic) {
+			t.Errorf("等待者 error = %v, want ErrLoaderPanic", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("加载函数panic后等待者未返回")
+	}
+
+	val, err := g.do("k", func() ([]byte, error) { return []byte("v"), nil })
+	if err != nil || string(val) != "v" {
+		t.Errorf("panic后重新加载 = %q, %v, want v", val, err)
+	}
+}
+
+func TestGetOrSetLoaderPanic(t *testing.T) {
+	c := newTestMemoryCache(t)
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("GetOrSet未传播加载函数的panic")
+			}
+		}()
+		var dest string
+		_ = c.GetOrSet(ctx, "k", &dest, time.Minute, func(context.Context) (interface{}, error) {
+			panic("boom")
+		})
+	}()
+
+	done := make(chan error, 1)
+	var dest string
+	go func() {
+		done <- c.GetOrSet(ctx, "k", &dest, time.Minute, func(context.Context) (interface{}, error) {
+			return "v", nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil || dest != "v" {
+			t.Errorf("GetOrSet() = %q, %v, want v", dest, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("加载函数panic后GetOrSet挂起")
+	}
+}
+
+func TestRefreshEarlyLoaderPanic(t *testing.T) {
+	failed := make(chan error, 1)
+	c := newTestMemoryCache(t, WithRefreshErrorHandler(func(key string, err error) {
+		if key == "k" {
+			failed <- err
+		}
+	})).(*memoryCache)
+	ctx := context.Background()
+	old := "old"
+	if err := c.Set(ctx, "k", &old, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	c.refreshEarly(ctx, c, "test:k", "k", time.Minute, func(context.Context) (interface{}, error) {
+		panic("boom")
+	}, nil)
+	select {
+	case err := <-failed:
+		if !errors.Is(err, ErrLoaderPanic) {
+			t.Errorf("刷新失败回调 error = %v, want ErrLoaderPanic", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("加载函数panic后未调用刷新失败回调")
+	}
+
+	var got string
+	if err := c.Get(ctx, "k", &got); err != nil || got != "old" {
+		t.Errorf("Get() = %q, %v, want old", got, err)
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// healthProbeKey 健康检查探测使用的键
+const healthProbeKey = "__cache_health_probe__"
+
+// HealthStatus 缓存健康状态，可直接序列化到/healthz响应中
+type HealthStatus struct {
+	// Healthy 是否健康
+	Healthy bool `json:"healthy"`
+	// Backend 缓存类型
+	Backend CacheType `json:"backend"`
+	// Latency 探测往返耗时
+	Latency time.Duration `json:"latency"`
+	// PoolSize 连接池容量，内存缓存为0
+	PoolSize int `json:"pool_size"`
+	// TotalConns 连接池中的连接总数
+	TotalConns uint32 `json:"total_conns"`
+	// IdleConns 连接池中的空闲连接数
+	IdleConns uint32 `json:"idle_conns"`
+	// Timeouts 获取连接超时的累计次数
+	Timeouts uint32 `json:"timeouts"`
+	// PoolSaturation 连接池饱和度，取值0~1，为使用中连接数与容量之比
+	PoolSaturation float64 `json:"pool_saturation"`
+	// LastError 最近一次检查失败的错误信息
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt 最近一次检查失败的时间
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+	// CheckedAt 本次检查时间
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// healthRecorder 记录最近一次健康检查错误
+type healthRecorder struct {
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// record 记录检查结果并填充状态中的错误信息
+func (h *healthRecorder) record(status *HealthStatus, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.lastError = err.Error()
+		h.lastErrorAt = status.CheckedAt
+	}
+	status.Healthy = err == nil
+	status.LastError = h.lastError
+	status.LastErrorAt = h.lastErrorAt
+}
+
+// fillPoolStats 填充连接池统计信息
+func fillPoolStats(status *HealthStatus, stats *redis.PoolStats, poolSize int) {
+	status.PoolSize = poolSize
+	if stats == nil {
+		return
+	}
+	status.TotalConns = stats.TotalConns
+	status.IdleConns = stats.IdleConns
+	status.Timeouts = stats.Timeouts
+	if poolSize > 0 && stats.TotalConns >= stats.IdleConns {
+		status.PoolSaturation = float64(stats.TotalConns-stats.IdleConns) / float64(poolSize)
+	}
+}
+
+// HealthCheck 检查内存缓存健康状态
+func (p *memoryProvider) HealthCheck(_ context.Context) HealthStatus {
+	status := HealthStatus{Backend: MemoryCache, CheckedAt: time.Now()}
+	start := time.Now()
+	p.client.Get(healthProbeKey)
+	status.Latency = time.Since(start)
+	p.health.record(&status, nil)
+	return status
+}
+
+// HealthCheck 检查Redis健康状态
+func (p *redisProvider) HealthCheck(ctx context.Context) HealthStatus {
+	status := HealthStatus{Backend: RedisCache, CheckedAt: time.Now()}
+	if _, err := p.conn.ensure(); err != nil {
+		p.health.record(&status, err)
+		return status
+	}
+	start := time.Now()
+	err := p.client.Ping(ctx).Err()
+	status.Latency = time.Since(start)
+	fillPoolStats(&status, p.client.PoolStats(), p.client.Options().PoolSize)
+	p.health.record(&status, err)
+	return status
+}
+
+// HealthCheck 检查Redis集群健康状态，所有分片都可达才视为健康
+func (p *redisClusterProvider) HealthCheck(ctx context.Context) HealthStatus {
+	status := HealthStatus{Backend: RedisClusterCache, CheckedAt: time.Now()}
+	if _, err := p.conn.ensure(); err != nil {
+		p.health.record(&status, err)
+		return status
+	}
+	start := time.Now()
+	err := p.pingAll(ctx)
+	status.Latency = time.Since(start)
+	// 集群的连接池统计是所有节点的汇总，容量按节点数计算
+	opts := p.client.Options()
+	fillPoolStats(&status, p.client.PoolStats(), opts.PoolSize*len(opts.Addrs))
+	p.health.record(&status, err)
+	return status
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 缓存状态响应头
+const (
+	// HeaderCache 缓存命中情况，值为HIT或MISS
+	HeaderCache = "X-Cache"
+	// HeaderCacheSource 命中的缓存层级，值为l1或l2
+	HeaderCacheSource = "X-Cache-Source"
+	// HeaderCacheTTL 缓存剩余过期时间(秒)，向上取整
+	HeaderCacheTTL = "X-Cache-TTL"
+)
+
+// X-Cache响应头的值
+const (
+	CacheHit  = "HIT"
+	CacheMiss = "MISS"
+)
+
+// SetCacheHeaders 根据结果来源设置X-Cache等响应头，通常与WithSource配合使用
+// 命中缓存时X-Cache为HIT并设置X-Cache-Source，由加载函数加载时为MISS，SourceNone时不设置任何响应头
+// ttl为剩余过期时间(如TTL方法的返回值)，大于0时设置X-Cache-TTL，0表示永不过期或未知，不设置
+func SetCacheHeaders(h http.Header, source CacheSource, ttl time.Duration) {
+	switch source {
+	case SourceL1, SourceL2:
+		h.Set(HeaderCache, CacheHit)
+		h.Set(HeaderCacheSource, string(source))
+	case SourceLoader:
+		h.Set(HeaderCache, CacheMiss)
+	default:
+		return
+	}
+	if ttl > 0 {
+		seconds := (ttl + time.Second - 1) / time.Second
+		h.Set(HeaderCacheTTL, strconv.FormatInt(int64(seconds), 10))
+	}
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrWithTTLScript 原子自增，仅在键新建时设置过期时间，已存在的键保留原有过期时间
+var incrWithTTLScript = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if created and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return v
+`)
+
+// memoryIncrMu 内存缓存自增锁，ristretto本身不支持原子的读改写
+var memoryIncrMu sync.Mutex
+
+// IncrWithTTL 原子自增，键不存在时以delta创建并设置过期时间ttl
+// 已存在的键只自增不刷新过期时间，适用于计数器和限流窗口
+func (m *memoryCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+
+	memoryIncrMu.Lock()
+	defer memoryIncrMu.Unlock()
+
+	value := delta
+	var expiration time.Duration
+	if data, ok := m.client.Get(cacheKey); ok {
+		dataBytes, _ := data.([]byte)
+		current, err := strconv.ParseInt(string(dataBytes), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: 值不是整数, 键=%s", ErrDecode, key)
+		}
+		value = current + delta
+		expiration, _ = m.client.GetTTL(cacheKey)
+	} else if expiration, err = m.expiration(ttl, key); err != nil {
+		return 0, err
+	}
+
+	ok := m.client.SetWithTTL(cacheKey, []byte(strconv.FormatInt(value, 10)), 0, expiration)
+	if !ok {
+		return 0, fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
+	}
+	m.client.Wait()
+	return value, nil
+}
+
+// IncrWithTTL 原子自增，键不存在时以delta创建并设置过期时间ttl
+func (c *redisCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = c.expiration(ttl, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := redisInt64(incrWithTTLScript.Run(ctx, c.client, []string{cacheKey}, delta, ttl.Milliseconds()))
+	if err != nil {
+		return 0, fmt.Errorf("%w: 客户端自增错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return v, nil
+}
+
+// IncrWithTTL 原子自增，键不存在时以delta创建并设置过期时间ttl
+func (c *redisClusterCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = c.expiration(ttl, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := redisInt64(incrWithTTLScript.Run(ctx, c.client, []string{cacheKey}, delta, ttl.Milliseconds()))
+	if err != nil {
+		return 0, fmt.Errorf("%w: 客户端自增错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return v, nil
+}
@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultInvalidationChannel 默认的失效广播频道
+const defaultInvalidationChannel = "cache:invalidate"
+
+// InvalidationConfig 失效广播配置
+type InvalidationConfig struct {
+	// Channel 失效消息的发布订阅频道，默认cache:invalidate，同一组实例必须一致
+	// 与TieredConfig.Channel使用相同的消息格式，两者可以共用一个频道
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+}
+
+// setDefaults 设置默认值
+func (c *InvalidationConfig) setDefaults() {
+	if c.Channel == "" {
+		c.Channel = defaultInvalidationChannel
+	}
+}
+
+// InvalidationBus 基于Redis发布订阅的失效广播，用于每个实例各自使用内存缓存的部署
+// 写入和删除作用于本实例的缓存，并通知其他实例删除相同的键，其他实例下次读取时重新加载
+// 消息不保证送达，订阅断开期间的失效消息会丢失，本地缓存应设置较短的过期时间作为兜底
+// 计数器(IncrWithTTL)和过期时间(Expire)只作用于本实例，不广播
+type InvalidationBus struct {
+	forwarder
+	client  redis.UniversalClient
+	config  InvalidationConfig
+	source  string
+	onError func(err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewInvalidationBus 创建失效广播，local通常由NewMemoryCache创建，所有实例使用相同的键前缀
+// 需要调用Start开始订阅其他实例的失效消息
+func NewInvalidationBus(local Cache, client redis.UniversalClient, config InvalidationConfig) *InvalidationBus {
+	config.setDefaults()
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &InvalidationBus{
+		forwarder: forwarder{local},
+		client:    client,
+		config:    config,
+		source:    hex.EncodeToString(id),
+	}
+}
+
+// OnError 设置错误回调，如失效消息发布失败、本地删除失败
+func (b *InvalidationBus) OnError(fn func(err error)) *InvalidationBus {
+	b.onError = fn
+	return b
+}
+
+// reportError 回调错误
+func (b *InvalidationBus) reportError(err error) {
+	if err != nil && b.onError != nil {
+		b.onError(err)
+	}
+}
+
+// Start 订阅其他实例的失效消息
+func (b *InvalidationBus) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := b.client.Subscribe(ctx, b.config.Channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		cancel()
+		_ = sub.Close()
+		return err
+	}
+	b.cancel = cancel
+	b.wg.Add(1)
+	go b.consume(ctx, sub)
+	return nil
+}
+
+// consume 处理失效消息，忽略本实例发布的消息
+func (b *InvalidationBus) consume(ctx context.Context, sub *redis.PubSub) {
+	defer b.wg.Done()
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var m tieredMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				b.reportError(err)
+				continue
+			}
+			if m.Source != b.source {
+				m.apply(ctx, b.Cache, b.reportError)
+			}
+		}
+	}
+}
+
+// Close 停止订阅，不关闭本地缓存
+func (b *InvalidationBus) Close() error {
+	b.mu.Lock()
+	cancel := b.cancel
+	b.cancel = nil
+	b.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		b.wg.Wait()
+	}
+	return nil
+}
+
+// publish 通知其他实例删除键，发布失败时通过错误回调报告
+func (b *InvalidationBus) publish(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	b.reportError(b.publishMessage(ctx, tieredMessage{Keys: keys}))
+}
+
+// publishMessage 发布失效消息，填充实例标识和上下文中的键前缀
+func (b *InvalidationBus) publishMessage(ctx context.Context, m tieredMessage) error {
+	m.Source = b.source
+	m.Prefix = KeyPrefixFromContext(ctx, "")
+	return m.publish(ctx, b.client, b.config.Channel)
+}
+
+// Invalidate 删除本实例中的键并通知其他实例删除，不写入任何数据
+// 用于数据源变更后只需要让各实例重新加载的场景，返回本地删除和消息发布的错误
+func (b *InvalidationBus) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := b.Cache.Del(ctx, keys...)
+	return errors.Join(err, b.publishMessage(ctx, tieredMessage{Keys: keys}))
+}
+
+// Set 设置数据，并通知其他实例删除旧值
+func (b *InvalidationBus) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := b.Cache.Set(ctx, key, val, expiration); err != nil {
+		return err
+	}
+	b.publish(ctx, key)
+	return nil
+}
+
+// MultiSet 批量设置数据，并通知其他实例删除旧值
+func (b *InvalidationBus) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	err := b.Cache.MultiSet(ctx, valMap, expiration)
+	keys := make([]string, 0, len(valMap))
+	for key := range valMap {
+		keys = append(keys, key)
+	}
+	b.publish(ctx, keys...)
+	return err
+}
+
+// Del 删除数据，并通知其他实例删除
+func (b *InvalidationBus) Del(ctx context.Context, keys ...string) error {
+	err := b.Cache.Del(ctx, keys...)
+	b.publish(ctx, keys...)
+	return err
+}
+
+// SetCacheWithNotFound 设置未找到的缓存，并通知其他实例删除旧值
+func (b *InvalidationBus) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	if err := b.Cache.SetCacheWithNotFound(ctx, key, ttl...); err != nil {
+		return err
+	}
+	b.publish(ctx, key)
+	return nil
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存，并通知其他实例删除旧值
+func (b *InvalidationBus) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	err := b.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+	b.publish(ctx, keys...)
+	return err
+}
+
+// DelWithTombstone 删除数据并写入墓碑标记，并通知其他实例删除
+func (b *InvalidationBus) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	err := b.forwarder.DelWithTombstone(ctx, key, ttl)
+	b.publish(ctx, key)
+	return err
+}
+
+// DelMany 分片批量删除大量键，并通知其他实例删除
+func (b *InvalidationBus) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	err := b.forwarder.DelMany(ctx, keys, opts)
+	b.publish(ctx, keys...)
+	return err
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次，两次删除都会通知其他实例
+func (b *InvalidationBus) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, b, key, delay)
+}
+
+// SetNX 仅在本实例中键不存在时设置数据，写入成功后通知其他实例删除旧值
+func (b *InvalidationBus) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	ok, err := b.forwarder.SetNX(ctx, key, val, expiration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	b.publish(ctx, key)
+	return true, nil
+}
+
+// SetWithTags 设置数据并加入标签，并通知其他实例删除旧值
+func (b *InvalidationBus) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if err := b.forwarder.SetWithTags(ctx, key, val, expiration, tags...); err != nil {
+		return err
+	}
+	b.publish(ctx, key)
+	return nil
+}
+
+// InvalidateTag 删除标签下的所有键，并通知其他实例失效该标签
+func (b *InvalidationBus) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	report, err := b.forwarder.InvalidateTag(ctx, tag)
+	b.reportError(b.publishMessage(ctx, tieredMessage{Tags: []string{tag}}))
+	return report, err
+}
+
+// DelPattern 删除匹配模式的键，并通知其他实例删除匹配的键，返回本实例删除的键数量
+func (b *InvalidationBus) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	n, err := b.forwarder.DelPattern(ctx, pattern)
+	b.reportError(b.publishMessage(ctx, tieredMessage{Patterns: []string{pattern}}))
+	return n, err
+}
+
+// Copy 复制键到目标键，并通知其他实例删除目标键
+func (b *InvalidationBus) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	err := b.forwarder.Copy(ctx, src, dst, preserveTTL)
+	b.publish(ctx, dst)
+	return err
+}
+
+// Rename 重命名键，并通知其他实例删除两个键
+func (b *InvalidationBus) Rename(ctx context.Context, src, dst string) error {
+	err := b.forwarder.Rename(ctx, src, dst)
+	b.publish(ctx, src, dst)
+	return err
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyeventPatterns 订阅的键事件通知频道
+// 需要Redis开启键事件通知，如: CONFIG SET notify-keyspace-events Egx
+var keyeventPatterns = []string{"__keyevent@*__:del", "__keyevent@*__:expired", "__keyevent@*__:evicted"}
+
+// KeyspaceInvalidator 基于Redis键事件通知的本地缓存失效器
+// 订阅Redis的删除、过期和驱逐事件，将前缀匹配的键从进程内缓存中移除，
+// 作为应用层发布失效消息之外的另一种选择
+type KeyspaceInvalidator struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	local     Cache
+	onError   func(err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewKeyspaceInvalidator 创建键事件失效器
+// keyPrefix为Redis缓存使用的键前缀，local为使用相同键(不含前缀)的进程内缓存
+func NewKeyspaceInvalidator(client redis.UniversalClient, keyPrefix string, local Cache) *KeyspaceInvalidator {
+	return &KeyspaceInvalidator{
+		client:    client,
+		keyPrefix: keyPrefix,
+		local:     local,
+	}
+}
+
+// OnError 设置错误回调，如本地删除失败
+func (k *KeyspaceInvalidator) OnError(fn func(err error)) *KeyspaceInvalidator {
+	k.onError = fn
+	return k
+}
+
+// Start 开始订阅，集群模式下订阅每个主节点
+func (k *KeyspaceInvalidator) Start(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	var subs []*redis.PubSub
+	if cluster, ok := k.client.(*redis.ClusterClient); ok {
+		var subMu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			sub := node.PSubscribe(ctx, keyeventPatterns...)
+			subMu.Lock()
+			subs = append(subs, sub)
+			subMu.Unlock()
+			_, err := sub.Receive(ctx)
+			return err
+		})
+		if err != nil {
+			cancel()
+			for _, sub := range subs {
+				_ = sub.Close()
+			}
+			return err
+		}
+	} else {
+		sub := k.client.PSubscribe(ctx, keyeventPatterns...)
+		if _, err := sub.Receive(ctx); err != nil {
+			cancel()
+			_ = sub.Close()
+			return err
+		}
+		subs = append(subs, sub)
+	}
+
+	k.cancel = cancel
+	for _, sub := range subs {
+		k.wg.Add(1)
+		go k.consume(ctx, sub)
+	}
+	return nil
+}
+
+// consume 处理键事件消息
+func (k *KeyspaceInvalidator) consume(ctx context.Context, sub *redis.PubSub) {
+	defer k.wg.Done()
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			key, ok := k.localKey(msg.Payload)
+			if !ok {
+				continue
+			}
+			if err := k.local.Del(ctx, key); err != nil && k.onError != nil {
+				k.onError(err)
+			}
+		}
+	}
+}
+
+// localKey 将Redis中的键转换为本地缓存的键，前缀不匹配时返回false
+func (k *KeyspaceInvalidator) localKey(cacheKey string) (string, bool) {
+	if k.keyPrefix == "" {
+		return cacheKey, cacheKey != ""
+	}
+	key, ok := strings.CutPrefix(cacheKey, k.keyPrefix+":")
+	return key, ok && key != ""
+}
+
+// Close 停止订阅
+func (k *KeyspaceInvalidator) Close() error {
+	k.mu.Lock()
+	cancel := k.cancel
+	k.cancel = nil
+	k.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		k.wg.Wait()
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyFunc 生成一次注入的延迟
+type LatencyFunc func(r *rand.Rand) time.Duration
+
+// FixedLatency 固定延迟
+func FixedLatency(d time.Duration) LatencyFunc {
+	return func(*rand.Rand) time.Duration {
+		return d
+	}
+}
+
+// UniformLatency [min, max)区间内均匀分布的延迟
+func UniformLatency(min, max time.Duration) LatencyFunc {
+	return func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	}
+}
+
+// NormalLatency 正态分布的延迟，负值按0处理
+func NormalLatency(mean, stddev time.Duration) LatencyFunc {
+	return func(r *rand.Rand) time.Duration {
+		d := time.Duration(r.NormFloat64()*float64(stddev)) + mean
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+}
+
+// LatencyInjector 延迟注入器，用于在预发布环境验证超时和熔断配置
+// 默认不注入，调用Enable后生效，可在运行时随时开关
+type LatencyInjector struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	latency LatencyFunc
+	ops     map[string]bool
+	rand    *rand.Rand
+}
+
+// NewLatencyInjector 创建延迟注入器
+func NewLatencyInjector() *LatencyInjector {
+	return &LatencyInjector{
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Enable 开启延迟注入，ops为空时对所有操作生效，否则只对指定操作(OpGet、OpSet等)生效
+func (l *LatencyInjector) Enable(latency LatencyFunc, ops ...string) {
+	l.mu.Lock()
+	l.latency = latency
+	l.ops = nil
+	if len(ops) > 0 {
+		l.ops = make(map[string]bool, len(ops))
+		for _, op := range ops {
+			l.ops[op] = true
+		}
+	}
+	l.mu.Unlock()
+	l.enabled.Store(latency != nil)
+}
+
+// Disable 关闭延迟注入
+func (l *LatencyInjector) Disable() {
+	l.enabled.Store(false)
+}
+
+// Enabled 是否开启
+func (l *LatencyInjector) Enabled() bool {
+	return l.enabled.Load()
+}
+
+// delay 按配置等待，上下文结束时提前返回上下文错误
+func (l *LatencyInjector) delay(ctx context.Context, op string) error {
+	if !l.enabled.Load() {
+		return nil
+	}
+	l.mu.Lock()
+	if l.latency == nil || (l.ops != nil && !l.ops[op]) {
+		l.mu.Unlock()
+		return nil
+	}
+	d := l.latency(l.rand)
+	l.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// latencyCache 注入延迟的缓存包装
+type latencyCache struct {
+	forwarder
+	injector *LatencyInjector
+}
+
+// WithLatencyInjection 为缓存添加延迟注入，injector为空时返回原缓存
+func WithLatencyInjection(c Cache, injector *LatencyInjector) Cache {
+	if injector == nil {
+		return c
+	}
+	return &latencyCache{
+		forwarder: forwarder{c},
+		injector:  injector,
+	}
+}
+
+// Set 设置数据
+func (c *latencyCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := c.injector.delay(ctx, OpSet); err != nil {
+		return err
+	}
+	return c.Cache.Set(ctx, key, val, expiration)
+}
+
+// Get 获取数据
+func (c *latencyCache) Get(ctx context.Context, key string, val interface{}) error {
+	if err := c.injector.delay(ctx, OpGet); err != nil {
+		return err
+	}
+	return c.Cache.Get(ctx, key, val)
+}
+
+// MultiSet 批量设置数据
+func (c *latencyCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	if err := c.injector.delay(ctx, OpMultiSet); err != nil {
+		return err
+	}
+	return c.Cache.MultiSet(ctx, valMap, expiration)
+}
+
+// MultiGet 批量获取数据
+func (c *latencyCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	if err := c.injector.delay(ctx, OpMultiGet); err != nil {
+		return err
+	}
+	return c.Cache.MultiGet(ctx, keys, valueMap)
+}
+
+// Del 删除数据
+func (c *latencyCache) Del(ctx context.Context, keys ...string) error {
+	if err := c.injector.delay(ctx, OpDel); err != nil {
+		return err
+	}
+	return c.Cache.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (c *latencyCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	if err := c.injector.delay(ctx, OpSetCacheWithNotFound); err != nil {
+		return err
+	}
+	return c.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (c *latencyCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	if err := c.injector.delay(ctx, OpMultiSetCacheWithNotFound); err != nil {
+		return err
+	}
+	return c.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (c *latencyCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.injector.delay(ctx, OpDelWithTombstone); err != nil {
+		return err
+	}
+	return c.forwarder.DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增
+func (c *latencyCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if err := c.injector.delay(ctx, OpIncrWithTTL); err != nil {
+		return 0, err
+	}
+	return c.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 分片批量删除大量键
+func (c *latencyCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	if err := c.injector.delay(ctx, OpDelMany); err != nil {
+		return err
+	}
+	return c.forwarder.DelMany(ctx, keys, opts)
+}
+
+// Describe 获取缓存条目的元数据
+func (c *latencyCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	if err := c.injector.delay(ctx, OpDescribe); err != nil {
+		return EntryInfo{}, err
+	}
+	return c.forwarder.Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *latencyCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	if err := c.injector.delay(ctx, OpGetOrSet); err != nil {
+		return err
+	}
+	return c.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *latencyCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	if err := c.injector.delay(ctx, OpDelDelayed); err != nil {
+		return err
+	}
+	return c.forwarder.DelDelayed(ctx, key, delay)
+}
+
+// TTL 获取剩余过期时间
+func (c *latencyCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := c.injector.delay(ctx, OpTTL); err != nil {
+		return 0, err
+	}
+	return c.Cache.TTL(ctx, key)
+}
+
+// Expire 重设过期时间
+func (c *latencyCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.injector.delay(ctx, OpExpire); err != nil {
+		return err
+	}
+	return c.Cache.Expire(ctx, key, ttl)
+}
+
+// Exists 键是否存在
+func (c *latencyCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := c.injector.delay(ctx, OpExists); err != nil {
+		return false, err
+	}
+	return c.forwarder.Exists(ctx, key)
+}
+
+// SetNX 仅在键不存在时设置数据
+func (c *latencyCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	if err := c.injector.delay(ctx, OpSetNX); err != nil {
+		return false, err
+	}
+	return c.forwarder.SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签
+func (c *latencyCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if err := c.injector.delay(ctx, OpSetWithTags); err != nil {
+		return err
+	}
+	return c.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 删除标签下的所有键
+func (c *latencyCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	if err := c.injector.delay(ctx, OpInvalidateTag); err != nil {
+		return nil, err
+	}
+	return c.forwarder.InvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除匹配模式的键
+func (c *latencyCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := c.injector.delay(ctx, OpDelPattern); err != nil {
+		return 0, err
+	}
+	return c.forwarder.DelPattern(ctx, pattern)
+}
+
+// Copy 复制键到目标键
+func (c *latencyCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	if err := c.injector.delay(ctx, OpCopy); err != nil {
+		return err
+	}
+	return c.forwarder.Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键
+func (c *latencyCache) Rename(ctx context.Context, src, dst string) error {
+	if err := c.injector.delay(ctx, OpRename); err != nil {
+		return err
+	}
+	return c.forwarder.Rename(ctx, src, dst)
+}
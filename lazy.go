@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed 缓存提供者已关闭
+var ErrClosed = errors.New("缓存提供者已关闭")
+
+// lazyConn 连接的延迟初始化状态
+// 非延迟模式下在创建提供者时立即初始化，延迟模式下在首次操作或显式Connect时初始化
+type lazyConn struct {
+	mu     sync.Mutex
+	ready  bool
+	closed bool
+	init   func() Cache
+	cache  Cache
+}
+
+// ensure 确保连接已初始化，返回内部缓存实例
+func (l *lazyConn) ensure() (Cache, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, ErrClosed
+	}
+	if !l.ready {
+		l.cache = l.init()
+		l.ready = true
+	}
+	return l.cache, nil
+}
+
+// close 标记为已关闭，仅在已初始化时调用release释放资源
+func (l *lazyConn) close(release func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if !l.ready {
+		return nil
+	}
+	return release()
+}
+
+// wrap 根据是否延迟连接返回对外暴露的缓存实例
+func (l *lazyConn) wrap(lazy bool) Cache {
+	if !lazy {
+		c, _ := l.ensure()
+		return c
+	}
+	return &lazyCache{conn: l}
+}
+
+// ----------------------------------------------------------------------------
+
+// lazyCache 延迟连接的缓存，首次操作时才创建客户端
+type lazyCache struct {
+	conn *lazyConn
+}
+
+// Set 设置数据
+func (c *lazyCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.Set(ctx, key, val, expiration)
+}
+
+// Get 获取数据
+func (c *lazyCache) Get(ctx context.Context, key string, val interface{}) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.Get(ctx, key, val)
+}
+
+// MultiSet 批量设置数据
+func (c *lazyCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.MultiSet(ctx, valMap, expiration)
+}
+
+// MultiGet 批量获取数据
+func (c *lazyCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.MultiGet(ctx, keys, valueMap)
+}
+
+// Del 删除数据
+func (c *lazyCache) Del(ctx context.Context, keys ...string) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (c *lazyCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (c *lazyCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (c *lazyCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增
+func (c *lazyCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return 0, err
+	}
+	return Extend(inner).IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 分片批量删除大量键
+func (c *lazyCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).DelMany(ctx, keys, opts)
+}
+
+// Describe 获取缓存条目的元数据
+func (c *lazyCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return EntryInfo{}, err
+	}
+	return Extend(inner).Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *lazyCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *lazyCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).DelDelayed(ctx, key, delay)
+}
+
+// TTL 获取剩余过期时间
+func (c *lazyCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return 0, err
+	}
+	return inner.TTL(ctx, key)
+}
+
+// Expire 重设过期时间
+func (c *lazyCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return inner.Expire(ctx, key, ttl)
+}
+
+// Exists 键是否存在
+func (c *lazyCache) Exists(ctx context.Context, key string) (bool, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return false, err
+	}
+	return Extend(inner).Exists(ctx, key)
+}
+
+// SetNX 仅在键不存在时设置数据
+func (c *lazyCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return false, err
+	}
+	return Extend(inner).SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签
+func (c *lazyCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 删除标签下的所有键
+func (c *lazyCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return nil, err
+	}
+	return Extend(inner).InvalidateTag(ctx, tag)
+}
+
+// PreviewInvalidateTag 返回标签下的键
+func (c *lazyCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return nil, err
+	}
+	return Extend(inner).PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除匹配模式的键
+func (c *lazyCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return 0, err
+	}
+	return Extend(inner).DelPattern(ctx, pattern)
+}
+
+// Copy 复制键到目标键
+func (c *lazyCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键
+func (c *lazyCache) Rename(ctx context.Context, src, dst string) error {
+	inner, err := c.conn.ensure()
+	if err != nil {
+		return err
+	}
+	return Extend(inner).Rename(ctx, src, dst)
+}
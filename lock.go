@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockHeld 锁已被其他持有者获取
+	ErrLockHeld = errors.New("锁已被持有")
+	// ErrLockNotHeld 释放锁时锁已过期或已被其他持有者获取
+	ErrLockNotHeld = errors.New("锁未被当前持有者持有")
+)
+
+// unlockScript 令牌一致时才删除锁，避免锁过期后误删其他持有者的锁
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Unlocker 已获取的锁
+type Unlocker interface {
+	// Unlock 释放锁，锁已过期或被其他持有者获取时返回ErrLockNotHeld
+	Unlock(ctx context.Context) error
+}
+
+// Locker 锁，用于防止缓存击穿时的重复加载和任务去重
+// 锁在ttl后自动释放，持有者崩溃时不会永久占用
+type Locker interface {
+	// Lock 获取锁，已被持有时按重试间隔等待，直到获取成功或ctx结束
+	Lock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error)
+	// TryLock 尝试获取锁，已被持有时返回ErrLockHeld
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error)
+}
+
+// LockerProvider 支持锁的提供者
+type LockerProvider interface {
+	// Locker 获取使用提供者键前缀的锁
+	Locker() Locker
+}
+
+// LockOptions 锁配置
+type LockOptions struct {
+	// RetryInterval Lock等待锁释放时的重试间隔，默认50毫秒
+	RetryInterval time.Duration
+}
+
+// setDefaults 设置默认值
+func (o *LockOptions) setDefaults() {
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = 50 * time.Millisecond
+	}
+}
+
+// lockKeySegment 锁键的命名空间，锁键为keyPrefix:lock:key，与缓存数据的键分开
+// 避免锁与同名的缓存键互相覆盖，也避免按前缀清理缓存时误删锁
+const lockKeySegment = "lock"
+
+// buildLockKey 构造锁键
+func buildLockKey(keyPrefix, key string) (string, error) {
+	if key == "" {
+		return "", errors.New("[缓存] 键不能为空")
+	}
+	return BuildCacheKey(keyPrefix, lockKeySegment+":"+key)
+}
+
+// lockToken 生成随机的锁令牌，用于释放时校验持有者
+func lockToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// lockWithRetry 按间隔重试tryLock，直到获取成功、出现其他错误或ctx结束
+func lockWithRetry(ctx context.Context, interval time.Duration, tryLock func() (Unlocker, error)) (Unlocker, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		unlocker, err := tryLock()
+		if !errors.Is(err, ErrLockHeld) {
+			return unlocker, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrLockHeld, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// redisLocker 基于Redis SET NX PX的锁，释放时通过Lua脚本校验令牌
+type redisLocker struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	opts      LockOptions
+}
+
+// NewRedisLocker 创建基于Redis的锁，Redis单机和集群客户端都可以使用，锁键为keyPrefix:lock:key
+// 锁只在单个Redis节点上获取，主从切换时可能被两个持有者同时获取，不适用于要求严格互斥的场景
+func NewRedisLocker(client redis.UniversalClient, keyPrefix string, opts LockOptions) Locker {
+	opts.setDefaults()
+	return &redisLocker{client: client, keyPrefix: keyPrefix, opts: opts}
+}
+
+// Lock 获取锁，已被持有时等待
+func (l *redisLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	return lockWithRetry(ctx, l.opts.RetryInterval, func() (Unlocker, error) {
+		return l.TryLock(ctx, key, ttl)
+	})
+}
+
+// TryLock 尝试获取锁
+func (l *redisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("锁的过期时间必须大于0: %s", ttl)
+	}
+	cacheKey, err := buildLockKey(KeyPrefixFromContext(ctx, l.keyPrefix), key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	token := lockToken()
+	ok, err := l.client.SetNX(ctx, cacheKey, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 客户端获取锁错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: 缓存键=%s", ErrLockHeld, cacheKey)
+	}
+	return &redisUnlocker{client: l.client, cacheKey: cacheKey, token: token}, nil
+}
+
+// redisUnlocker Redis锁的持有者
+type redisUnlocker struct {
+	client   redis.UniversalClient
+	cacheKey string
+	token    string
+}
+
+// Unlock 令牌一致时删除锁
+func (u *redisUnlocker) Unlock(ctx context.Context) error {
+	n, err := unlockScript.Run(ctx, u.client, []string{u.cacheKey}, u.token).Int64()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端释放锁错误: %w, 缓存键=%s", ErrBackend, err, u.cacheKey)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: 缓存键=%s", ErrLockNotHeld, u.cacheKey)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// memoryLock 进程内锁的状态
+type memoryLock struct {
+	token    string
+	expireAt time.Time
+}
+
+// memoryLocker 进程内的锁，用于内存缓存和单实例部署
+type memoryLocker struct {
+	keyPrefix string
+	opts      LockOptions
+	mu        sync.Mutex
+	locks     map[string]memoryLock
+}
+
+// NewMemoryLocker 创建进程内的锁，只在同一个Locker内互斥，锁键与Redis锁相同为keyPrefix:lock:key
+func NewMemoryLocker(keyPrefix string, opts LockOptions) Locker {
+	opts.setDefaults()
+	return &memoryLocker{keyPrefix: keyPrefix, opts: opts, locks: make(map[string]memoryLock)}
+}
+
+// Lock 获取锁，已被持有时等待
+func (l *memoryLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	return lockWithRetry(ctx, l.opts.RetryInterval, func() (Unlocker, error) {
+		return l.TryLock(ctx, key, ttl)
+	})
+}
+
+// TryLock 尝试获取锁，同时清理已过期的锁
+func (l *memoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("锁的过期时间必须大于0: %s", ttl)
+	}
+	cacheKey, err := buildLockKey(KeyPrefixFromContext(ctx, l.keyPrefix), key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[cacheKey]; ok && now.Before(lock.expireAt) {
+		return nil, fmt.Errorf("%w: 缓存键=%s", ErrLockHeld, cacheKey)
+	}
+	for k, lock := range l.locks {
+		if !now.Before(lock.expireAt) {
+			delete(l.locks, k)
+		}
+	}
+	token := lockToken()
+	l.locks[cacheKey] = memoryLock{token: token, expireAt: now.Add(ttl)}
+	return &memoryUnlocker{locker: l, cacheKey: cacheKey, token: token}, nil
+}
+
+// memoryUnlocker 进程内锁的持有者
+type memoryUnlocker struct {
+	locker   *memoryLocker
+	cacheKey string
+	token    string
+}
+
+// Unlock 令牌一致且未过期时释放锁
+func (u *memoryUnlocker) Unlock(_ context.Context) error {
+	l := u.locker
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock, ok := l.locks[u.cacheKey]
+	if !ok || lock.token != u.token {
+		return fmt.Errorf("%w: 缓存键=%s", ErrLockNotHeld, u.cacheKey)
+	}
+	delete(l.locks, u.cacheKey)
+	if !time.Now().Before(lock.expireAt) {
+		return fmt.Errorf("%w: 缓存键=%s", ErrLockNotHeld, u.cacheKey)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Locker 获取进程内的锁
+func (p *memoryProvider) Locker() Locker {
+	return p.locker
+}
+
+// Locker 获取基于Redis的锁，延迟连接时在首次加锁时建立连接
+func (p *redisProvider) Locker() Locker {
+	return &lazyLocker{conn: &p.conn, locker: func() Locker {
+		return NewRedisLocker(p.client, p.keyPrefix, LockOptions{})
+	}}
+}
+
+// Locker 获取基于Redis集群的锁，延迟连接时在首次加锁时建立连接
+func (p *redisClusterProvider) Locker() Locker {
+	return &lazyLocker{conn: &p.conn, locker: func() Locker {
+		return NewRedisLocker(p.client, p.keyPrefix, LockOptions{})
+	}}
+}
+
+// lazyLocker 每次加锁前确保提供者的连接已初始化，客户端在初始化后才可用
+// 提供者关闭后加锁返回ErrClosed
+type lazyLocker struct {
+	conn   *lazyConn
+	locker func() Locker
+}
+
+// Lock 获取锁
+func (l *lazyLocker) Lock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	if _, err := l.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return l.locker().Lock(ctx, key, ttl)
+}
+
+// TryLock 尝试获取锁
+func (l *lazyLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Unlocker, error) {
+	if _, err := l.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return l.locker().TryLock(ctx, key, ttl)
+}
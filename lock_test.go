@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLockerLazyConnect(t *testing.T) {
+	// 端口1不可达，加锁应返回连接错误而不是因客户端未初始化而panic
+	configs := map[string]*Config{
+		"redis": {
+			Type:        RedisCache,
+			LazyConnect: true,
+			Redis:       &RedisConfig{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond},
+		},
+		"cluster": {
+			Type:         RedisClusterCache,
+			LazyConnect:  true,
+			RedisCluster: &RedisClusterConfig{Addrs: []string{"127.0.0.1:1"}, DialTimeout: 100 * time.Millisecond},
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			provider, err := NewProvider(config, &JSONEncoding{}, func() interface{} { return new(string) })
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+			locker := provider.(LockerProvider).Locker()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if _, err := locker.TryLock(ctx, "job", time.Second); err == nil {
+				t.Fatal("TryLock() error = nil, want connection error")
+			}
+
+			if err := provider.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if _, err := locker.TryLock(ctx, "job", time.Second); !errors.Is(err, ErrClosed) {
+				t.Errorf("TryLock() after Close error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+// recordHook 不访问网络，记录命令的参数，所有命令都返回redis.Nil
+type recordHook struct {
+	missHook
+	args *[][]interface{}
+}
+
+func (h recordHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		*h.args = append(*h.args, cmd.Args())
+		cmd.SetErr(redis.Nil)
+		return redis.Nil
+	}
+}
+
+func TestLockKey(t *testing.T) {
+	tests := []struct {
+		prefix, key, want string
+	}{
+		{"app", "job", "app:lock:job"},
+		{"", "job", "lock:job"},
+		{"app", "lock:job", "app:lock:lock:job"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			memory := NewMemoryLocker(tt.prefix, LockOptions{}).(*memoryLocker)
+			if _, err := memory.TryLock(context.Background(), tt.key, time.Second); err != nil {
+				t.Fatalf("memory TryLock() error = %v", err)
+			}
+			if _, ok := memory.locks[tt.want]; !ok || len(memory.locks) != 1 {
+				t.Errorf("memory locks = %v, want key %s", memory.locks, tt.want)
+			}
+
+			var args [][]interface{}
+			client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+			client.AddHook(recordHook{args: &args})
+			defer client.Close()
+			_, _ = NewRedisLocker(client, tt.prefix, LockOptions{}).TryLock(context.Background(), tt.key, time.Second)
+			if len(args) != 1 || len(args[0]) < 2 || args[0][1] != tt.want {
+				t.Errorf("redis commands = %v, want SET %s", args, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewMemoryLocker("app", LockOptions{}).TryLock(context.Background(), "", time.Second); !errors.Is(err, ErrKeyBuild) {
+		t.Errorf("TryLock(\"\") error = %v, want ErrKeyBuild", err)
+	}
+}
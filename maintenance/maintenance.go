@@ -0,0 +1,196 @@
+// Package maintenance 缓存定时维护任务
+// 重设过期时间、一致性检查、容量分析、快照等任务按cron计划注册到Runner，
+// 多个实例同时运行Runner时通过分布式锁选出一个实例执行每一次计划，其他实例跳过
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+var (
+	// ErrStarted Runner启动后不能再注册任务
+	ErrStarted = errors.New("维护任务已启动，不能再注册")
+	// ErrJobPanic 任务panic，panic不会传播到Runner之外，通过OnError和OnRun回调
+	ErrJobPanic = errors.New("维护任务panic")
+)
+
+// Job 维护任务
+type Job func(ctx context.Context) error
+
+// Options 维护任务配置
+type Options struct {
+	// Locker 分布式锁，通常由cache.NewRedisLocker或提供者的Locker方法创建，锁键带有锁的键前缀和lock段
+	// 为空时每个实例都执行所有计划，只适用于单实例部署
+	Locker cache.Locker
+	// LockPrefix 锁键中任务名称前的前缀，默认maintenance:，锁键为LockPrefix任务名称:计划执行时间
+	LockPrefix string
+	// LockTTL 锁的保留时间，默认1分钟
+	// 锁键包含计划执行时间，执行结束后不主动释放，以防时钟偏差较大的实例重复执行同一次计划
+	LockTTL time.Duration
+	// Timeout 每次执行的超时时间，0表示不限制
+	Timeout time.Duration
+	// OnError 任务执行失败或获取锁失败时的回调
+	OnError func(job string, err error)
+	// OnRun 任务在当前实例执行完成后的回调，用于记录日志和指标
+	OnRun func(job string, duration time.Duration, err error)
+}
+
+// setDefaults 设置默认值
+func (o *Options) setDefaults() {
+	if o.LockPrefix == "" {
+		o.LockPrefix = "maintenance:"
+	}
+	if o.LockTTL <= 0 {
+		o.LockTTL = time.Minute
+	}
+}
+
+// entry 已注册的任务
+type entry struct {
+	name     string
+	schedule Schedule
+	job      Job
+}
+
+// Runner 定时维护任务执行器
+type Runner struct {
+	opts    Options
+	mu      sync.Mutex
+	entries []*entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	now     func() time.Time
+}
+
+// New 创建维护任务执行器
+func New(opts Options) *Runner {
+	opts.setDefaults()
+	return &Runner{opts: opts, now: time.Now}
+}
+
+// Register 按执行计划注册任务，spec格式见ParseSchedule，任务名称在锁键中使用，各实例必须一致
+func (r *Runner) Register(name, spec string, job Job) error {
+	if name == "" || job == nil {
+		return errors.New("任务名称和任务不能为空")
+	}
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("任务%s的执行计划错误: %w", name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return ErrStarted
+	}
+	for _, e := range r.entries {
+		if e.name == name {
+			return fmt.Errorf("任务%s已注册", name)
+		}
+	}
+	r.entries = append(r.entries, &entry{name: name, schedule: schedule, job: job})
+	return nil
+}
+
+// Start 在后台启动所有任务，重复调用不做处理
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	for _, e := range r.entries {
+		r.wg.Add(1)
+		go r.loop(ctx, e)
+	}
+}
+
+// Stop 停止所有任务并等待正在执行的任务返回
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	r.wg.Wait()
+}
+
+// loop 按计划执行任务
+func (r *Runner) loop(ctx context.Context, e *entry) {
+	defer r.wg.Done()
+	for {
+		next := e.schedule.Next(r.now())
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		r.run(ctx, e, next)
+	}
+}
+
+// run 获取本次计划的锁并执行任务
+func (r *Runner) run(ctx context.Context, e *entry, scheduled time.Time) {
+	if r.opts.Locker != nil {
+		key := r.opts.LockPrefix + e.name + ":" + strconv.FormatInt(scheduled.Unix(), 10)
+		if _, err := r.opts.Locker.TryLock(ctx, key, r.opts.LockTTL); err != nil {
+			if !errors.Is(err, cache.ErrLockHeld) {
+				r.onError(e.name, fmt.Errorf("获取锁错误: %w", err))
+			}
+			return
+		}
+	}
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := execute(ctx, e.job)
+	if err != nil {
+		r.onError(e.name, err)
+	}
+	if r.opts.OnRun != nil {
+		r.opts.OnRun(e.name, time.Since(start), err)
+	}
+}
+
+// execute 执行任务，任务panic时返回包装ErrJobPanic的错误，不影响其他任务和之后的计划
+func execute(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanic, r)
+		}
+	}()
+	return job(ctx)
+}
+
+// onError 回调错误
+func (r *Runner) onError(job string, err error) {
+	if r.opts.OnError != nil {
+		r.opts.OnError(job, err)
+	}
+}
+
+// ReexpireJob 重设过期时间的任务，reexpirer通常是Redis提供者
+func ReexpireJob(reexpirer cache.Reexpirer, pattern string, newTTL, jitter time.Duration) Job {
+	return func(ctx context.Context) error {
+		_, err := reexpirer.Reexpire(ctx, pattern, newTTL, jitter)
+		return err
+	}
+}
@@ -0,0 +1,125 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// failingLocker 获取锁总是失败的锁
+type failingLocker struct{}
+
+func (failingLocker) Lock(context.Context, string, time.Duration) (cache.Unlocker, error) {
+	return nil, errors.New("后端不可用")
+}
+
+func (failingLocker) TryLock(context.Context, string, time.Duration) (cache.Unlocker, error) {
+	return nil, errors.New("后端不可用")
+}
+
+// recorder 记录回调
+type recorder struct {
+	mu   sync.Mutex
+	errs []error
+	runs int
+}
+
+func (r *recorder) options(locker cache.Locker) Options {
+	return Options{
+		Locker: locker,
+		OnError: func(job string, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.errs = append(r.errs, err)
+		},
+		OnRun: func(job string, duration time.Duration, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.runs++
+		},
+	}
+}
+
+func TestRunnerRun(t *testing.T) {
+	scheduled := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		locker   cache.Locker
+		job      Job
+		runners  int
+		wantRuns int
+		wantErrs int
+		wantErr  error
+	}{
+		{"success", nil, func(context.Context) error { return nil }, 1, 1, 0, nil},
+		{"job error", nil, func(context.Context) error { return context.Canceled }, 1, 1, 1, context.Canceled},
+		{"job panic", nil, func(context.Context) error { panic("boom") }, 1, 1, 1, ErrJobPanic},
+		// 多个实例使用同一个锁时每次计划只执行一次，其他实例跳过且不回调错误
+		{"locked once", cache.NewMemoryLocker("test", cache.LockOptions{}), func(context.Context) error { return nil }, 3, 1, 0, nil},
+		{"lock error", failingLocker{}, func(context.Context) error { return nil }, 1, 0, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			e := &entry{name: "job", job: tt.job}
+			for i := 0; i < tt.runners; i++ {
+				New(rec.options(tt.locker)).run(context.Background(), e, scheduled)
+			}
+			if rec.runs != tt.wantRuns {
+				t.Errorf("执行次数 = %d, want %d", rec.runs, tt.wantRuns)
+			}
+			if len(rec.errs) != tt.wantErrs || (tt.wantErr != nil && !errors.Is(rec.errs[0], tt.wantErr)) {
+				t.Errorf("OnError = %v, want %d errors matching %v", rec.errs, tt.wantErrs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	rec := &recorder{}
+	opts := rec.options(nil)
+	opts.Timeout = 10 * time.Millisecond
+	e := &entry{name: "job", job: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	New(opts).run(context.Background(), e, time.Now())
+	if len(rec.errs) != 1 || !errors.Is(rec.errs[0], context.DeadlineExceeded) {
+		t.Errorf("OnError = %v, want DeadlineExceeded", rec.errs)
+	}
+}
+
+func TestRunnerRegister(t *testing.T) {
+	r := New(Options{})
+	job := func(context.Context) error { return nil }
+	tests := []struct {
+		name    string
+		job     string
+		spec    string
+		fn      Job
+		wantErr bool
+	}{
+		{"ok", "a", "@hourly", job, false},
+		{"duplicate", "a", "@daily", job, true},
+		{"empty name", "", "@hourly", job, true},
+		{"nil job", "b", "@hourly", nil, true},
+		{"bad spec", "c", "* *", job, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Register(tt.job, tt.spec, tt.fn); (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	r.Start()
+	defer r.Stop()
+	if err := r.Register("d", "@hourly", job); !errors.Is(err, ErrStarted) {
+		t.Errorf("启动后 Register() error = %v, want ErrStarted", err)
+	}
+}
@@ -0,0 +1,79 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"*/15 * * * *", "2026-10-17 10:07:00", "2026-10-17 10:15:00"},
+		{"0 * * * *", "2026-10-17 10:00:00", "2026-10-17 11:00:00"},
+		{"30 2 * * *", "2026-10-17 03:00:00", "2026-10-18 02:30:00"},
+		{"0 9-17/4 * * *", "2026-10-17 13:30:00", "2026-10-17 17:00:00"},
+		{"0 0 1,15 * *", "2026-10-17 00:00:00", "2026-11-01 00:00:00"},
+		{"@monthly", "2026-12-15 08:00:00", "2027-01-01 00:00:00"},
+		{"@weekly", "2026-10-17 12:00:00", "2026-10-18 00:00:00"},
+		{"@daily", "2026-10-17 23:59:59", "2026-10-18 00:00:00"},
+		{"@hourly", "2026-10-17 10:59:30", "2026-10-17 11:00:00"},
+		// 日期和星期都不为*时满足其一即可
+		{"0 0 13 * 5", "2026-10-17 00:00:00", "2026-10-23 00:00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"@every 10m", "2026-10-17 10:07:30", "2026-10-17 10:10:00"},
+		{"@every 1h", "2026-10-17 10:00:00", "2026-10-17 11:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.from, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+			if got := s.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+				t.Errorf("Next() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextNever(t *testing.T) {
+	s, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero", got)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 500ms",
+		"@every soon",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) error = nil, want error", spec)
+		}
+	}
+}
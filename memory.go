@@ -3,7 +3,6 @@ package cache
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -106,102 +105,169 @@ func CloseGlobalMemory() error {
 	return nil
 }
 
+// WithAsyncWrites 内存缓存的Set和MultiSet写入后不等待缓冲生效，用于大批量加载
+// ristretto的写入先进入缓冲再异步生效，开启后写入的数据可能在短时间内读取不到
+func WithAsyncWrites() CacheOption {
+	return func(o *cacheOptions) {
+		o.asyncWrites = true
+	}
+}
+
 // ----------------------------------------------------------------------------
 
 type memoryCache struct {
-	client            *ristretto.Cache
-	KeyPrefix         string
-	encoding          Encoding
-	DefaultExpireTime time.Duration
-	newObject         func() interface{}
+	client    MemoryStore
+	KeyPrefix string
+	newObject func() interface{}
+	cacheOptions
 }
 
 // NewMemoryCache 创建内存缓存
-func NewMemoryCache(keyPrefix string, encode Encoding, newObject func() interface{}) Cache {
+func NewMemoryCache(keyPrefix string, encode Encoding, newObject func() interface{}, opts ...CacheOption) Cache {
 	return &memoryCache{
-		client:    GetGlobalMemoryCli(),
-		KeyPrefix: keyPrefix,
-		encoding:  encode,
-		newObject: newObject,
+		client:       GetGlobalMemoryCli(),
+		KeyPrefix:    keyPrefix,
+		cacheOptions: newCacheOptions(&Config{}, encode, opts),
+		newObject:    newObject,
 	}
 }
 
 // Set 设置数据
-func (m *memoryCache) Set(_ context.Context, key string, val interface{}, expiration time.Duration) error {
-	buf, err := Marshal(m.encoding, val)
+func (m *memoryCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	buf, err := m.encode(val)
 	if err != nil {
-		return fmt.Errorf("编码错误: %v, 键=%s, 值=%+v ", err, key, val)
+		return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
 	}
-	if len(buf) == 0 {
-		buf = NotFoundPlaceholderBytes
+	if len(buf) == 0 && m.placeholderEnabled() {
+		buf = m.placeholderBytes()
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 	}
-	cacheKey, err := BuildCacheKey(m.KeyPrefix, key)
+	expiration, err = m.expiration(expiration, key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return err
 	}
 	ok := m.client.SetWithTTL(cacheKey, buf, 0, expiration)
 	if !ok {
-		return errors.New("SetWithTTL失败")
+		return fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
 	}
-	m.client.Wait()
+	if !m.asyncWrites {
+		m.client.Wait()
+	}
+	m.sampleWrite(OpSet, cacheKey, len(buf), expiration)
 
 	return nil
 }
 
 // Get 获取数据
-func (m *memoryCache) Get(_ context.Context, key string, val interface{}) error {
-	cacheKey, err := BuildCacheKey(m.KeyPrefix, key)
+func (m *memoryCache) Get(ctx context.Context, key string, val interface{}) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if BypassFromContext(ctx) {
+		return ErrCacheNotFound
 	}
 
 	data, ok := m.client.Get(cacheKey)
 	if !ok {
-		return CacheNotFound // 未找到，转换为redis nil错误
+		m.sampleRead(OpGet, cacheKey, nil, false)
+		return ErrCacheNotFound // 未找到，转换为redis nil错误
 	}
 
 	dataBytes, ok := data.([]byte)
 	if !ok {
-		return fmt.Errorf("数据类型错误, 键=%s, 类型=%T", key, data)
+		return fmt.Errorf("%w: 数据类型错误, 键=%s, 类型=%T", ErrDecode, key, data)
 	}
+	m.sampleRead(OpGet, cacheKey, dataBytes, true)
 
-	if len(dataBytes) == 0 || bytes.Equal(dataBytes, NotFoundPlaceholderBytes) {
+	if bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
+		return ErrTombstone
+	}
+	if m.isPlaceholder(dataBytes) {
 		return ErrPlaceholder
 	}
 
-	err = Unmarshal(m.encoding, dataBytes, val)
+	needRewrite, err := m.decodeValue(dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("解码错误: %v, 键=%s, 缓存键=%s, 类型=%T, 数据=%s ",
-			err, key, cacheKey, val, dataBytes)
+		if isMiss(err) {
+			return err
+		}
+		return fmt.Errorf("%w: %w, 键=%s, 缓存键=%s, 类型=%T, 数据=%s ",
+			ErrDecode, err, key, cacheKey, val, dataBytes)
+	}
+	if !m.valid(key, val) {
+		m.client.Del(cacheKey)
+		return ErrCacheNotFound
+	}
+	if needRewrite {
+		m.rewrite(cacheKey, val)
+	} else {
+		m.touch(cacheKey, dataBytes)
 	}
 	return nil
 }
 
 // Del 删除数据
-func (m *memoryCache) Del(_ context.Context, keys ...string) error {
+func (m *memoryCache) Del(ctx context.Context, keys ...string) error {
 	if len(keys) == 0 {
 		return nil
 	}
 
-	key := keys[0]
-	cacheKey, err := BuildCacheKey(m.KeyPrefix, key)
-	if err != nil {
-		return fmt.Errorf("构建缓存键错误, 错误=%v, 键=%s", err, key)
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), keys)
+	for _, cacheKey := range cacheKeys {
+		m.client.Del(cacheKey)
 	}
-	m.client.Del(cacheKey)
-	return nil
+	return keyErrs.errOrNil()
 }
 
 // MultiSet 批量设置数据
+// 先编码所有值，任一值编码失败时不写入；之后依次写入并只等待一次缓冲生效，异步写入模式下不等待
+// 被存储拒绝的键不影响其他键的写入，以MultiKeyError返回
 func (m *memoryCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
-	var err error
+	if len(valueMap) == 0 {
+		return nil
+	}
+	keyPrefix := KeyPrefixFromContext(ctx, m.KeyPrefix)
+	keys := make([]string, 0, len(valueMap))
+	cacheKeys := make([]string, 0, len(valueMap))
+	bufs := make([][]byte, 0, len(valueMap))
 	for key, value := range valueMap {
-		err = m.Set(ctx, key, value, expiration)
+		buf, err := m.encode(value)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, value)
+		}
+		if len(buf) == 0 && m.placeholderEnabled() {
+			buf = m.placeholderBytes()
 		}
+		cacheKey, err := BuildCacheKey(keyPrefix, key)
+		if err != nil {
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+		}
+		keys = append(keys, key)
+		cacheKeys = append(cacheKeys, cacheKey)
+		bufs = append(bufs, buf)
 	}
-	return nil
+	expiration, err := m.clamp(expiration, keys...)
+	if err != nil {
+		return err
+	}
+
+	var keyErrs MultiKeyError
+	for i, cacheKey := range cacheKeys {
+		ttl := m.jittered(expiration)
+		if !m.client.SetWithTTL(cacheKey, bufs[i], 0, ttl) {
+			keyErrs = append(keyErrs, &KeyError{Key: keys[i], Err: fmt.Errorf("%w: SetWithTTL失败", ErrBackend)})
+			continue
+		}
+		m.sampleWrite(OpMultiSet, cacheKey, len(bufs[i]), ttl)
+	}
+	if !m.asyncWrites {
+		m.client.Wait()
+	}
+	return keyErrs.errOrNil()
 }
 
 // MultiGet 批量获取数据
@@ -209,9 +275,10 @@ func (m *memoryCache) MultiGet(ctx context.Context, keys []string, value interfa
 	valueMap := reflect.ValueOf(value)
 	var err error
 	for _, key := range keys {
-		object := m.newObject()
+		object := m.acquire(m.newObject)
 		err = m.Get(ctx, key, object)
 		if err != nil {
+			m.release(object)
 			continue
 		}
 		valueMap.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(object))
@@ -221,16 +288,39 @@ func (m *memoryCache) MultiGet(ctx context.Context, keys []string, value interfa
 }
 
 // SetCacheWithNotFound 设置未找到的缓存
-func (m *memoryCache) SetCacheWithNotFound(_ context.Context, key string) error {
-	cacheKey, err := BuildCacheKey(m.KeyPrefix, key)
+func (m *memoryCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if !m.placeholderEnabled() {
+		return nil
 	}
 
-	ok := m.client.SetWithTTL(cacheKey, []byte(NotFoundPlaceholder), 0, DefaultNotFoundExpireTime)
+	expiration := explicitNotFoundTTL(ttl)
+	if expiration == 0 {
+		expiration = m.memoryNotFoundExpiration(cacheKey)
+	}
+	ok := m.client.SetWithTTL(cacheKey, m.placeholderBytes(), 0, expiration)
 	if !ok {
-		return errors.New("SetWithTTL失败")
+		return fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
 	}
 
 	return nil
 }
+
+// rewrite 使用主编码重写条目并保留剩余过期时间，失败时忽略
+func (m *memoryCache) rewrite(cacheKey string, val interface{}) {
+	buf, err := m.encode(val)
+	if err != nil || len(buf) == 0 {
+		return
+	}
+	ttl, ok := m.client.GetTTL(cacheKey)
+	if !ok {
+		return
+	}
+	if m.slidingTTL > 0 {
+		ttl = m.slidingTTL
+	}
+	m.client.SetWithTTL(cacheKey, buf, 0, ttl)
+}
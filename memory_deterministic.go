@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryEngine 内存缓存引擎
+type MemoryEngine string
+
+const (
+	// MemoryEngineRistretto 基于ristretto，高并发下性能好，但写入按概率准入，过期时间精度有限，为默认引擎
+	MemoryEngineRistretto MemoryEngine = "ristretto"
+	// MemoryEngineDeterministic 基于map和过期堆，写入总是生效，过期时间精确，超出容量时按LRU淘汰
+	// 适用于正确性测试和冷启动的小容量缓存
+	MemoryEngineDeterministic MemoryEngine = "deterministic"
+)
+
+// detEntry 确定性存储的条目
+type detEntry struct {
+	key      interface{}
+	value    interface{}
+	cost     int64
+	expireAt time.Time
+	// index 在过期堆中的位置，-1表示不在堆中(永不过期)
+	index int
+	elem  *list.Element
+}
+
+// expiryHeap 按过期时间排序的小顶堆
+type expiryHeap []*detEntry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expireAt.Before(h[j].expireAt) }
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*detEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// deterministicStore 确定性内存存储，实现MemoryStore
+type deterministicStore struct {
+	mu      sync.Mutex
+	items   map[interface{}]*detEntry
+	expiry  expiryHeap
+	lru     *list.List
+	maxCost int64
+	cost    int64
+	now     func() time.Time
+}
+
+var _ MemoryStore = (*deterministicStore)(nil)
+
+// newDeterministicStore 创建确定性内存存储，maxCost为0表示不限制容量
+func newDeterministicStore(maxCost int64) *deterministicStore {
+	return &deterministicStore{
+		items:   make(map[interface{}]*detEntry),
+		lru:     list.New(),
+		maxCost: maxCost,
+		now:     time.Now,
+	}
+}
+
+// entryCost 计算条目成本，未指定时按字节数计算
+func entryCost(value interface{}, cost int64) int64 {
+	if cost > 0 {
+		return cost
+	}
+	if b, ok := value.([]byte); ok {
+		return int64(len(b))
+	}
+	return 1
+}
+
+// removeExpired 清理所有已过期的条目
+func (s *deterministicStore) removeExpired() {
+	now := s.now()
+	for len(s.expiry) > 0 && !now.Before(s.expiry[0].expireAt) {
+		s.remove(s.expiry[0])
+	}
+}
+
+// remove 删除条目
+func (s *deterministicStore) remove(e *detEntry) {
+	if e.index >= 0 {
+		heap.Remove(&s.expiry, e.index)
+	}
+	s.lru.Remove(e.elem)
+	delete(s.items, e.key)
+	s.cost -= e.cost
+}
+
+// Get 获取值
+func (s *deterministicStore) Get(key interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(e.elem)
+	return e.value, true
+}
+
+// SetWithTTL 设置值和过期时间，单个条目超过容量时拒绝写入
+func (s *deterministicStore) SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool {
+	if ttl < 0 {
+		return false
+	}
+	cost = entryCost(value, cost)
+	if s.maxCost > 0 && cost > s.maxCost {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	if old, ok := s.items[key]; ok {
+		s.remove(old)
+	}
+	for s.maxCost > 0 && s.cost+cost > s.maxCost {
+		s.remove(s.lru.Back().Value.(*detEntry))
+	}
+
+	e := &detEntry{key: key, value: value, cost: cost, index: -1}
+	if ttl > 0 {
+		e.expireAt = s.now().Add(ttl)
+		heap.Push(&s.expiry, e)
+	}
+	e.elem = s.lru.PushFront(e)
+	s.items[key] = e
+	s.cost += cost
+	return true
+}
+
+// GetTTL 获取剩余过期时间，永不过期时返回0
+func (s *deterministicStore) GetTTL(key interface{}) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	e, ok := s.items[key]
+	if !ok {
+		return 0, false
+	}
+	if e.expireAt.IsZero() {
+		return 0, true
+	}
+	return e.expireAt.Sub(s.now()), true
+}
+
+// Del 删除值
+func (s *deterministicStore) Del(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.remove(e)
+	}
+}
+
+// Wait 写入同步生效，无需等待
+func (s *deterministicStore) Wait() {}
+
+// Close 清空存储
+func (s *deterministicStore) Close() {
+	s.Clear()
+}
+
+// Clear 清空存储
+func (s *deterministicStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[interface{}]*detEntry)
+	s.expiry = nil
+	s.lru.Init()
+	s.cost = 0
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryEngineLRU 轻量LRU，按条目数量限制容量，适用于只有几百个条目的小缓存
+const MemoryEngineLRU MemoryEngine = "lru"
+
+// defaultLRUCapacity LRU默认容量
+const defaultLRUCapacity = 1000
+
+// lruEntry LRU条目
+type lruEntry struct {
+	key      interface{}
+	value    interface{}
+	expireAt time.Time
+}
+
+// lruStore 轻量LRU存储，实现MemoryStore
+// 过期条目在访问时惰性删除，容量满时淘汰最久未使用的条目
+type lruStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[interface{}]*list.Element
+	ll       *list.List
+}
+
+var _ MemoryStore = (*lruStore)(nil)
+
+// newLRUStore 创建LRU存储，capacity不大于0时使用默认容量
+func newLRUStore(capacity int) *lruStore {
+	if capacity <= 0 {
+		capacity = defaultLRUCapacity
+	}
+	return &lruStore{
+		capacity: capacity,
+		items:    make(map[interface{}]*list.Element, capacity),
+		ll:       list.New(),
+	}
+}
+
+// lookup 获取未过期的条目
+func (s *lruStore) lookup(key interface{}) (*lruEntry, bool) {
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*lruEntry)
+	if !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		s.ll.Remove(elem)
+		delete(s.items, key)
+		return nil, false
+	}
+	return e, true
+}
+
+// Get 获取值
+func (s *lruStore) Get(key interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	s.ll.MoveToFront(s.items[key])
+	return e.value, true
+}
+
+// SetWithTTL 设置值和过期时间，cost被忽略
+func (s *lruStore) SetWithTTL(key, value interface{}, _ int64, ttl time.Duration) bool {
+	if ttl < 0 {
+		return false
+	}
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[key]; ok {
+		e := elem.Value.(*lruEntry)
+		e.value = value
+		e.expireAt = expireAt
+		s.ll.MoveToFront(elem)
+		return true
+	}
+	if s.ll.Len() >= s.capacity {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*lruEntry).key)
+	}
+	s.items[key] = s.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	return true
+}
+
+// GetTTL 获取剩余过期时间，永不过期时返回0
+func (s *lruStore) GetTTL(key interface{}) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	if e.expireAt.IsZero() {
+		return 0, true
+	}
+	return time.Until(e.expireAt), true
+}
+
+// Del 删除值
+func (s *lruStore) Del(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[key]; ok {
+		s.ll.Remove(elem)
+		delete(s.items, key)
+	}
+}
+
+// Wait 写入同步生效，无需等待
+func (s *lruStore) Wait() {}
+
+// Close 清空存储
+func (s *lruStore) Close() {
+	s.Clear()
+}
+
+// Clear 清空存储
+func (s *lruStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[interface{}]*list.Element)
+	s.ll.Init()
+}
+
+// NewLRUStore 创建轻量LRU存储，可用于NewMemoryCacheWithStore或其他后端的本地存储
+func NewLRUStore(capacity int) MemoryStore {
+	return newLRUStore(capacity)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"time"
+)
+
+const (
+	// defaultSecondChanceSize 二次机会缓存默认容量
+	defaultSecondChanceSize = 1024
+	// defaultSecondChanceWindow 二次机会缓存默认保留时间
+	defaultSecondChanceWindow = time.Second
+)
+
+// SecondChanceConfig 二次机会缓存配置
+// ristretto按概率准入写入，Set成功返回后条目仍可能被拒绝，紧接着的Get意外未命中；
+// 开启后最近写入的条目在一个小的LRU中额外保留一段时间，保证请求内先写后读总能命中
+type SecondChanceConfig struct {
+	// Size 保留的最大条目数量，默认1024
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// Window 条目的保留时间，默认1秒，不超过条目本身的过期时间
+	Window time.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// overlayEntry 二次机会缓存中的条目，记录条目本身的过期时间
+type overlayEntry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+// secondChanceStore 在底层存储之上保留最近写入的条目
+type secondChanceStore struct {
+	MemoryStore
+	overlay *lruStore
+	window  time.Duration
+}
+
+// NewSecondChanceStore 为底层存储添加二次机会缓存，config为空时使用默认配置
+// 读取先查最近写入的条目，再查底层存储；写入被底层存储的缓冲丢弃时仍返回true
+func NewSecondChanceStore(store MemoryStore, config *SecondChanceConfig) MemoryStore {
+	var cfg SecondChanceConfig
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = defaultSecondChanceSize
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSecondChanceWindow
+	}
+	return &secondChanceStore{
+		MemoryStore: store,
+		overlay:     newLRUStore(cfg.Size),
+		window:      cfg.Window,
+	}
+}
+
+// Get 获取值，最近写入的条目优先
+func (s *secondChanceStore) Get(key interface{}) (interface{}, bool) {
+	if v, ok := s.overlay.Get(key); ok {
+		return v.(overlayEntry).value, true
+	}
+	return s.MemoryStore.Get(key)
+}
+
+// SetWithTTL 写入底层存储，同时在二次机会缓存中保留不超过window的时间
+func (s *secondChanceStore) SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool {
+	if ttl < 0 {
+		return false
+	}
+	s.MemoryStore.SetWithTTL(key, value, cost, ttl)
+	window := s.window
+	var expireAt time.Time
+	if ttl > 0 {
+		window = min(window, ttl)
+		expireAt = time.Now().Add(ttl)
+	}
+	return s.overlay.SetWithTTL(key, overlayEntry{value: value, expireAt: expireAt}, 0, window)
+}
+
+// GetTTL 获取剩余过期时间，底层存储拒绝了写入时使用二次机会缓存中记录的过期时间
+func (s *secondChanceStore) GetTTL(key interface{}) (time.Duration, bool) {
+	if ttl, ok := s.MemoryStore.GetTTL(key); ok {
+		return ttl, true
+	}
+	v, ok := s.overlay.Get(key)
+	if !ok {
+		return 0, false
+	}
+	e := v.(overlayEntry)
+	if e.expireAt.IsZero() {
+		return 0, true
+	}
+	return time.Until(e.expireAt), true
+}
+
+// Del 删除两处的值
+func (s *secondChanceStore) Del(key interface{}) {
+	s.overlay.Del(key)
+	s.MemoryStore.Del(key)
+}
+
+// Close 关闭底层存储并清空二次机会缓存
+func (s *secondChanceStore) Close() {
+	s.overlay.Close()
+	s.MemoryStore.Close()
+}
+
+// Clear 清空二次机会缓存，底层存储实现MemoryClearer时一并清空
+func (s *secondChanceStore) Clear() {
+	s.overlay.Clear()
+	if clearer, ok := s.MemoryStore.(MemoryClearer); ok {
+		clearer.Clear()
+	}
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memoryPartition 命名空间对应的分区
+type memoryPartition struct {
+	// prefix 命名空间的缓存键前缀，包含分隔符
+	prefix string
+	store  MemoryStore
+}
+
+// partitionedStore 按命名空间划分的内存存储，每个命名空间使用独立的底层存储和容量预算
+// 准入和淘汰只在分区内进行，一个命名空间的突发写入不会淘汰其他命名空间的条目
+type partitionedStore struct {
+	// partitions 按前缀长度降序排列，键匹配最长的命名空间
+	partitions []memoryPartition
+	// fallback 未列出的命名空间共享的分区
+	fallback MemoryStore
+}
+
+// listingPartitionedStore 底层引擎可以遍历键时的分区存储，实现MemoryKeyLister
+type listingPartitionedStore struct {
+	*partitionedStore
+}
+
+// newPartitionedStore 根据MemoryConfig.NamespaceWeights创建分区存储
+// 每个分区的MaxCost、NumCounters和Capacity按权重占比分配，键为""的权重用于未列出的命名空间，默认为1
+func newPartitionedStore(memConfig *MemoryConfig, readYourWrites bool) (MemoryStore, error) {
+	weights := make(map[string]int64, len(memConfig.NamespaceWeights)+1)
+	var total int64
+	for namespace, weight := range memConfig.NamespaceWeights {
+		if weight <= 0 {
+			return nil, fmt.Errorf("命名空间 %s 的权重必须大于0: %d", namespace, weight)
+		}
+		weights[namespace] = weight
+		total += weight
+	}
+	if _, ok := weights[""]; !ok {
+		weights[""] = 1
+		total++
+	}
+
+	s := &partitionedStore{}
+	for namespace, weight := range weights {
+		store, err := newMemoryStore(memConfig.share(weight, total), readYourWrites)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if namespace == "" {
+			s.fallback = store
+			continue
+		}
+		s.partitions = append(s.partitions, memoryPartition{prefix: namespace + ":", store: store})
+	}
+	sort.Slice(s.partitions, func(i, j int) bool {
+		return len(s.partitions[i].prefix) > len(s.partitions[j].prefix)
+	})
+
+	if _, ok := s.fallback.(MemoryKeyLister); ok {
+		return &listingPartitionedStore{s}, nil
+	}
+	return s, nil
+}
+
+// share 按权重占比分配容量后的单个分区配置
+func (c *MemoryConfig) share(weight, total int64) *MemoryConfig {
+	scale := func(n int64) int64 {
+		if n <= 0 {
+			return n
+		}
+		return max(n*weight/total, 1)
+	}
+	cfg := *c
+	if cfg.Engine == MemoryEngineLRU && cfg.Capacity <= 0 {
+		cfg.Capacity = defaultLRUCapacity
+	}
+	cfg.NamespaceWeights = nil
+	cfg.MaxCost = scale(cfg.MaxCost)
+	cfg.NumCounters = scale(cfg.NumCounters)
+	cfg.Capacity = int(scale(int64(cfg.Capacity)))
+	return &cfg
+}
+
+// route 获取键所属的分区
+func (s *partitionedStore) route(key interface{}) MemoryStore {
+	if k, ok := key.(string); ok {
+		for _, p := range s.partitions {
+			if strings.HasPrefix(k, p.prefix) {
+				return p.store
+			}
+		}
+	}
+	return s.fallback
+}
+
+// each 依次访问所有分区
+func (s *partitionedStore) each(fn func(store MemoryStore)) {
+	for _, p := range s.partitions {
+		fn(p.store)
+	}
+	if s.fallback != nil {
+		fn(s.fallback)
+	}
+}
+
+// Get 获取值
+func (s *partitionedStore) Get(key interface{}) (interface{}, bool) {
+	return s.route(key).Get(key)
+}
+
+// SetWithTTL 写入键所属的分区，超出分区预算时只淘汰该分区的条目
+func (s *partitionedStore) SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool {
+	return s.route(key).SetWithTTL(key, value, cost, ttl)
+}
+
+// GetTTL 获取剩余过期时间
+func (s *partitionedStore) GetTTL(key interface{}) (time.Duration, bool) {
+	return s.route(key).GetTTL(key)
+}
+
+// Del 删除值
+func (s *partitionedStore) Del(key interface{}) {
+	s.route(key).Del(key)
+}
+
+// Wait 等待所有分区缓冲中的写入生效
+func (s *partitionedStore) Wait() {
+	s.each(MemoryStore.Wait)
+}
+
+// Close 关闭所有分区
+func (s *partitionedStore) Close() {
+	s.each(MemoryStore.Close)
+}
+
+// Clear 清空所有实现了MemoryClearer的分区
+func (s *partitionedStore) Clear() {
+	s.each(func(store MemoryStore) {
+		if clearer, ok := store.(MemoryClearer); ok {
+			clearer.Clear()
+		}
+	})
+}
+
+// Keys 返回所有分区当前键的快照
+func (s *listingPartitionedStore) Keys() []interface{} {
+	var keys []interface{}
+	s.each(func(store MemoryStore) {
+		if lister, ok := store.(MemoryKeyLister); ok {
+			keys = append(keys, lister.Keys()...)
+		}
+	})
+	return keys
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// MemoryStore 内存缓存的底层存储，方法签名与ristretto.Cache一致
+// 默认使用ristretto，测试中可以替换为可控制淘汰行为的实现
+type MemoryStore interface {
+	// Get 获取值
+	Get(key interface{}) (interface{}, bool)
+	// SetWithTTL 设置值和过期时间，ttl为0表示永不过期，写入被拒绝时返回false
+	SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool
+	// GetTTL 获取剩余过期时间，永不过期时返回0
+	GetTTL(key interface{}) (time.Duration, bool)
+	// Del 删除值
+	Del(key interface{})
+	// Wait 等待缓冲中的写入生效
+	Wait()
+	// Close 关闭存储
+	Close()
+}
+
+var _ MemoryStore = (*ristretto.Cache)(nil)
+
+// MemoryClearer 可以清空的内存存储，内置的所有引擎都实现了该接口
+type MemoryClearer interface {
+	// Clear 清空所有条目，清空后存储仍可使用
+	Clear()
+}
+
+var _ MemoryClearer = (*ristretto.Cache)(nil)
+
+// NewMemoryCacheWithStore 使用指定的底层存储创建内存缓存
+func NewMemoryCacheWithStore(store MemoryStore, keyPrefix string, encode Encoding, newObject func() interface{}, opts ...CacheOption) Cache {
+	return &memoryCache{
+		client:       store,
+		KeyPrefix:    keyPrefix,
+		cacheOptions: newCacheOptions(&Config{}, encode, opts),
+		newObject:    newObject,
+	}
+}
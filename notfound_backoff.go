@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// notFoundCounterSuffix 未找到计数键的后缀，计数键与占位符键相邻存放
+const notFoundCounterSuffix = ":__not_found_count"
+
+// notFoundCounterScript 自增未找到计数并刷新计数的过期时间
+var notFoundCounterScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return n
+`)
+
+// WithNotFoundBackoff 启用未找到占位符的指数退避，同一个键连续未找到时占位符过期时间逐次翻倍直到max
+// 用于减少对不存在ID的持续查询给数据库带来的压力
+func WithNotFoundBackoff(max time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.notFoundMax = max
+	}
+}
+
+// notFoundBackoffEnabled 是否启用未找到占位符的指数退避
+func (p *ttlPolicy) notFoundBackoffEnabled() bool {
+	return p.notFoundMax > p.notFoundExpiration()
+}
+
+// notFoundCounterWindow 未找到计数的保留时间
+// 在最后一次写入占位符后的这段时间内没有再次未找到时计数自动清零，取最大过期时间的两倍
+func (p *ttlPolicy) notFoundCounterWindow() time.Duration {
+	return 2 * p.notFoundMax
+}
+
+// notFoundBackoff 第n次连续未找到时占位符的过期时间
+func (p *ttlPolicy) notFoundBackoff(n int64) time.Duration {
+	ttl := p.notFoundExpiration()
+	for i := int64(1); i < n && ttl < p.notFoundMax; i++ {
+		ttl *= 2
+	}
+	return min(ttl, p.notFoundMax)
+}
+
+// notFoundCounterKey 未找到计数键
+func notFoundCounterKey(cacheKey string) string {
+	return cacheKey + notFoundCounterSuffix
+}
+
+// redisNotFoundExpiration 自增Redis中的未找到计数并计算占位符过期时间
+// 计数失败时使用未退避的过期时间，不影响占位符写入
+func (o *cacheOptions) redisNotFoundExpiration(ctx context.Context, client redis.Scripter, cacheKey string) time.Duration {
+	if !o.notFoundBackoffEnabled() {
+		return o.notFoundExpiration()
+	}
+	n, err := redisInt64(notFoundCounterScript.Run(ctx, client, []string{notFoundCounterKey(cacheKey)}, o.notFoundCounterWindow().Milliseconds()))
+	if err != nil {
+		return o.notFoundExpiration()
+	}
+	return o.notFoundBackoff(n)
+}
+
+// memoryNotFoundExpiration 自增内存中的未找到计数并计算占位符过期时间
+func (m *memoryCache) memoryNotFoundExpiration(cacheKey string) time.Duration {
+	if !m.notFoundBackoffEnabled() {
+		return m.notFoundExpiration()
+	}
+	counterKey := notFoundCounterKey(cacheKey)
+
+	memoryIncrMu.Lock()
+	defer memoryIncrMu.Unlock()
+	var n int64 = 1
+	if data, ok := m.client.Get(counterKey); ok {
+		dataBytes, _ := data.([]byte)
+		if current, err := strconv.ParseInt(string(dataBytes), 10, 64); err == nil {
+			n = current + 1
+		}
+	}
+	m.client.SetWithTTL(counterKey, []byte(strconv.FormatInt(n, 10)), 0, m.notFoundCounterWindow())
+	m.client.Wait()
+	return m.notFoundBackoff(n)
+}
+
+// storeNotFoundExpiration 通过存储的原子自增计算占位符过期时间
+// 存储的自增只在计数新建时设置过期时间，计数从第一次未找到起保留notFoundCounterWindow
+// 存储不支持自增或自增失败时使用未退避的过期时间
+func (c *storeCache) storeNotFoundExpiration(ctx context.Context, cacheKey string) time.Duration {
+	if !c.notFoundBackoffEnabled() {
+		return c.notFoundExpiration()
+	}
+	incr, ok := c.store.(StoreIncrementer)
+	if !ok {
+		return c.notFoundExpiration()
+	}
+	n, err := incr.IncrWithTTL(ctx, notFoundCounterKey(cacheKey), 1, c.notFoundCounterWindow())
+	if err != nil {
+		return c.notFoundExpiration()
+	}
+	return c.notFoundBackoff(n)
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (m *memoryCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), keys)
+	if !m.placeholderEnabled() {
+		return keyErrs.errOrNil()
+	}
+	for _, cacheKey := range cacheKeys {
+		if !m.client.SetWithTTL(cacheKey, m.placeholderBytes(), 0, m.memoryNotFoundExpiration(cacheKey)) {
+			keyErrs = append(keyErrs, &KeyError{Key: cacheKey, Err: fmt.Errorf("%w: SetWithTTL失败", ErrBackend)})
+		}
+	}
+	return keyErrs.errOrNil()
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存，通过管道一次往返写入
+func (c *redisCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	return c.redisMultiSetNotFound(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存，通过管道按节点写入
+func (c *redisClusterCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	return c.redisMultiSetNotFound(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+}
+
+// redisMultiSetNotFound 批量写入未找到占位符，无效的键被跳过并以MultiKeyError返回
+// 启用指数退避时先通过一个管道自增所有键的未找到计数，再通过第二个管道写入占位符；不使用管道时逐个写入
+func (o *cacheOptions) redisMultiSetNotFound(ctx context.Context, client redis.Cmdable, keyPrefix string, keys []string) error {
+	cacheKeys, keyErrs := buildCacheKeys(keyPrefix, keys)
+	if len(cacheKeys) == 0 || !o.placeholderEnabled() {
+		return keyErrs.errOrNil()
+	}
+
+	ttls := make([]time.Duration, len(cacheKeys))
+	if !o.pipelining {
+		for i, cacheKey := range cacheKeys {
+			ttls[i] = o.redisNotFoundExpiration(ctx, client, cacheKey)
+		}
+		err := o.redisSetEachNotFound(ctx, client, cacheKeys, ttls)
+		return errors.Join(err, keyErrs.errOrNil())
+	}
+
+	for i := range ttls {
+		ttls[i] = o.notFoundExpiration()
+	}
+	if o.notFoundBackoffEnabled() {
+		window := o.notFoundCounterWindow().Milliseconds()
+		cmds := make([]*redis.Cmd, len(cacheKeys))
+		// 计数失败时使用未退避的过期时间，不影响占位符写入
+		_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, cacheKey := range cacheKeys {
+				cmds[i] = notFoundCounterScript.Eval(ctx, pipe, []string{notFoundCounterKey(cacheKey)}, window)
+			}
+			return nil
+		})
+		for i, cmd := range cmds {
+			if n, err := redisInt64(cmd); err == nil {
+				ttls[i] = o.notFoundBackoff(n)
+			}
+		}
+	}
+
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, cacheKey := range cacheKeys {
+			pipe.Set(ctx, cacheKey, o.placeholderBytes(), ttls[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Join(fmt.Errorf("%w: 管道批量设置错误: %w", ErrBackend, err), keyErrs.errOrNil())
+	}
+	return keyErrs.errOrNil()
+}
+
+// redisSetEachNotFound 逐个写入未找到占位符
+func (o *cacheOptions) redisSetEachNotFound(ctx context.Context, client redis.Cmdable, cacheKeys []string, ttls []time.Duration) error {
+	for i, cacheKey := range cacheKeys {
+		if err := client.Set(ctx, cacheKey, o.placeholderBytes(), ttls[i]).Err(); err != nil {
+			return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+		}
+	}
+	return nil
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+// 存储实现StoreMultiSetter且未启用指数退避时一次批量写入，否则逐个写入
+func (c *storeCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	if len(cacheKeys) == 0 || !c.placeholderEnabled() {
+		return keyErrs.errOrNil()
+	}
+	if setter, ok := c.store.(StoreMultiSetter); ok && !c.notFoundBackoffEnabled() {
+		values := make(map[string][]byte, len(cacheKeys))
+		for _, cacheKey := range cacheKeys {
+			values[cacheKey] = c.placeholderBytes()
+		}
+		if err := setter.MultiSet(ctx, values, c.notFoundExpiration()); err != nil {
+			return errors.Join(fmt.Errorf("%w: 存储批量设置错误: %w", ErrBackend, err), keyErrs.errOrNil())
+		}
+		return keyErrs.errOrNil()
+	}
+	for _, cacheKey := range cacheKeys {
+		if err := c.store.Set(ctx, cacheKey, c.placeholderBytes(), c.storeNotFoundExpiration(ctx, cacheKey)); err != nil {
+			return errors.Join(fmt.Errorf("%w: 存储设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey), keyErrs.errOrNil())
+		}
+	}
+	return keyErrs.errOrNil()
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// otelStatsCollector 基于OpenTelemetry Metrics API的统计收集器
+type otelStatsCollector struct {
+	hits    metric.Int64Counter
+	misses  metric.Int64Counter
+	errors  metric.Int64Counter
+	latency metric.Float64Histogram
+}
+
+// NewOTelStatsCollector 创建OpenTelemetry统计收集器
+// 指标通过meter所属的MeterProvider导出(如OTLP)，无需Prometheus抓取
+func NewOTelStatsCollector(meter metric.Meter) (StatsCollector, error) {
+	hits, err := meter.Int64Counter("cache.hits",
+		metric.WithDescription("缓存命中次数"))
+	if err != nil {
+		return nil, fmt.Errorf("创建命中计数器失败: %w", err)
+	}
+	misses, err := meter.Int64Counter("cache.misses",
+		metric.WithDescription("缓存未命中次数"))
+	if err != nil {
+		return nil, fmt.Errorf("创建未命中计数器失败: %w", err)
+	}
+	errs, err := meter.Int64Counter("cache.errors",
+		metric.WithDescription("缓存操作错误次数"))
+	if err != nil {
+		return nil, fmt.Errorf("创建错误计数器失败: %w", err)
+	}
+	latency, err := meter.Float64Histogram("cache.operation.duration",
+		metric.WithDescription("缓存操作耗时"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("创建耗时直方图失败: %w", err)
+	}
+
+	return &otelStatsCollector{
+		hits:    hits,
+		misses:  misses,
+		errors:  errs,
+		latency: latency,
+	}, nil
+}
+
+// attrs 生成指标属性
+func (o *otelStatsCollector) attrs(backend CacheType, op string) metric.MeasurementOption {
+	return metric.WithAttributes(
+		attribute.String("cache.backend", string(backend)),
+		attribute.String("cache.operation", op),
+	)
+}
+
+// IncrHit 命中计数加一
+func (o *otelStatsCollector) IncrHit(backend CacheType, op string) {
+	o.hits.Add(context.Background(), 1, o.attrs(backend, op))
+}
+
+// IncrMiss 未命中计数加一
+func (o *otelStatsCollector) IncrMiss(backend CacheType, op string) {
+	o.misses.Add(context.Background(), 1, o.attrs(backend, op))
+}
+
+// ObserveLatency 记录操作耗时
+func (o *otelStatsCollector) ObserveLatency(backend CacheType, op string, d time.Duration) {
+	o.latency.Record(context.Background(), d.Seconds(), o.attrs(backend, op))
+}
+
+// IncrError 错误计数加一
+func (o *otelStatsCollector) IncrError(backend CacheType, op string) {
+	o.errors.Add(context.Background(), 1, o.attrs(backend, op))
+}
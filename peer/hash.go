@@ -0,0 +1,44 @@
+package peer
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// ring 一致性哈希环
+type ring struct {
+	replicas int
+	hashes   []uint32
+	nodes    map[uint32]string
+}
+
+// newRing 创建一致性哈希环，每个节点在环上有replicas个虚拟节点
+func newRing(replicas int, nodes ...string) *ring {
+	r := &ring{
+		replicas: replicas,
+		nodes:    make(map[uint32]string, replicas*len(nodes)),
+	}
+	for _, node := range nodes {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + node))
+			r.hashes = append(r.hashes, h)
+			r.nodes[h] = node
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// get 获取键所属的节点，环为空时返回空字符串
+func (r *ring) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	idx := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return r.nodes[r.hashes[idx]]
+}
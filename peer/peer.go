@@ -0,0 +1,286 @@
+// Package peer 点对点分布式缓存后端(groupcache风格)
+// 一组应用实例组成一致性哈希组，每个键只由一个实例持有，其他实例通过HTTP向持有者读取，
+// 持有者未命中时调用加载函数填充，无需Redis即可横向扩展只读为主的数据缓存
+package peer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/smart-unicom/cache"
+)
+
+const (
+	// defaultBasePath 默认的HTTP路径前缀
+	defaultBasePath = "/_cache/"
+	// defaultReplicas 默认的虚拟节点数量
+	defaultReplicas = 50
+	// ttlHeader 写入请求中携带过期时间(毫秒)的请求头
+	ttlHeader = "X-Cache-TTL"
+)
+
+// Getter 加载函数，持有者未命中时调用，key为包含前缀的完整缓存键，返回ErrCacheNotFound表示数据不存在
+// 返回的数据必须是缓存编码后的字节
+type Getter func(ctx context.Context, key string) ([]byte, error)
+
+// Options 点对点组配置
+type Options struct {
+	// Self 当前实例的地址，如http://10.0.0.1:8080，必须与SetPeers中的地址一致
+	Self string
+	// BasePath HTTP路径前缀，默认/_cache/
+	BasePath string
+	// Replicas 每个实例的虚拟节点数量，默认50
+	Replicas int
+	// Capacity 本地存储的最大条目数量，默认1000
+	Capacity int
+	// Getter 加载函数，为空时未命中直接返回ErrCacheNotFound
+	Getter Getter
+	// LoadTTL 加载函数填充的数据在本地的过期时间，0表示永不过期
+	LoadTTL time.Duration
+	// Client 访问其他实例的HTTP客户端，默认超时3秒
+	Client *http.Client
+}
+
+// Group 点对点缓存组，实现cache.Store和http.Handler
+// 需要将Group注册到HTTP服务的BasePath路径下，供其他实例访问
+type Group struct {
+	opts   Options
+	local  cache.MemoryStore
+	flight flightGroup
+	mu     sync.RWMutex
+	ring   *ring
+}
+
+var (
+	_ cache.Store  = (*Group)(nil)
+	_ http.Handler = (*Group)(nil)
+)
+
+// NewGroup 创建点对点缓存组
+func NewGroup(opts Options) (*Group, error) {
+	if opts.Self == "" {
+		return nil, errors.New("当前实例地址不能为空")
+	}
+	if opts.BasePath == "" {
+		opts.BasePath = defaultBasePath
+	}
+	if !strings.HasSuffix(opts.BasePath, "/") {
+		opts.BasePath += "/"
+	}
+	if opts.Replicas <= 0 {
+		opts.Replicas = defaultReplicas
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 3 * time.Second}
+	}
+	opts.Self = strings.TrimSuffix(opts.Self, "/")
+	g := &Group{
+		opts:  opts,
+		local: cache.NewLRUStore(opts.Capacity),
+	}
+	g.SetPeers(opts.Self)
+	return g, nil
+}
+
+// NewCache 基于点对点组创建缓存
+func NewCache(g *Group, keyPrefix string, encode cache.Encoding, newObject func() interface{}, opts ...cache.CacheOption) cache.Cache {
+	return cache.NewStoreCache(g, keyPrefix, encode, newObject, opts...)
+}
+
+// SetPeers 设置组内所有实例的地址(包括当前实例)，成员变化时重新调用
+func (g *Group) SetPeers(peers ...string) {
+	nodes := make([]string, 0, len(peers))
+	for _, p := range peers {
+		nodes = append(nodes, strings.TrimSuffix(p, "/"))
+	}
+	r := newRing(g.opts.Replicas, nodes...)
+	g.mu.Lock()
+	g.ring = r
+	g.mu.Unlock()
+}
+
+// owner 获取键的持有者，返回空字符串表示由当前实例持有
+func (g *Group) owner(key string) string {
+	g.mu.RLock()
+	node := g.ring.get(key)
+	g.mu.RUnlock()
+	if node == "" || node == g.opts.Self {
+		return ""
+	}
+	return node
+}
+
+// Get 获取值，键不属于当前实例时向持有者读取
+func (g *Group) Get(ctx context.Context, key string) ([]byte, error) {
+	if node := g.owner(key); node != "" {
+		return g.remoteGet(ctx, node, key)
+	}
+	return g.localGet(ctx, key)
+}
+
+// Set 设置值，键不属于当前实例时写入持有者
+func (g *Group) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if node := g.owner(key); node != "" {
+		return g.remoteSet(ctx, node, key, value, ttl)
+	}
+	g.localSet(key, value, ttl)
+	return nil
+}
+
+// Del 删除值，按持有者分别删除
+func (g *Group) Del(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if node := g.owner(key); node != "" {
+			if err := g.remoteDel(ctx, node, key); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		g.local.Del(key)
+	}
+	return errors.Join(errs...)
+}
+
+// localGet 读取本地存储，未命中时调用加载函数，同一个键的并发加载会被合并
+func (g *Group) localGet(ctx context.Context, key string) ([]byte, error) {
+	if v, ok := g.local.Get(key); ok {
+		return v.([]byte), nil
+	}
+	if g.opts.Getter == nil {
+		return nil, cache.ErrCacheNotFound
+	}
+	return g.flight.do(key, func() ([]byte, error) {
+		if v, ok := g.local.Get(key); ok {
+			return v.([]byte), nil
+		}
+		value, err := g.opts.Getter(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		g.localSet(key, value, g.opts.LoadTTL)
+		return value, nil
+	})
+}
+
+// localSet 写入本地存储
+func (g *Group) localSet(key string, value []byte, ttl time.Duration) {
+	g.local.SetWithTTL(key, value, 0, ttl)
+}
+
+// url 构造访问持有者的地址
+func (g *Group) url(node, key string) string {
+	return node + g.opts.BasePath + url.PathEscape(key)
+}
+
+// remoteGet 向持有者读取
+func (g *Group) remoteGet(ctx context.Context, node, key string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url(node, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("访问实例%s错误: %w", node, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, cache.ErrCacheNotFound
+	default:
+		return nil, fmt.Errorf("实例%s返回错误状态: %s", node, resp.Status)
+	}
+}
+
+// remoteSet 写入持有者
+func (g *Group) remoteSet(ctx context.Context, node, key string, value []byte, ttl time.Duration) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, g.url(node, key), bytes.NewReader(value))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(ttlHeader, strconv.FormatInt(ttl.Milliseconds(), 10))
+	return g.do(req, node)
+}
+
+// remoteDel 删除持有者上的值
+func (g *Group) remoteDel(ctx context.Context, node, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, g.url(node, key), nil)
+	if err != nil {
+		return err
+	}
+	return g.do(req, node)
+}
+
+// do 执行不需要响应内容的请求
+func (g *Group) do(req *http.Request, node string) error {
+	resp, err := g.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("访问实例%s错误: %w", node, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("实例%s返回错误状态: %s", node, resp.Status)
+	}
+	return nil
+}
+
+// ServeHTTP 处理其他实例的请求，只访问本地存储，不再转发
+func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.EscapedPath(), g.opts.BasePath) {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), g.opts.BasePath))
+	if err != nil || key == "" {
+		http.Error(w, "无效的键", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, err := g.localGet(r.Context(), key)
+		// 加载函数可能直接返回redis.Nil
+		if errors.Is(err, cache.ErrCacheNotFound) || errors.Is(err, redis.Nil) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(value)
+	case http.MethodPut:
+		ms, err := strconv.ParseInt(r.Header.Get(ttlHeader), 10, 64)
+		if err != nil || ms < 0 {
+			http.Error(w, "无效的过期时间", http.StatusBadRequest)
+			return
+		}
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		g.localSet(key, value, time.Duration(ms)*time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		g.local.Del(key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,199 @@
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// newTestCluster 创建n个互相连接的实例
+func newTestCluster(t *testing.T, n int, getter Getter) []*Group {
+	t.Helper()
+	groups := make([]*Group, n)
+	addrs := make([]string, n)
+	for i := range groups {
+		i := i
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			groups[i].ServeHTTP(w, r)
+		}))
+		t.Cleanup(srv.Close)
+		addrs[i] = srv.URL
+	}
+	for i := range groups {
+		g, err := NewGroup(Options{Self: addrs[i], Getter: getter, Capacity: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.SetPeers(addrs...)
+		groups[i] = g
+	}
+	return groups
+}
+
+func TestRing(t *testing.T) {
+	if got := newRing(10).get("k"); got != "" {
+		t.Errorf("空环 get() = %q, want empty", got)
+	}
+	nodes := []string{"a", "b", "c"}
+	r := newRing(50, nodes...)
+	counts := make(map[string]int)
+	moved := 0
+	grown := newRing(50, append(nodes, "d")...)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		node := r.get(key)
+		counts[node]++
+		if r.get(key) != node {
+			t.Fatalf("get(%s) 结果不稳定", key)
+		}
+		if after := grown.get(key); after != node {
+			if after != "d" {
+				t.Fatalf("增加节点后键%s从%s移动到%s，只应移动到新节点", key, node, after)
+			}
+			moved++
+		}
+	}
+	for _, node := range nodes {
+		if counts[node] == 0 {
+			t.Errorf("节点%s没有分配到键: %v", node, counts)
+		}
+	}
+	if moved == 0 || moved > 500 {
+		t.Errorf("增加节点后移动的键 = %d，want 部分键", moved)
+	}
+}
+
+func TestGroupSetGetDel(t *testing.T) {
+	groups := newTestCluster(t, 3, nil)
+	ctx := context.Background()
+	keys := []string{"user:1", "user:2", "订单/3", "a b"}
+	for i, key := range keys {
+		if err := groups[i%len(groups)].Set(ctx, key, []byte(key), time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+	for _, key := range keys {
+		holders := 0
+		for _, g := range groups {
+			got, err := g.Get(ctx, key)
+			if err != nil || string(got) != key {
+				t.Errorf("Get(%s) = %q, %v", key, got, err)
+			}
+			if _, ok := g.local.Get(key); ok {
+				holders++
+			}
+		}
+		if holders != 1 {
+			t.Errorf("键%s由%d个实例持有，want 1", key, holders)
+		}
+	}
+
+	if err := groups[0].Del(ctx, keys...); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	for _, key := range keys {
+		if _, err := groups[1].Get(ctx, key); !errors.Is(err, cache.ErrCacheNotFound) {
+			t.Errorf("删除后 Get(%s) error = %v, want ErrCacheNotFound", key, err)
+		}
+	}
+}
+
+func TestGroupGetter(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	groups := newTestCluster(t, 3, func(ctx context.Context, key string) ([]byte, error) {
+		calls.Add(1)
+		if strings.HasPrefix(key, "missing") {
+			return nil, cache.ErrCacheNotFound
+		}
+		<-release
+		return []byte("loaded:" + key), nil
+	})
+	ctx := context.Background()
+
+	// 各实例并发读取同一个键，持有者只加载一次
+	var wg sync.WaitGroup
+	errs := make(chan error, 9)
+	for i := 0; i < 9; i++ {
+		g := groups[i%len(groups)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := g.Get(ctx, "k")
+			if err == nil && string(got) != "loaded:k" {
+				err = fmt.Errorf("Get() = %q", got)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("加载函数调用次数 = %d, want 1", n)
+	}
+
+	for _, g := range groups {
+		if _, err := g.Get(ctx, "missing"); !errors.Is(err, cache.ErrCacheNotFound) {
+			t.Errorf("Get(missing) error = %v, want ErrCacheNotFound", err)
+		}
+	}
+}
+
+func TestGroupServeHTTP(t *testing.T) {
+	g, err := NewGroup(Options{Self: "http://self"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		ttl    string
+		want   int
+	}{
+		{"put", http.MethodPut, "/_cache/k", "1000", http.StatusNoContent},
+		{"get", http.MethodGet, "/_cache/k", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/_cache/missing", "", http.StatusNotFound},
+		{"put bad ttl", http.MethodPut, "/_cache/k", "soon", http.StatusBadRequest},
+		{"put negative ttl", http.MethodPut, "/_cache/k", "-1", http.StatusBadRequest},
+		{"empty key", http.MethodGet, "/_cache/", "", http.StatusBadRequest},
+		{"other path", http.MethodGet, "/other/k", "", http.StatusNotFound},
+		{"bad method", http.MethodPost, "/_cache/k", "", http.StatusMethodNotAllowed},
+		{"delete", http.MethodDelete, "/_cache/k", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("v"))
+			if tt.ttl != "" {
+				req.Header.Set(ttlHeader, tt.ttl)
+			}
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("状态码 = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGroupRequiresSelf(t *testing.T) {
+	if _, err := NewGroup(Options{}); err == nil {
+		t.Error("NewGroup() error = nil, want error")
+	}
+}
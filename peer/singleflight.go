@@ -0,0 +1,41 @@
+package peer
+
+import "sync"
+
+// call 进行中的加载
+type call struct {
+	wg  sync.WaitGroup
+	val []byte
+	err error
+}
+
+// flightGroup 合并同一个键的并发加载
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do 执行加载，同一个键同时只执行一次，其他调用等待并共享结果
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return c.val, c.err
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithObjectPool 设置批量获取(MultiGet)解码目标对象的对象池
+// 对象从pool中获取，未命中、占位符和解码失败时放回；返回给调用方的对象由调用方使用完后放回，以减少大量解码时的内存分配
+// pool中的对象类型必须与newObject创建的类型相同，pool为空或返回nil时使用newObject创建
+// 对象在解码前被重置：实现了Reset方法(如protobuf消息)时调用Reset，否则置为零值
+func WithObjectPool(pool *sync.Pool) CacheOption {
+	return func(o *cacheOptions) {
+		o.pool = pool
+	}
+}
+
+// acquireObject 从对象池获取重置后的对象，对象池为空时使用newObject创建
+func acquireObject(pool *sync.Pool, newObject func() interface{}) interface{} {
+	if pool == nil {
+		return newObject()
+	}
+	object := pool.Get()
+	if object == nil {
+		return newObject()
+	}
+	if r, ok := object.(interface{ Reset() }); ok {
+		r.Reset()
+		return object
+	}
+	if v := reflect.ValueOf(object); v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+	return object
+}
+
+// releaseObject 将未返回给调用方的对象放回对象池
+func releaseObject(pool *sync.Pool, object interface{}) {
+	if pool != nil && object != nil {
+		pool.Put(object)
+	}
+}
+
+// acquire 获取批量获取的解码目标对象
+func (o *cacheOptions) acquire(newObject func() interface{}) interface{} {
+	return acquireObject(o.pool, newObject)
+}
+
+// release 放回未使用的解码目标对象
+func (o *cacheOptions) release(object interface{}) {
+	releaseObject(o.pool, object)
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// prefixPlaceholder 键前缀中的占位符，如{app}、{env}、{region}
+var prefixPlaceholder = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// ExpandKeyPrefix 解析键前缀中的占位符
+// 占位符的值优先从vars中查找，其次从环境变量CACHE_<大写名称>中查找，
+// 例如{env}对应环境变量CACHE_ENV，无法解析的占位符返回错误
+func ExpandKeyPrefix(prefix string, vars map[string]string) (string, error) {
+	var missing []string
+	expanded := prefixPlaceholder.ReplaceAllStringFunc(prefix, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv("CACHE_" + strings.ToUpper(name)); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return m
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("键前缀 %s 中的占位符无法解析: %s", prefix, strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// SchemaHash 计算对象类型结构的哈希值
+// 哈希基于字段名、字段类型和标签递归计算，结构变化时哈希随之变化
+func SchemaHash(v interface{}) string {
+	h := fnv.New32a()
+	writeTypeSchema(h, reflect.TypeOf(v), make(map[reflect.Type]bool))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// writeTypeSchema 递归写入类型结构描述
+func writeTypeSchema(w io.Writer, t reflect.Type, seen map[reflect.Type]bool) {
+	if t == nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		fmt.Fprintf(w, "%s(", t.Kind())
+		writeTypeSchema(w, t.Elem(), seen)
+		fmt.Fprint(w, ")")
+	case reflect.Map:
+		fmt.Fprint(w, "map(")
+		writeTypeSchema(w, t.Key(), seen)
+		fmt.Fprint(w, ",")
+		writeTypeSchema(w, t.Elem(), seen)
+		fmt.Fprint(w, ")")
+	case reflect.Struct:
+		// 递归类型只写入一次结构
+		if seen[t] {
+			fmt.Fprintf(w, "ref(%s)", t.String())
+			return
+		}
+		seen[t] = true
+		fmt.Fprint(w, "struct{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fmt.Fprintf(w, "%s %q ", f.Name, f.Tag)
+			writeTypeSchema(w, f.Type, seen)
+			fmt.Fprint(w, ";")
+		}
+		fmt.Fprint(w, "}")
+	default:
+		fmt.Fprint(w, t.String())
+	}
+}
+
+// schemaKeyPrefix 将结构版本混入键前缀，结构变化后自动使用新的键空间
+// version优先于结构哈希，两者都未启用时返回原前缀
+func schemaKeyPrefix(keyPrefix, version string, hash bool, newObject func() interface{}) string {
+	var schema string
+	switch {
+	case version != "":
+		schema = "v" + version
+	case hash && newObject != nil:
+		schema = "s" + SchemaHash(newObject())
+	default:
+		return keyPrefix
+	}
+	if keyPrefix == "" {
+		return schema
+	}
+	return strings.Join([]string{keyPrefix, schema}, ":")
+}
+
+// keyPrefixCtxKey 上下文中键前缀的键
+type keyPrefixCtxKey struct{}
+
+// WithKeyPrefix 返回携带键前缀的上下文，缓存操作构建键时使用该前缀替换实例的键前缀
+// 可通过包级辅助函数按请求路由到不同租户，无需为每个租户创建缓存实例
+func WithKeyPrefix(ctx context.Context, keyPrefix string) context.Context {
+	return context.WithValue(ctx, keyPrefixCtxKey{}, keyPrefix)
+}
+
+// KeyPrefixFromContext 获取上下文中的键前缀，未设置时返回实例的键前缀defaultPrefix
+func KeyPrefixFromContext(ctx context.Context, defaultPrefix string) string {
+	if ctx == nil {
+		return defaultPrefix
+	}
+	if keyPrefix, ok := ctx.Value(keyPrefixCtxKey{}).(string); ok && keyPrefix != "" {
+		return keyPrefix
+	}
+	return defaultPrefix
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrShed 后端饱和时低优先级操作被丢弃
+var ErrShed = errors.New("后端饱和，低优先级操作已丢弃")
+
+// Priority 操作优先级
+type Priority int
+
+const (
+	// PriorityNormal 普通优先级，默认值，不会被丢弃
+	PriorityNormal Priority = iota
+	// PriorityLow 低优先级，后端饱和时写入被丢弃，如后台刷新、预热
+	PriorityLow
+)
+
+// priorityCtxKey 上下文中优先级的键
+type priorityCtxKey struct{}
+
+// WithPriority 返回携带操作优先级的上下文
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, priority)
+}
+
+// PriorityFromContext 获取上下文中的操作优先级，未设置时为PriorityNormal
+func PriorityFromContext(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+	if priority, ok := ctx.Value(priorityCtxKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// LoadShedConfig 负载丢弃配置
+// 后端饱和时丢弃低优先级的写入，读取和普通优先级的操作不受影响，以控制压力下的尾部延迟
+type LoadShedConfig struct {
+	// PoolSaturation 连接池饱和度阈值，使用中连接数与容量之比达到该值时视为饱和，默认0.9
+	PoolSaturation float64 `json:"pool_saturation" yaml:"pool_saturation"`
+	// OnShed 操作被丢弃时的回调，op为操作名，如set
+	OnShed func(op string) `json:"-" yaml:"-"`
+}
+
+// setDefaults 设置默认值
+func (c *LoadShedConfig) setDefaults() {
+	if c.PoolSaturation <= 0 {
+		c.PoolSaturation = 0.9
+	}
+}
+
+// loadShedder 判断后端是否饱和
+type loadShedder struct {
+	config     LoadShedConfig
+	supervisor *supervisor
+	pool       func() (*redis.PoolStats, int)
+}
+
+// newLoadShedder 创建负载丢弃判断，pool返回连接池统计和容量，supervisor可以为空
+func newLoadShedder(config LoadShedConfig, s *supervisor, pool func() (*redis.PoolStats, int)) *loadShedder {
+	config.setDefaults()
+	return &loadShedder{config: config, supervisor: s, pool: pool}
+}
+
+// saturated 后端是否饱和，连接监控器探测失败(尚未判定断开)、正在重连或连接池饱和度达到阈值时视为饱和
+func (l *loadShedder) saturated() bool {
+	if l.supervisor != nil && l.supervisor.degraded() {
+		return true
+	}
+	if l.pool == nil {
+		return false
+	}
+	var status HealthStatus
+	stats, poolSize := l.pool()
+	fillPoolStats(&status, stats, poolSize)
+	return status.PoolSaturation >= l.config.PoolSaturation
+}
+
+// allow 判断操作是否可以执行，被丢弃时返回ErrShed
+func (l *loadShedder) allow(ctx context.Context, op string) error {
+	if PriorityFromContext(ctx) != PriorityLow || !l.saturated() {
+		return nil
+	}
+	if l.config.OnShed != nil {
+		l.config.OnShed(op)
+	}
+	return ErrShed
+}
+
+// sheddingCache 丢弃低优先级写入的缓存，读取和删除总是执行
+// 删除用于保证数据一致性，不会被丢弃
+type sheddingCache struct {
+	forwarder
+	shedder *loadShedder
+}
+
+// Set 设置数据，低优先级且后端饱和时丢弃
+func (c *sheddingCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := c.shedder.allow(ctx, OpSet); err != nil {
+		return err
+	}
+	return c.Cache.Set(ctx, key, val, expiration)
+}
+
+// MultiSet 批量设置数据，低优先级且后端饱和时丢弃
+func (c *sheddingCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	if err := c.shedder.allow(ctx, OpMultiSet); err != nil {
+		return err
+	}
+	return c.Cache.MultiSet(ctx, valueMap, expiration)
+}
+
+// SetNX 仅在键不存在时设置数据，低优先级且后端饱和时丢弃
+func (c *sheddingCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	if err := c.shedder.allow(ctx, OpSetNX); err != nil {
+		return false, err
+	}
+	return c.forwarder.SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签，低优先级且后端饱和时丢弃
+func (c *sheddingCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if err := c.shedder.allow(ctx, OpSetWithTags); err != nil {
+		return err
+	}
+	return c.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存，低优先级且后端饱和时丢弃
+func (c *sheddingCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	if err := c.shedder.allow(ctx, OpSetCacheWithNotFound); err != nil {
+		return err
+	}
+	return c.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存，低优先级且后端饱和时丢弃
+func (c *sheddingCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	if err := c.shedder.allow(ctx, OpMultiSetCacheWithNotFound); err != nil {
+		return err
+	}
+	return c.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// Expire 重设过期时间，低优先级且后端饱和时丢弃
+func (c *sheddingCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.shedder.allow(ctx, OpExpire); err != nil {
+		return err
+	}
+	return c.Cache.Expire(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增，低优先级且后端饱和时丢弃
+func (c *sheddingCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if err := c.shedder.allow(ctx, OpIncrWithTTL); err != nil {
+		return 0, err
+	}
+	return c.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// Copy 复制键到目标键，低优先级且后端饱和时丢弃
+func (c *sheddingCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	if err := c.shedder.allow(ctx, OpCopy); err != nil {
+		return err
+	}
+	return c.forwarder.Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键，低优先级且后端饱和时丢弃
+func (c *sheddingCache) Rename(ctx context.Context, src, dst string) error {
+	if err := c.shedder.allow(ctx, OpRename); err != nil {
+		return err
+	}
+	return c.forwarder.Rename(ctx, src, dst)
+}
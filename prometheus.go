@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// promStatsCollector 基于Prometheus的统计收集器
+type promStatsCollector struct {
+	hits    *prometheus.CounterVec
+	misses  *prometheus.CounterVec
+	errors  *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewPrometheusStatsCollector 创建Prometheus统计收集器，指标按backend和operation标签区分
+// 多个缓存可以使用同一个registerer，指标已注册时复用已注册的指标
+func NewPrometheusStatsCollector(registerer prometheus.Registerer) (StatsCollector, error) {
+	labels := []string{"backend", "operation"}
+	hits, err := registerVec(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "缓存命中次数",
+	}, labels))
+	if err != nil {
+		return nil, fmt.Errorf("注册命中计数器失败: %w", err)
+	}
+	misses, err := registerVec(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "缓存未命中次数",
+	}, labels))
+	if err != nil {
+		return nil, fmt.Errorf("注册未命中计数器失败: %w", err)
+	}
+	errs, err := registerVec(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_errors_total",
+		Help: "缓存操作错误次数",
+	}, labels))
+	if err != nil {
+		return nil, fmt.Errorf("注册错误计数器失败: %w", err)
+	}
+	latency, err := registerVec(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cache_operation_duration_seconds",
+		Help:    "缓存操作耗时",
+		Buckets: []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, labels))
+	if err != nil {
+		return nil, fmt.Errorf("注册耗时直方图失败: %w", err)
+	}
+
+	return &promStatsCollector{
+		hits:    hits,
+		misses:  misses,
+		errors:  errs,
+		latency: latency,
+	}, nil
+}
+
+// registerVec 注册指标，已注册相同指标时返回已注册的指标
+func registerVec[T prometheus.Collector](registerer prometheus.Registerer, c T) (T, error) {
+	err := registerer.Register(c)
+	if err == nil {
+		return c, nil
+	}
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+	return c, err
+}
+
+// WithMetrics 为缓存添加Prometheus指标，记录命中、未命中、错误次数和操作耗时
+func WithMetrics(c Cache, backend CacheType, registerer prometheus.Registerer) (Cache, error) {
+	collector, err := NewPrometheusStatsCollector(registerer)
+	if err != nil {
+		return nil, err
+	}
+	return WithStats(c, backend, collector), nil
+}
+
+// IncrHit 命中计数加一
+func (p *promStatsCollector) IncrHit(backend CacheType, op string) {
+	p.hits.WithLabelValues(string(backend), op).Inc()
+}
+
+// IncrMiss 未命中计数加一
+func (p *promStatsCollector) IncrMiss(backend CacheType, op string) {
+	p.misses.WithLabelValues(string(backend), op).Inc()
+}
+
+// ObserveLatency 记录操作耗时
+func (p *promStatsCollector) ObserveLatency(backend CacheType, op string, d time.Duration) {
+	p.latency.WithLabelValues(string(backend), op).Observe(d.Seconds())
+}
+
+// IncrError 错误计数加一
+func (p *promStatsCollector) IncrError(backend CacheType, op string) {
+	p.errors.WithLabelValues(string(backend), op).Inc()
+}
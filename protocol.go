@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ProtocolRESP2 RESP2协议，兼容所有Redis版本和托管服务
+	ProtocolRESP2 = 2
+	// ProtocolRESP3 RESP3协议，Redis 6.0及以上支持，客户端缓存依赖RESP3推送
+	ProtocolRESP3 = 3
+)
+
+// validateProtocol 校验RESP协议版本配置
+func validateProtocol(config *Config) error {
+	var protocol int
+	switch {
+	case config.Type == RedisCache && config.Redis != nil:
+		protocol = config.Redis.Protocol
+		if protocol == ProtocolRESP3 && config.Redis.ProxyMode != ProxyModeNone {
+			return fmt.Errorf("代理模式%s只支持RESP2协议", config.Redis.ProxyMode)
+		}
+	case config.Type == RedisClusterCache && config.RedisCluster != nil:
+		protocol = config.RedisCluster.Protocol
+	default:
+		return nil
+	}
+	switch protocol {
+	case 0, ProtocolRESP2, ProtocolRESP3:
+		return nil
+	default:
+		return fmt.Errorf("不支持的RESP协议版本: %d", protocol)
+	}
+}
+
+// protocol 获取当前缓存类型实际使用的RESP协议版本，未设置时为RESP3，代理模式下为RESP2
+func (c *Config) protocol() int {
+	protocol := 0
+	switch {
+	case c.Type == RedisCache && c.Redis != nil:
+		if c.Redis.ProxyMode != ProxyModeNone {
+			return ProtocolRESP2
+		}
+		protocol = c.Redis.Protocol
+	case c.Type == RedisClusterCache && c.RedisCluster != nil:
+		protocol = c.RedisCluster.Protocol
+	}
+	if protocol == 0 {
+		return ProtocolRESP3
+	}
+	return protocol
+}
+
+// redisInt64 读取整数结果
+// RESP3下超出整数范围的结果以大数类型返回，RESP2下Lua脚本可能以字符串返回数字
+func redisInt64(cmd *redis.Cmd) (int64, error) {
+	v, err := cmd.Result()
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := v.(*big.Int); ok {
+		if !n.IsInt64() {
+			return 0, fmt.Errorf("%w: 整数超出范围: %s", ErrDecode, n)
+		}
+		return n.Int64(), nil
+	}
+	return cmd.Int64()
+}
@@ -1,10 +1,12 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/dgraph-io/ristretto"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -24,32 +26,108 @@ const (
 type Config struct {
 	// Type 缓存类型
 	Type CacheType `json:"type" yaml:"type"`
-	// KeyPrefix 键前缀
+	// KeyPrefix 键前缀，支持{app}、{env}、{region}等占位符，创建提供者时解析
 	KeyPrefix string `json:"key_prefix" yaml:"key_prefix"`
-	// DefaultExpireTime 默认过期时间
+	// Encoding 编码名称，如json、msgpack、gob、proto或通过RegisterCodec注册的编码，
+	// 仅在NewProvider的encoding参数为空时生效，默认为json
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// PrefixVars 键前缀占位符的值，未配置的占位符从环境变量CACHE_<大写名称>中读取
+	PrefixVars map[string]string `json:"prefix_vars,omitempty" yaml:"prefix_vars,omitempty"`
+	// SchemaVersion 缓存数据结构版本，非空时混入键前缀，修改版本即可让旧数据失效
+	SchemaVersion string `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
+	// SchemaHash 将newObject返回对象的结构哈希混入键前缀，结构变化后不会解码到不兼容的旧数据
+	SchemaHash bool `json:"schema_hash,omitempty" yaml:"schema_hash,omitempty"`
+	// DefaultExpireTime 默认过期时间，仅在ZeroTTLPolicy为default时对过期时间为0的写入生效
 	DefaultExpireTime time.Duration `json:"default_expire_time" yaml:"default_expire_time"`
+	// NotFoundExpireTime 未找到占位符(缓存穿透)的过期时间，0表示使用包级DefaultNotFoundExpireTime
+	NotFoundExpireTime time.Duration `json:"not_found_expire_time,omitempty" yaml:"not_found_expire_time,omitempty"`
+	// DisableNotFoundPlaceholder 禁用未找到占位符，空数据按原样写入和读取
+	// 开启后SetCacheWithNotFound和MultiSetCacheWithNotFound不写入任何数据，GetOrSet加载到不存在的数据时返回ErrNotFound
+	DisableNotFoundPlaceholder bool `json:"disable_not_found_placeholder,omitempty" yaml:"disable_not_found_placeholder,omitempty"`
+	// NotFoundPlaceholder 未找到占位符，为空时使用包级NotFoundPlaceholder("*")，读写同一批键的实例需要使用相同的占位符
+	NotFoundPlaceholder string `json:"not_found_placeholder,omitempty" yaml:"not_found_placeholder,omitempty"`
+	// NotFoundMaxTTL 大于未找到占位符的过期时间时启用指数退避，同一个键连续未找到时占位符过期时间逐次翻倍直到该值
+	NotFoundMaxTTL time.Duration `json:"not_found_max_ttl,omitempty" yaml:"not_found_max_ttl,omitempty"`
+	// MinTTL 最小过期时间，低于该值的写入会被提升为MinTTL，0表示不限制
+	MinTTL time.Duration `json:"min_ttl,omitempty" yaml:"min_ttl,omitempty"`
+	// MaxTTL 最大过期时间，超过该值的写入会被截断为MaxTTL，0表示不限制，永不过期的写入不受影响
+	MaxTTL time.Duration `json:"max_ttl,omitempty" yaml:"max_ttl,omitempty"`
+	// OnTTLClamped 过期时间被修正时的回调，用于发现误用超长过期时间的调用方
+	OnTTLClamped TTLClampFunc `json:"-" yaml:"-"`
+	// ZeroTTLPolicy 过期时间为0时的处理策略：no_expiry(默认，永不过期)、default(使用DefaultExpireTime)、reject(拒绝写入)
+	ZeroTTLPolicy ZeroTTLPolicy `json:"zero_ttl_policy,omitempty" yaml:"zero_ttl_policy,omitempty"`
 	// Memory 内存缓存配置
 	Memory *MemoryConfig `json:"memory,omitempty" yaml:"memory,omitempty"`
 	// Redis Redis缓存配置
 	Redis *RedisConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
 	// RedisCluster Redis集群缓存配置
 	RedisCluster *RedisClusterConfig `json:"redis_cluster,omitempty" yaml:"redis_cluster,omitempty"`
+	// Extra 通过RegisterStore或RegisterBackend注册的后端的特有配置，如DynamoDB的表名，可以包含嵌套的配置段
+	// 后端通过ExtraString读取单个配置，或通过DecodeExtra解码到自己的配置结构
+	Extra map[string]interface{} `json:"extra,omitempty" yaml:"extra,omitempty"`
+	// Envelope 写入时使用带版本的值信封，读取时总是兼容信封格式和旧格式
+	Envelope bool `json:"envelope" yaml:"envelope"`
+	// EnvelopePolicy 遇到旧格式或更新版本信封时的处理策略，默认为ignore_unknown
+	EnvelopePolicy EnvelopePolicy `json:"envelope_policy,omitempty" yaml:"envelope_policy,omitempty"`
+	// UseUnlink Redis删除时使用UNLINK代替DEL，避免删除大值阻塞Redis，需要Redis 4.0及以上
+	UseUnlink bool `json:"use_unlink" yaml:"use_unlink"`
+	// LatencyInjector 延迟注入器，仅用于预发布环境验证超时和熔断配置，为空时不注入
+	LatencyInjector *LatencyInjector `json:"-" yaml:"-"`
+	// TraceCommands 调试模式，将每个操作实际发往Redis的命令和最终键作为事件写入当前span，仅对Redis类型生效
+	TraceCommands bool `json:"trace_commands" yaml:"trace_commands"`
+	// CommandTrace 启用TraceCommands时同时接收命令记录，如写入日志，为空时只写入span
+	CommandTrace CommandTraceFunc `json:"-" yaml:"-"`
+	// Stats 统计收集器，为空时不统计
+	Stats StatsCollector `json:"-" yaml:"-"`
+	// AccessSampler 键访问采样器，按比例记录被访问的键、命中情况和值大小，为空时不采样
+	AccessSampler *AccessSampler `json:"-" yaml:"-"`
+	// LazyConnect 延迟连接，创建提供者时不访问网络，首次操作或调用Connect时才建立连接
+	LazyConnect bool `json:"lazy_connect" yaml:"lazy_connect"`
+	// VerifyOnStartup 创建提供者时在DialTimeout内PING后端，不可达时立即返回错误，不能与LazyConnect同时使用
+	VerifyOnStartup bool `json:"verify_on_startup" yaml:"verify_on_startup"`
+	// Supervisor 连接监控配置，为空时不监控，仅对Redis类型生效
+	Supervisor *SupervisorConfig `json:"supervisor,omitempty" yaml:"supervisor,omitempty"`
+	// LoadShed 负载丢弃配置，为空时不丢弃，仅对Redis类型生效，通过WithPriority标记低优先级操作
+	LoadShed *LoadShedConfig `json:"load_shed,omitempty" yaml:"load_shed,omitempty"`
+	// WriteLimit 单键写入频率限制配置，为空时不限制
+	WriteLimit *WriteLimitConfig `json:"write_limit,omitempty" yaml:"write_limit,omitempty"`
+	// Quota 命名空间软配额配置，为空时不统计
+	Quota *QuotaConfig `json:"quota,omitempty" yaml:"quota,omitempty"`
+	// ReadYourWrites 保证同一进程内成功的Set对之后的Get可见，仅对内存类型生效
+	// 每次写入都等待ristretto缓冲生效(忽略WithAsyncWrites)，并在未配置Memory.SecondChance时使用默认的二次机会缓存，
+	// 写入吞吐下降，并额外占用二次机会缓存的内存；被WriteLimit丢弃或合并的写入不在保证范围内
+	// 两级缓存的L1使用开启该选项的内存缓存即可获得相同保证
+	ReadYourWrites bool `json:"read_your_writes,omitempty" yaml:"read_your_writes,omitempty"`
 }
 
 // MemoryConfig 内存缓存配置
 type MemoryConfig struct {
+	// Engine 内存缓存引擎，ristretto(默认)、deterministic或lru
+	Engine MemoryEngine `json:"engine,omitempty" yaml:"engine,omitempty"`
 	// NumCounters 跟踪频率的键数量
 	NumCounters int64 `json:"num_counters" yaml:"num_counters"`
-	// MaxCost 缓存的最大成本
+	// MaxCost 缓存的最大成本，deterministic引擎按值的字节数计算，0表示不限制
 	MaxCost int64 `json:"max_cost" yaml:"max_cost"`
 	// BufferItems 每个Get缓冲区的键数量
 	BufferItems int64 `json:"buffer_items" yaml:"buffer_items"`
+	// Capacity lru引擎的最大条目数量，默认1000
+	Capacity int `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+	// SecondChance ristretto引擎的二次机会缓存，最近写入的条目被拒绝准入时仍可以读取，为空时不开启
+	SecondChance *SecondChanceConfig `json:"second_chance,omitempty" yaml:"second_chance,omitempty"`
+	// NamespaceWeights 按命名空间(键前缀)分配容量的权重，为空时所有命名空间共享同一个存储
+	// 每个命名空间使用独立的存储，MaxCost、NumCounters和Capacity按权重占比分配，写入只淘汰同一命名空间的条目；
+	// 键为""的权重用于未列出的命名空间，默认为1
+	NamespaceWeights map[string]int64 `json:"namespace_weights,omitempty" yaml:"namespace_weights,omitempty"`
 }
 
 // RedisConfig Redis缓存配置
 type RedisConfig struct {
+	// Network 网络类型，tcp或unix，默认为tcp
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
 	// Addr Redis服务器地址
 	Addr string `json:"addr" yaml:"addr"`
+	// SocketPath Unix域套接字路径，设置后忽略Addr并使用unix网络类型
+	SocketPath string `json:"socket_path,omitempty" yaml:"socket_path,omitempty"`
 	// Password Redis密码
 	Password string `json:"password" yaml:"password"`
 	// DB Redis数据库索引
@@ -68,6 +146,20 @@ type RedisConfig struct {
 	ReadTimeout time.Duration `json:"read_timeout" yaml:"read_timeout"`
 	// WriteTimeout 写入超时时间
 	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
+	// CredentialsProvider 凭证提供函数，每次建立新连接时调用，用于IAM等短期令牌认证，设置后忽略Password
+	CredentialsProvider func(ctx context.Context) (username, password string, err error) `json:"-" yaml:"-"`
+	// PasswordFile 密码文件路径，文件内容更新后新建立的连接自动使用新密码，无需重启
+	PasswordFile string `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	// PasswordReloadInterval 密码文件检查间隔，默认10秒
+	PasswordReloadInterval time.Duration `json:"password_reload_interval,omitempty" yaml:"password_reload_interval,omitempty"`
+	// TenantDBs 租户到数据库索引的映射，通过ForTenant获取租户对应的缓存
+	TenantDBs map[string]int `json:"tenant_dbs,omitempty" yaml:"tenant_dbs,omitempty"`
+	// Compat Valkey、Dragonfly等兼容服务端的兼容性配置
+	Compat *CompatConfig `json:"compat,omitempty" yaml:"compat,omitempty"`
+	// ProxyMode 通过Twemproxy、Envoy等代理访问时设置，只使用代理支持的命令，并校验配置
+	ProxyMode ProxyMode `json:"proxy_mode,omitempty" yaml:"proxy_mode,omitempty"`
+	// Protocol RESP协议版本，2或3，默认3，部分托管服务拒绝RESP3握手时设置为2
+	Protocol int `json:"protocol,omitempty" yaml:"protocol,omitempty"`
 }
 
 // RedisClusterConfig Redis集群缓存配置
@@ -90,6 +182,18 @@ type RedisClusterConfig struct {
 	ReadTimeout time.Duration `json:"read_timeout" yaml:"read_timeout"`
 	// WriteTimeout 写入超时时间
 	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
+	// CredentialsProvider 凭证提供函数，每次建立新连接时调用，用于IAM等短期令牌认证，设置后忽略Password
+	CredentialsProvider func(ctx context.Context) (username, password string, err error) `json:"-" yaml:"-"`
+	// PasswordFile 密码文件路径，文件内容更新后新建立的连接自动使用新密码，无需重启
+	PasswordFile string `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	// PasswordReloadInterval 密码文件检查间隔，默认10秒
+	PasswordReloadInterval time.Duration `json:"password_reload_interval,omitempty" yaml:"password_reload_interval,omitempty"`
+	// Compat Valkey、Dragonfly等兼容服务端的兼容性配置
+	Compat *CompatConfig `json:"compat,omitempty" yaml:"compat,omitempty"`
+	// Protocol RESP协议版本，2或3，默认3，部分托管服务拒绝RESP3握手时设置为2
+	Protocol int `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	// ReplicaReads 只读视图(ReadOnly)从从节点读取
+	ReplicaReads bool `json:"replica_reads,omitempty" yaml:"replica_reads,omitempty"`
 }
 
 // Provider 缓存提供者接口
@@ -98,12 +202,18 @@ type Provider interface {
 	GetCache() Cache
 	// Close 关闭缓存连接
 	Close() error
+	// Connect 显式建立连接，用于延迟连接模式下提前建立连接并检查可用性
+	Connect(ctx context.Context) error
+	// HealthCheck 检查缓存健康状态，包括往返耗时、连接池饱和度和最近一次错误
+	HealthCheck(ctx context.Context) HealthStatus
 }
 
 // memoryProvider 内存缓存提供者
 type memoryProvider struct {
 	cache  Cache
-	client *ristretto.Cache
+	client MemoryStore
+	health healthRecorder
+	locker Locker
 }
 
 // GetCache 获取内存缓存实例
@@ -119,10 +229,26 @@ func (p *memoryProvider) Close() error {
 	return nil
 }
 
+// Connect 内存缓存无需建立连接
+func (p *memoryProvider) Connect(_ context.Context) error {
+	return nil
+}
+
 // redisProvider Redis缓存提供者
 type redisProvider struct {
-	cache  Cache
-	client *redis.Client
+	cache       Cache
+	client      *redis.Client
+	keyPrefix   string
+	health      healthRecorder
+	supervisor  *supervisor
+	conn        lazyConn
+	probe       *compatProbe
+	redisConfig *RedisConfig
+	newCache    func(client *redis.Client) Cache
+	wrap        func(c Cache) Cache
+	dbMu        sync.Mutex
+	dbClients   map[int]*redis.Client
+	dbCaches    map[int]Cache
 }
 
 // GetCache 获取Redis缓存实例
@@ -132,16 +258,99 @@ func (p *redisProvider) GetCache() Cache {
 
 // Close 关闭Redis连接
 func (p *redisProvider) Close() error {
-	if p.client != nil {
-		return p.client.Close()
+	return p.conn.close(func() error {
+		if p.supervisor != nil {
+			p.supervisor.Close()
+		}
+		return errors.Join(p.client.Close(), p.closeDBClients())
+	})
+}
+
+// Connect 建立Redis连接并检查可用性
+func (p *redisProvider) Connect(ctx context.Context) error {
+	if _, err := p.conn.ensure(); err != nil {
+		return err
+	}
+	if err := p.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: 连接Redis失败: %w", ErrBackend, err)
+	}
+	return p.probe.run(ctx, p.client)
+}
+
+// options 生成指定数据库的Redis客户端选项
+func (c *RedisConfig) options(db int) *redis.Options {
+	network := c.Network
+	if c.SocketPath != "" {
+		network = "unix"
+	}
+	opts := &redis.Options{
+		Network:         network,
+		Addr:            c.address(),
+		Password:        c.Password,
+		DB:              db,
+		PoolSize:        c.PoolSize,
+		MinIdleConns:    c.MinIdleConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+		DialTimeout:     c.DialTimeout,
+		ReadTimeout:     c.ReadTimeout,
+		WriteTimeout:    c.WriteTimeout,
+		Protocol:        c.Protocol,
+		DisableIdentity: c.Compat.disableIdentity(),
+
+		CredentialsProviderContext: credentialsProvider(c.CredentialsProvider, c.PasswordFile, c.PasswordReloadInterval),
+	}
+	if c.ProxyMode != ProxyModeNone {
+		// 代理不支持HELLO和CLIENT SETINFO，直接使用RESP2
+		opts.Protocol = 2
+		opts.DisableIdentity = true
+	}
+	return opts
+}
+
+// address 获取实际连接的地址
+func (c *RedisConfig) address() string {
+	if c.SocketPath != "" {
+		return c.SocketPath
+	}
+	return c.Addr
+}
+
+// options 生成Redis集群客户端选项
+func (c *RedisClusterConfig) options() *redis.ClusterOptions {
+	return &redis.ClusterOptions{
+		Addrs:           c.Addrs,
+		Password:        c.Password,
+		PoolSize:        c.PoolSize,
+		MinIdleConns:    c.MinIdleConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+		DialTimeout:     c.DialTimeout,
+		ReadTimeout:     c.ReadTimeout,
+		WriteTimeout:    c.WriteTimeout,
+		Protocol:        c.Protocol,
+		DisableIdentity: c.Compat.disableIdentity(),
+
+		CredentialsProviderContext: credentialsProvider(c.CredentialsProvider, c.PasswordFile, c.PasswordReloadInterval),
 	}
-	return nil
 }
 
 // redisClusterProvider Redis集群缓存提供者
 type redisClusterProvider struct {
-	cache  Cache
-	client *redis.ClusterClient
+	cache      Cache
+	client     *redis.ClusterClient
+	keyPrefix  string
+	health     healthRecorder
+	supervisor *supervisor
+	conn       lazyConn
+	probe      *compatProbe
+
+	clusterConfig *RedisClusterConfig
+	newCache      func(client *redis.ClusterClient) Cache
+	wrap          func(c Cache) Cache
+	replicaMu     sync.Mutex
+	replicaClient *redis.ClusterClient
+	replicaCache  Cache
 }
 
 // GetCache 获取Redis集群缓存实例
@@ -151,63 +360,171 @@ func (p *redisClusterProvider) GetCache() Cache {
 
 // Close 关闭Redis集群连接
 func (p *redisClusterProvider) Close() error {
-	if p.client != nil {
-		return p.client.Close()
+	return p.conn.close(func() error {
+		if p.supervisor != nil {
+			p.supervisor.Close()
+		}
+		return errors.Join(p.client.Close(), p.closeReplica())
+	})
+}
+
+// Connect 建立Redis集群连接并检查所有分片的可用性
+func (p *redisClusterProvider) Connect(ctx context.Context) error {
+	if _, err := p.conn.ensure(); err != nil {
+		return err
 	}
-	return nil
+	if err := p.pingAll(ctx); err != nil {
+		return fmt.Errorf("%w: 连接Redis集群失败: %w", ErrBackend, err)
+	}
+	if p.probe == nil {
+		return nil
+	}
+	return p.client.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		return p.probe.run(ctx, master)
+	})
+}
+
+// pingAll 探测所有分片
+func (p *redisClusterProvider) pingAll(ctx context.Context) error {
+	return p.client.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		return shard.Ping(ctx).Err()
+	})
 }
 
 // NewProvider 创建缓存提供者
-func NewProvider(config *Config, encoding Encoding, newObject func() interface{}) (Provider, error) {
+func NewProvider(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error) {
 	if config == nil {
 		return nil, fmt.Errorf("缓存配置不能为空")
 	}
+	if config.LazyConnect && config.VerifyOnStartup {
+		return nil, fmt.Errorf("LazyConnect和VerifyOnStartup不能同时启用")
+	}
+
+	switch config.EnvelopePolicy {
+	case "", EnvelopeIgnoreUnknown, EnvelopeTreatAsMiss, EnvelopeError:
+	default:
+		return nil, fmt.Errorf("不支持的信封策略: %s", config.EnvelopePolicy)
+	}
+	if err := validateTTLConfig(config); err != nil {
+		return nil, err
+	}
+	if config.NotFoundPlaceholder == TombstonePlaceholder {
+		return nil, fmt.Errorf("未找到占位符不能与墓碑标记相同: %s", config.NotFoundPlaceholder)
+	}
+	encoding, err := resolveEncoding(config, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProxyConfig(config); err != nil {
+		return nil, err
+	}
+	if err := validateProtocol(config); err != nil {
+		return nil, err
+	}
+	if config.WriteLimit != nil {
+		if err := config.WriteLimit.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if config.Quota != nil {
+		if err := config.Quota.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 解析键前缀模板，使用副本避免修改调用方的模板
+	keyPrefix, err := ExpandKeyPrefix(config.KeyPrefix, config.PrefixVars)
+	if err != nil {
+		return nil, err
+	}
+	keyPrefix = schemaKeyPrefix(keyPrefix, config.SchemaVersion, config.SchemaHash, newObject)
+	if keyPrefix != config.KeyPrefix {
+		resolved := *config
+		resolved.KeyPrefix = keyPrefix
+		config = &resolved
+	}
 
 	switch config.Type {
 	case MemoryCache:
-		return newMemoryProvider(config, encoding, newObject)
+		return newMemoryProvider(config, encoding, newObject, opts...)
 	case RedisCache:
-		return newRedisProvider(config, encoding, newObject)
+		return newRedisProvider(config, encoding, newObject, opts...)
 	case RedisClusterCache:
-		return newRedisClusterProvider(config, encoding, newObject)
+		return newRedisClusterProvider(config, encoding, newObject, opts...)
 	default:
+		if factory, ok := lookupBackend(config.Type); ok {
+			return factory(config, encoding, newObject, opts...)
+		}
+		if factory, ok := lookupStore(config.Type); ok {
+			return newStoreProvider(factory, config, encoding, newObject, opts...)
+		}
 		return nil, fmt.Errorf("不支持的缓存类型: %s", config.Type)
 	}
 }
 
 // newMemoryProvider 创建内存缓存提供者
-func newMemoryProvider(config *Config, encoding Encoding, newObject func() interface{}) (Provider, error) {
+func newMemoryProvider(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error) {
 	if config.Memory == nil {
 		config.Memory = defaultMemoryConfig()
 	}
 
 	// 创建内存缓存客户端
-	client := InitMemory(
-		WithNumCounters(config.Memory.NumCounters),
-		WithMaxCost(config.Memory.MaxCost),
-		WithBufferItems(config.Memory.BufferItems),
-	)
+	client, err := newMemoryStore(config.Memory, config.ReadYourWrites)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建内存缓存实例
 	cache := &memoryCache{
-		client:            client,
-		KeyPrefix:         config.KeyPrefix,
-		encoding:          encoding,
-		DefaultExpireTime: config.DefaultExpireTime,
-		newObject:         newObject,
+		client:       client,
+		KeyPrefix:    config.KeyPrefix,
+		cacheOptions: newCacheOptions(config, encoding, opts),
+		newObject:    newObject,
 	}
 
 	return &memoryProvider{
-		cache:  cache,
+		cache:  wrapCache(config, encoding, cache),
 		client: client,
+		locker: NewMemoryLocker(config.KeyPrefix, LockOptions{}),
 	}, nil
 }
 
+// newMemoryStore 根据内存缓存配置创建底层存储，readYourWrites为true时ristretto引擎使用二次机会缓存
+// 配置了NamespaceWeights时按命名空间创建分区存储
+func newMemoryStore(memConfig *MemoryConfig, readYourWrites bool) (MemoryStore, error) {
+	if len(memConfig.NamespaceWeights) > 0 {
+		return newPartitionedStore(memConfig, readYourWrites)
+	}
+	switch memConfig.Engine {
+	case "", MemoryEngineRistretto:
+		var client MemoryStore = InitMemory(
+			WithNumCounters(memConfig.NumCounters),
+			WithMaxCost(memConfig.MaxCost),
+			WithBufferItems(memConfig.BufferItems),
+		)
+		if memConfig.SecondChance != nil || readYourWrites {
+			client = NewSecondChanceStore(client, memConfig.SecondChance)
+		}
+		return client, nil
+	case MemoryEngineDeterministic:
+		return newDeterministicStore(memConfig.MaxCost), nil
+	case MemoryEngineLRU:
+		return newLRUStore(memConfig.Capacity), nil
+	default:
+		return nil, fmt.Errorf("不支持的内存缓存引擎: %s", memConfig.Engine)
+	}
+}
+
 // newRedisProvider 创建Redis缓存提供者
-func newRedisProvider(config *Config, encoding Encoding, newObject func() interface{}) (Provider, error) {
+func newRedisProvider(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error) {
 	if config.Redis == nil {
 		return nil, fmt.Errorf("Redis配置不能为空")
 	}
+	switch config.Redis.Network {
+	case "", "tcp", "unix":
+	default:
+		return nil, fmt.Errorf("不支持的Redis网络类型: %s", config.Redis.Network)
+	}
 
 	// 设置默认值
 	redisConfig := config.Redis
@@ -230,37 +547,53 @@ func newRedisProvider(config *Config, encoding Encoding, newObject func() interf
 		redisConfig.WriteTimeout = 3 * time.Second
 	}
 
-	// 创建Redis客户端
-	client := redis.NewClient(&redis.Options{
-		Addr:            redisConfig.Addr,
-		Password:        redisConfig.Password,
-		DB:              redisConfig.DB,
-		PoolSize:        redisConfig.PoolSize,
-		MinIdleConns:    redisConfig.MinIdleConns,
-		MaxIdleConns:    redisConfig.MaxIdleConns,
-		ConnMaxLifetime: redisConfig.ConnMaxLifetime,
-		DialTimeout:     redisConfig.DialTimeout,
-		ReadTimeout:     redisConfig.ReadTimeout,
-		WriteTimeout:    redisConfig.WriteTimeout,
-	})
-
-	// 创建Redis缓存实例
-	cache := &redisCache{
-		client:            client,
-		KeyPrefix:         config.KeyPrefix,
-		encoding:          encoding,
-		DefaultExpireTime: config.DefaultExpireTime,
-		newObject:         newObject,
+	provider := &redisProvider{
+		keyPrefix:   config.KeyPrefix,
+		probe:       newCompatProbe(config, newCacheOptions(config, encoding, opts)),
+		redisConfig: redisConfig,
+		newCache: func(client *redis.Client) Cache {
+			config.addCommandTraceHook(client)
+			return &redisCache{
+				client:       client,
+				KeyPrefix:    config.KeyPrefix,
+				cacheOptions: newCacheOptions(config, encoding, opts),
+				newObject:    newObject,
+			}
+		},
+		wrap: func(c Cache) Cache {
+			return wrapCache(config, encoding, c)
+		},
 	}
-
-	return &redisProvider{
-		cache:  cache,
-		client: client,
-	}, nil
+	provider.conn.init = func() Cache {
+		// 创建Redis客户端和缓存实例
+		client := redis.NewClient(redisConfig.options(redisConfig.DB))
+		provider.client = client
+		c := provider.newCache(client)
+		if config.Supervisor != nil {
+			provider.supervisor = newSupervisor(*config.Supervisor, redisConfig.DialTimeout, func(ctx context.Context) error {
+				return client.Ping(ctx).Err()
+			})
+			c = &supervisedCache{forwarder: forwarder{c}, supervisor: provider.supervisor}
+		}
+		if config.LoadShed != nil {
+			shedder := newLoadShedder(*config.LoadShed, provider.supervisor, func() (*redis.PoolStats, int) {
+				return client.PoolStats(), client.Options().PoolSize
+			})
+			c = &sheddingCache{forwarder: forwarder{c}, shedder: shedder}
+		}
+		return c
+	}
+	provider.cache = wrapCache(config, encoding, provider.conn.wrap(config.LazyConnect))
+	if config.VerifyOnStartup {
+		if err := verifyProvider(provider, redisConfig.DialTimeout); err != nil {
+			return nil, fmt.Errorf("Redis配置验证失败, 地址=%s: %w", redisConfig.address(), err)
+		}
+	}
+	return provider, nil
 }
 
 // newRedisClusterProvider 创建Redis集群缓存提供者
-func newRedisClusterProvider(config *Config, encoding Encoding, newObject func() interface{}) (Provider, error) {
+func newRedisClusterProvider(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error) {
 	if config.RedisCluster == nil {
 		return nil, fmt.Errorf("Redis集群配置不能为空")
 	}
@@ -290,32 +623,65 @@ func newRedisClusterProvider(config *Config, encoding Encoding, newObject func()
 		clusterConfig.WriteTimeout = 3 * time.Second
 	}
 
-	// 创建Redis集群客户端
-	client := redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:           clusterConfig.Addrs,
-		Password:        clusterConfig.Password,
-		PoolSize:        clusterConfig.PoolSize,
-		MinIdleConns:    clusterConfig.MinIdleConns,
-		MaxIdleConns:    clusterConfig.MaxIdleConns,
-		ConnMaxLifetime: clusterConfig.ConnMaxLifetime,
-		DialTimeout:     clusterConfig.DialTimeout,
-		ReadTimeout:     clusterConfig.ReadTimeout,
-		WriteTimeout:    clusterConfig.WriteTimeout,
-	})
+	provider := &redisClusterProvider{
+		keyPrefix:     config.KeyPrefix,
+		probe:         newCompatProbe(config, newCacheOptions(config, encoding, opts)),
+		clusterConfig: clusterConfig,
+		newCache: func(client *redis.ClusterClient) Cache {
+			config.addCommandTraceHook(client)
+			return &redisClusterCache{
+				client:       client,
+				KeyPrefix:    config.KeyPrefix,
+				cacheOptions: newCacheOptions(config, encoding, opts),
+				newObject:    newObject,
+			}
+		},
+		wrap: func(c Cache) Cache {
+			return wrapCache(config, encoding, c)
+		},
+	}
+	provider.conn.init = func() Cache {
+		// 创建Redis集群客户端和缓存实例
+		client := redis.NewClusterClient(clusterConfig.options())
+		provider.client = client
+		c := provider.newCache(client)
+		if config.Supervisor != nil {
+			provider.supervisor = newSupervisor(*config.Supervisor, clusterConfig.DialTimeout, provider.pingAll)
+			c = &supervisedCache{forwarder: forwarder{c}, supervisor: provider.supervisor}
+		}
+		if config.LoadShed != nil {
+			shedder := newLoadShedder(*config.LoadShed, provider.supervisor, func() (*redis.PoolStats, int) {
+				opts := client.Options()
+				return client.PoolStats(), opts.PoolSize * len(opts.Addrs)
+			})
+			c = &sheddingCache{forwarder: forwarder{c}, shedder: shedder}
+		}
+		return c
+	}
+	provider.cache = wrapCache(config, encoding, provider.conn.wrap(config.LazyConnect))
+	if config.VerifyOnStartup {
+		if err := verifyProvider(provider, clusterConfig.DialTimeout); err != nil {
+			return nil, fmt.Errorf("Redis集群配置验证失败, 地址=%v: %w", clusterConfig.Addrs, err)
+		}
+	}
+	return provider, nil
+}
 
-	// 创建Redis集群缓存实例
-	cache := &redisClusterCache{
-		client:            client,
-		KeyPrefix:         config.KeyPrefix,
-		encoding:          encoding,
-		DefaultExpireTime: config.DefaultExpireTime,
-		newObject:         newObject,
+// verifyProvider 在超时时间内建立连接，失败时关闭提供者
+func verifyProvider(provider Provider, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := provider.Connect(ctx); err != nil {
+		_ = provider.Close()
+		return err
 	}
+	return nil
+}
 
-	return &redisClusterProvider{
-		cache:  cache,
-		client: client,
-	}, nil
+// wrapCache 根据配置为缓存实例添加统计等功能
+func wrapCache(config *Config, encoding Encoding, c Cache) Cache {
+	c = WithWriteLimit(WithQuota(c, config.KeyPrefix, encoding, config.Quota), encoding, config.WriteLimit)
+	return WithStats(WithLatencyInjection(c, config.LatencyInjector), config.Type, config.Stats)
 }
 
 // defaultMemoryConfig 默认内存缓存配置
@@ -377,8 +743,8 @@ func DefaultRedisClusterConfig(addrs []string) *Config {
 }
 
 // SetupGlobalCache 设置全局缓存
-func SetupGlobalCache(config *Config, encoding Encoding, newObject func() interface{}) error {
-	provider, err := NewProvider(config, encoding, newObject)
+func SetupGlobalCache(config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) error {
+	provider, err := NewProvider(config, encoding, newObject, opts...)
 	if err != nil {
 		return fmt.Errorf("创建缓存提供者失败: %w", err)
 	}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrProxyUnsupported 当前代理模式下不支持该操作
+var ErrProxyUnsupported = errors.New("代理模式不支持该操作")
+
+// ProxyMode Redis代理模式，通过Twemproxy、Envoy等代理访问Redis时只使用代理支持的命令
+type ProxyMode string
+
+const (
+	// ProxyModeNone 直连Redis，不限制命令
+	ProxyModeNone ProxyMode = ""
+	// ProxyModeTwemproxy Twemproxy(nutcracker)
+	ProxyModeTwemproxy ProxyMode = "twemproxy"
+	// ProxyModeEnvoy Envoy Redis代理
+	ProxyModeEnvoy ProxyMode = "envoy"
+)
+
+// proxyUnsupportedCommands 各代理模式不支持的命令
+// MULTI、SCAN、发布订阅、SELECT和跨键的COPY、RENAME均不被支持，Twemproxy另外不支持较新的UNLINK和GETEX
+var proxyUnsupportedCommands = map[ProxyMode][]string{
+	ProxyModeTwemproxy: {"multi", "scan", "subscribe", "psubscribe", "select", "info", "command", "client", "copy", "rename", "unlink", "getex"},
+	ProxyModeEnvoy:     {"multi", "scan", "subscribe", "psubscribe", "select", "info", "command", "client", "copy", "rename"},
+}
+
+// supports 代理是否支持该命令
+func (m ProxyMode) supports(command string) bool {
+	for _, c := range proxyUnsupportedCommands[m] {
+		if strings.EqualFold(c, command) {
+			return false
+		}
+	}
+	return true
+}
+
+// check 检查代理是否支持该命令，不支持时返回ErrProxyUnsupported
+func (m ProxyMode) check(command string) error {
+	if m.supports(command) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s不支持%s", ErrProxyUnsupported, m, strings.ToUpper(command))
+}
+
+// proxyMode 获取当前缓存类型的代理模式，只有Redis单机配置支持代理模式
+func (c *Config) proxyMode() ProxyMode {
+	if c.Type == RedisCache && c.Redis != nil {
+		return c.Redis.ProxyMode
+	}
+	return ProxyModeNone
+}
+
+// validateProxyConfig 校验配置是否可以在代理模式下使用
+// 可以替换的命令(UNLINK改为DEL，GETEX改为GET和PEXPIRE)在运行时自动替换，无法替换的配置在这里拒绝
+func validateProxyConfig(config *Config) error {
+	if config.Type != RedisCache || config.Redis == nil {
+		return nil
+	}
+	redisConfig := config.Redis
+	mode := redisConfig.ProxyMode
+	switch mode {
+	case ProxyModeNone:
+		return nil
+	case ProxyModeTwemproxy, ProxyModeEnvoy:
+	default:
+		return fmt.Errorf("不支持的代理模式: %s", mode)
+	}
+
+	var errs []error
+	if redisConfig.DB != 0 {
+		errs = append(errs, fmt.Errorf("数据库索引必须为0: %w", mode.check("select")))
+	}
+	if len(redisConfig.TenantDBs) > 0 {
+		errs = append(errs, fmt.Errorf("不能配置TenantDBs: %w", mode.check("select")))
+	}
+	if redisConfig.Compat != nil && redisConfig.Compat.ProbeFeatures {
+		errs = append(errs, fmt.Errorf("不能开启ProbeFeatures: %w", mode.check("command")))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("代理模式%s配置错误: %w", mode, err)
+	}
+	return nil
+}
@@ -0,0 +1,486 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded 命名空间超出配额，拒绝写入新键
+var ErrQuotaExceeded = errors.New("命名空间超出配额")
+
+// quotaSweepInterval 超出配额时清除已过期键的最小间隔
+const quotaSweepInterval = time.Second
+
+// QuotaAction 命名空间超出配额时的处理方式
+type QuotaAction string
+
+const (
+	// QuotaAlert 只通过OnExceeded回调告警，默认值
+	QuotaAlert QuotaAction = "alert"
+	// QuotaReject 拒绝写入新键并返回ErrQuotaExceeded，已有键的覆盖写入和删除不受影响
+	QuotaReject QuotaAction = "reject"
+	// QuotaEvict 按写入顺序删除最早写入的键，直到回到配额内
+	QuotaEvict QuotaAction = "evict"
+)
+
+// QuotaConfig 命名空间软配额配置，命名空间为上下文中的键前缀(见WithKeyPrefix)，未设置时为缓存实例的键前缀
+// reject模式下按写入值编码后的实际大小预留，写入后会超出MaxBytes的新键同样被拒绝
+// 用于多个功能共享同一个缓存时，避免单个功能的异常写入占满整个缓存
+// 键数量和字节数只统计当前进程经过该缓存的写入，是近似值：其他进程的写入、标签失效和后端淘汰不会被统计，
+// GetOrSet回填按加载后的值估算；跟踪每个键需要少量内存，只设置MaxBytes时跟踪的键数量不受限制
+type QuotaConfig struct {
+	// MaxKeys 每个命名空间最多的键数量，0表示不限制
+	MaxKeys int64 `json:"max_keys,omitempty" yaml:"max_keys,omitempty"`
+	// MaxBytes 每个命名空间最多的字节数，按编码后的值大小估算，0表示不限制
+	MaxBytes int64 `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`
+	// Action 超出配额时的处理方式，alert(默认)、reject或evict
+	Action QuotaAction `json:"action,omitempty" yaml:"action,omitempty"`
+	// OnExceeded 命名空间超出配额时的回调，alert模式下每次超出只回调一次，回到配额内后重新计算
+	OnExceeded func(usage QuotaUsage) `json:"-" yaml:"-"`
+}
+
+// validate 校验配置
+func (c *QuotaConfig) validate() error {
+	if c.MaxKeys < 0 || c.MaxBytes < 0 {
+		return fmt.Errorf("配额不能为负数")
+	}
+	if c.MaxKeys == 0 && c.MaxBytes == 0 {
+		return fmt.Errorf("MaxKeys和MaxBytes至少设置一个")
+	}
+	switch c.Action {
+	case "", QuotaAlert, QuotaReject, QuotaEvict:
+		return nil
+	default:
+		return fmt.Errorf("不支持的配额处理方式: %s", c.Action)
+	}
+}
+
+// QuotaUsage 命名空间的用量
+type QuotaUsage struct {
+	// Namespace 上下文中的键前缀，未设置时为缓存实例的键前缀
+	Namespace string
+	// Keys 跟踪的键数量
+	Keys int64
+	// Bytes 跟踪的字节数
+	Bytes int64
+	// Action 本次采取的处理方式
+	Action QuotaAction
+}
+
+// quotaEntry 跟踪的键
+type quotaEntry struct {
+	key      string
+	size     int64
+	expireAt time.Time
+}
+
+// quotaNamespace 单个命名空间的用量，order按写入顺序排列，最早写入的在前
+type quotaNamespace struct {
+	entries map[string]*list.Element
+	order   *list.List
+	bytes   int64
+	alerted bool
+	swept   time.Time
+}
+
+// quotaCache 按命名空间统计用量并执行软配额的缓存
+type quotaCache struct {
+	forwarder
+	config    QuotaConfig
+	keyPrefix string
+	encoding  Encoding
+	mu        sync.Mutex
+	spaces    map[string]*quotaNamespace
+}
+
+// WithQuota 为缓存添加命名空间软配额，config为空时返回原缓存
+// keyPrefix为缓存实例的键前缀，上下文中没有键前缀时作为命名空间，因此两种写法写入的键计入同一个命名空间
+// encoding用于估算值的大小，未设置MaxBytes时不编码
+func WithQuota(c Cache, keyPrefix string, encoding Encoding, config *QuotaConfig) Cache {
+	if config == nil {
+		return c
+	}
+	cfg := *config
+	if cfg.Action == "" {
+		cfg.Action = QuotaAlert
+	}
+	return &quotaCache{forwarder: forwarder{c}, config: cfg, keyPrefix: keyPrefix, encoding: encoding, spaces: make(map[string]*quotaNamespace)}
+}
+
+// space 获取上下文对应的命名空间，调用方需要持有锁
+func (c *quotaCache) space(ctx context.Context) (string, *quotaNamespace) {
+	name := KeyPrefixFromContext(ctx, c.keyPrefix)
+	ns, ok := c.spaces[name]
+	if !ok {
+		ns = &quotaNamespace{entries: make(map[string]*list.Element), order: list.New()}
+		c.spaces[name] = ns
+	}
+	return name, ns
+}
+
+// sizeOf 估算值编码后的大小，每次写入只计算一次，同时用于预留和记录用量
+func (c *quotaCache) sizeOf(val interface{}) int64 {
+	if c.config.MaxBytes == 0 {
+		return 0
+	}
+	if raw, ok := rawBytes(val); ok {
+		return int64(len(raw))
+	}
+	if c.encoding == nil {
+		return 0
+	}
+	buf, err := c.encoding.Marshal(val)
+	if err != nil {
+		return 0
+	}
+	return int64(len(buf))
+}
+
+// overLimit 加上预留的键数量和字节数后用量是否超过上限
+func (c *quotaCache) overLimit(ns *quotaNamespace, keys, bytes int64) bool {
+	return (c.config.MaxKeys > 0 && int64(len(ns.entries))+keys > c.config.MaxKeys) ||
+		(c.config.MaxBytes > 0 && ns.bytes+bytes > c.config.MaxBytes)
+}
+
+// over 用量超过上限时清除已过期的键再判断，每秒最多清除一次
+func (c *quotaCache) over(ns *quotaNamespace, keys, bytes int64) bool {
+	if !c.overLimit(ns, keys, bytes) {
+		return false
+	}
+	if now := time.Now(); now.Sub(ns.swept) >= quotaSweepInterval {
+		ns.swept = now
+		for e := ns.order.Front(); e != nil; {
+			next := e.Next()
+			if entry := e.Value.(*quotaEntry); !entry.expireAt.IsZero() && now.After(entry.expireAt) {
+				ns.remove(e)
+			}
+			e = next
+		}
+	}
+	return c.overLimit(ns, keys, bytes)
+}
+
+// remove 移除跟踪的键
+func (ns *quotaNamespace) remove(e *list.Element) {
+	entry := ns.order.Remove(e).(*quotaEntry)
+	delete(ns.entries, entry.key)
+	ns.bytes -= entry.size
+}
+
+// usage 命名空间的用量
+func (ns *quotaNamespace) usage(name string, action QuotaAction) QuotaUsage {
+	return QuotaUsage{Namespace: name, Keys: int64(len(ns.entries)), Bytes: ns.bytes, Action: action}
+}
+
+// admit 判断是否允许写入，sizes为各个键编码后的大小
+// reject模式下写入包含新键且预留新键和字节数后超出配额时拒绝，只覆盖已有键时不受影响
+func (c *quotaCache) admit(ctx context.Context, sizes map[string]int64) error {
+	if c.config.Action != QuotaReject {
+		return nil
+	}
+	c.mu.Lock()
+	name, ns := c.space(ctx)
+	var fresh, bytes int64
+	for key, size := range sizes {
+		bytes += size
+		if e, ok := ns.entries[key]; ok {
+			bytes -= e.Value.(*quotaEntry).size
+		} else {
+			fresh++
+		}
+	}
+	if fresh == 0 || !c.over(ns, fresh, bytes) {
+		c.mu.Unlock()
+		return nil
+	}
+	usage := ns.usage(name, QuotaReject)
+	c.mu.Unlock()
+	if c.config.OnExceeded != nil {
+		c.config.OnExceeded(usage)
+	}
+	return fmt.Errorf("%w: 命名空间=%s, 键数量=%d, 字节数=%d", ErrQuotaExceeded, name, usage.Keys, usage.Bytes)
+}
+
+// record 记录写入的键，超出配额时按处理方式告警或删除最早写入的键
+func (c *quotaCache) record(ctx context.Context, sizes map[string]int64, ttl time.Duration) {
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	name, ns := c.space(ctx)
+	for key, size := range sizes {
+		if e, ok := ns.entries[key]; ok {
+			ns.remove(e)
+		}
+		ns.entries[key] = ns.order.PushBack(&quotaEntry{key: key, size: size, expireAt: expireAt})
+		ns.bytes += size
+	}
+	if !c.over(ns, 0, 0) {
+		ns.alerted = false
+		c.mu.Unlock()
+		return
+	}
+
+	var (
+		evicted []string
+		notify  bool
+	)
+	switch c.config.Action {
+	case QuotaEvict:
+		// 刚写入的键在末尾，至少保留一个
+		for c.overLimit(ns, 0, 0) && ns.order.Len() > 1 {
+			front := ns.order.Front()
+			evicted = append(evicted, front.Value.(*quotaEntry).key)
+			ns.remove(front)
+		}
+		notify = true
+	default:
+		notify = !ns.alerted
+		ns.alerted = true
+	}
+	usage := ns.usage(name, c.config.Action)
+	c.mu.Unlock()
+
+	if len(evicted) > 0 {
+		_ = c.Cache.Del(ctx, evicted...)
+	}
+	if notify && c.config.OnExceeded != nil {
+		c.config.OnExceeded(usage)
+	}
+}
+
+// forget 移除删除的键
+func (c *quotaCache) forget(ctx context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ns := c.space(ctx)
+	for _, key := range keys {
+		if e, ok := ns.entries[key]; ok {
+			ns.remove(e)
+		}
+	}
+}
+
+// Set 设置数据并记录用量
+func (c *quotaCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	sizes := map[string]int64{key: c.sizeOf(val)}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	if err := c.Cache.Set(ctx, key, val, expiration); err != nil {
+		return err
+	}
+	c.record(ctx, sizes, expiration)
+	return nil
+}
+
+// MultiSet 批量设置数据并记录用量，reject模式下只要包含新键且命名空间已满就拒绝整批写入
+func (c *quotaCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	sizes := make(map[string]int64, len(valueMap))
+	for key, val := range valueMap {
+		sizes[key] = c.sizeOf(val)
+	}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	err := c.Cache.MultiSet(ctx, valueMap, expiration)
+	var keyErrs MultiKeyError
+	switch {
+	case errors.As(err, &keyErrs):
+		// 写入失败的键不记录用量
+		for _, keyErr := range keyErrs {
+			delete(sizes, keyErr.Key)
+		}
+	case err != nil:
+		return err
+	}
+	c.record(ctx, sizes, expiration)
+	return err
+}
+
+// SetCacheWithNotFound 设置未找到的缓存并记录用量
+func (c *quotaCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	sizes := map[string]int64{key: int64(len(NotFoundPlaceholder))}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	if err := c.Cache.SetCacheWithNotFound(ctx, key, ttl...); err != nil {
+		return err
+	}
+	c.record(ctx, sizes, explicitNotFoundTTL(ttl))
+	return nil
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存并记录用量
+func (c *quotaCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	sizes := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		sizes[key] = int64(len(NotFoundPlaceholder))
+	}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	if err := c.forwarder.MultiSetCacheWithNotFound(ctx, keys); err != nil {
+		return err
+	}
+	c.record(ctx, sizes, 0)
+	return nil
+}
+
+// IncrWithTTL 原子自增并记录用量
+func (c *quotaCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	sizes := map[string]int64{key: 8}
+	if err := c.admit(ctx, sizes); err != nil {
+		return 0, err
+	}
+	n, err := c.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+	if err != nil {
+		return n, err
+	}
+	c.record(ctx, sizes, ttl)
+	return n, nil
+}
+
+// GetOrSet 读穿并记录用量，命中其他进程写入的键时同样开始跟踪
+func (c *quotaCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	err := c.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+	if err == nil {
+		c.record(ctx, map[string]int64{key: c.sizeOf(dest)}, ttl)
+	}
+	return err
+}
+
+// SetNX 仅在键不存在时设置数据，写入成功后记录用量
+func (c *quotaCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	sizes := map[string]int64{key: c.sizeOf(val)}
+	if err := c.admit(ctx, sizes); err != nil {
+		return false, err
+	}
+	ok, err := c.forwarder.SetNX(ctx, key, val, expiration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	c.record(ctx, sizes, expiration)
+	return true, nil
+}
+
+// SetWithTags 设置数据并加入标签，记录用量
+func (c *quotaCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	sizes := map[string]int64{key: c.sizeOf(val)}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	if err := c.forwarder.SetWithTags(ctx, key, val, expiration, tags...); err != nil {
+		return err
+	}
+	c.record(ctx, sizes, expiration)
+	return nil
+}
+
+// Copy 复制键到目标键，目标键按源键的大小记录用量
+func (c *quotaCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	c.mu.Lock()
+	_, ns := c.space(ctx)
+	var size int64
+	var ttl time.Duration
+	if e, ok := ns.entries[src]; ok {
+		entry := e.Value.(*quotaEntry)
+		size = entry.size
+		if preserveTTL && !entry.expireAt.IsZero() {
+			ttl = max(time.Until(entry.expireAt), time.Millisecond)
+		}
+	}
+	c.mu.Unlock()
+	sizes := map[string]int64{dst: size}
+	if err := c.admit(ctx, sizes); err != nil {
+		return err
+	}
+	if err := c.forwarder.Copy(ctx, src, dst, preserveTTL); err != nil {
+		return err
+	}
+	c.record(ctx, sizes, ttl)
+	return nil
+}
+
+// Rename 重命名键，用量转移到目标键
+func (c *quotaCache) Rename(ctx context.Context, src, dst string) error {
+	if err := c.forwarder.Rename(ctx, src, dst); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ns := c.space(ctx)
+	e, ok := ns.entries[src]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*quotaEntry)
+	ns.remove(e)
+	if old, ok := ns.entries[dst]; ok {
+		ns.remove(old)
+	}
+	ns.entries[dst] = ns.order.PushBack(&quotaEntry{key: dst, size: entry.size, expireAt: entry.expireAt})
+	ns.bytes += entry.size
+	return nil
+}
+
+// Expire 重设过期时间并更新跟踪的过期时间
+func (c *quotaCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.Cache.Expire(ctx, key, ttl); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ns := c.space(ctx)
+	if e, ok := ns.entries[key]; ok {
+		entry := e.Value.(*quotaEntry)
+		entry.expireAt = time.Time{}
+		if ttl > 0 {
+			entry.expireAt = time.Now().Add(ttl)
+		}
+	}
+	return nil
+}
+
+// Del 删除数据并移除用量
+func (c *quotaCache) Del(ctx context.Context, keys ...string) error {
+	c.forget(ctx, keys...)
+	return c.Cache.Del(ctx, keys...)
+}
+
+// DelWithTombstone 删除数据并移除用量，墓碑标记很快过期，不计入用量
+func (c *quotaCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	c.forget(ctx, key)
+	return c.forwarder.DelWithTombstone(ctx, key, ttl)
+}
+
+// DelDelayed 延迟双删并移除用量
+func (c *quotaCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	c.forget(ctx, key)
+	return c.forwarder.DelDelayed(ctx, key, delay)
+}
+
+// DelMany 分片批量删除大量键并移除用量
+func (c *quotaCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	c.forget(ctx, keys...)
+	return c.forwarder.DelMany(ctx, keys, opts)
+}
+
+// DelPattern 删除匹配模式的键并移除匹配的用量
+func (c *quotaCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	n, err := c.forwarder.DelPattern(ctx, pattern)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ns := c.space(ctx)
+	for key, e := range ns.entries {
+		if globMatch(pattern, key) {
+			ns.remove(e)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuotaReject(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  QuotaConfig
+		values  []string
+		wantErr []bool
+	}{
+		{"max keys", QuotaConfig{MaxKeys: 2}, []string{"a", "b", "c"}, []bool{false, false, true}},
+		// 每个值编码后为12字节，第二个值写入后会超出20字节
+		{"max bytes reserves real size", QuotaConfig{MaxBytes: 20}, []string{strings.Repeat("a", 10), strings.Repeat("b", 10)}, []bool{false, true}},
+		{"large first value", QuotaConfig{MaxBytes: 5}, []string{strings.Repeat("a", 10)}, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Action = QuotaReject
+			c := WithQuota(newTestMemoryCache(t), "test", &JSONEncoding{}, &tt.config)
+			ctx := context.Background()
+			for i, v := range tt.values {
+				key := string(rune('a' + i))
+				err := c.Set(ctx, key, &v, time.Minute)
+				if got := errors.Is(err, ErrQuotaExceeded); got != tt.wantErr[i] {
+					t.Errorf("Set(%s) error = %v, want ErrQuotaExceeded %v", key, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaOverwriteAllowed(t *testing.T) {
+	c := WithQuota(newTestMemoryCache(t), "test", &JSONEncoding{}, &QuotaConfig{MaxKeys: 1, Action: QuotaReject})
+	ctx := context.Background()
+	v := "v"
+	for i := 0; i < 2; i++ {
+		if err := c.Set(ctx, "k", &v, time.Minute); err != nil {
+			t.Fatalf("覆盖写入 Set() error = %v", err)
+		}
+	}
+	if err := c.Set(ctx, "other", &v, time.Minute); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Set(other) error = %v, want ErrQuotaExceeded", err)
+	}
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "other", &v, time.Minute); err != nil {
+		t.Errorf("删除后 Set(other) error = %v", err)
+	}
+}
+
+func TestQuotaNamespace(t *testing.T) {
+	var usages []QuotaUsage
+	c := WithQuota(newTestMemoryCache(t), "test", &JSONEncoding{}, &QuotaConfig{
+		MaxKeys:    1,
+		OnExceeded: func(usage QuotaUsage) { usages = append(usages, usage) },
+	})
+	v := "v"
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"default prefix", context.Background()},
+		// 上下文中的键前缀与缓存实例的键前缀相同时计入同一个命名空间
+		{"same prefix in context", WithKeyPrefix(context.Background(), "test")},
+		{"other prefix", WithKeyPrefix(context.Background(), "other")},
+	}
+	for i, tt := range tests {
+		if err := c.Set(tt.ctx, string(rune('a'+i)), &v, time.Minute); err != nil {
+			t.Fatalf("%s: Set() error = %v", tt.name, err)
+		}
+	}
+	if len(usages) != 1 || usages[0].Namespace != "test" || usages[0].Keys != 2 {
+		t.Errorf("OnExceeded = %+v, want one alert for namespace test with 2 keys", usages)
+	}
+}
+
+func TestQuotaEvict(t *testing.T) {
+	inner := newTestMemoryCache(t)
+	c := WithQuota(inner, "test", &JSONEncoding{}, &QuotaConfig{MaxKeys: 2, Action: QuotaEvict})
+	ctx := context.Background()
+	v := "v"
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, &v, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{"a", ErrCacheNotFound},
+		{"b", nil},
+		{"c", nil},
+	}
+	for _, tt := range tests {
+		var got string
+		if err := inner.Get(ctx, tt.key, &got); !errors.Is(err, tt.want) {
+			t.Errorf("Get(%s) error = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+}
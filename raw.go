@@ -0,0 +1,22 @@
+package cache
+
+// RawValue 已经是缓存编码格式的数据，所有Set直接写入而不调用Encoding.Marshal
+// 用于缓存上游接口返回的JSON等已序列化的数据，避免先解码再编码；数据格式需要与缓存的Encoding一致，
+// 否则Get解码到业务对象时失败。压缩、加密等包装编码同样被跳过，启用信封时仍会封装
+// 以RawValue或*RawValue传入均可，Get到*RawValue时直接返回原始数据，不调用Encoding.Unmarshal
+type RawValue []byte
+
+// rawBytes 值为RawValue或*RawValue时返回原始数据
+func rawBytes(v interface{}) ([]byte, bool) {
+	switch raw := v.(type) {
+	case RawValue:
+		return raw, true
+	case *RawValue:
+		if raw == nil {
+			return nil, true
+		}
+		return *raw, true
+	default:
+		return nil, false
+	}
+}
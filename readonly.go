@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrReadOnly 只读缓存视图拒绝写入和删除
+var ErrReadOnly = errors.New("缓存为只读")
+
+// ReadOnlyProvider 支持只读缓存视图的提供者
+type ReadOnlyProvider interface {
+	// ReadOnly 获取只读缓存视图，写入和删除返回ErrReadOnly
+	ReadOnly() Cache
+}
+
+// readOnlyCache 只读缓存视图，读取委托给内部缓存
+type readOnlyCache struct {
+	forwarder
+}
+
+// ReadOnly 包装缓存为只读视图，用于交给导出、分析等不允许修改缓存的组件
+// Set、MultiSet、Del等写入和删除操作返回ErrReadOnly
+func ReadOnly(c Cache) Cache {
+	if _, ok := c.(*readOnlyCache); ok {
+		return c
+	}
+	return &readOnlyCache{forwarder{c}}
+}
+
+// Set 拒绝写入
+func (c *readOnlyCache) Set(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
+	return ErrReadOnly
+}
+
+// MultiSet 拒绝写入
+func (c *readOnlyCache) MultiSet(_ context.Context, _ map[string]interface{}, _ time.Duration) error {
+	return ErrReadOnly
+}
+
+// Del 拒绝删除
+func (c *readOnlyCache) Del(_ context.Context, _ ...string) error {
+	return ErrReadOnly
+}
+
+// SetCacheWithNotFound 拒绝写入
+func (c *readOnlyCache) SetCacheWithNotFound(_ context.Context, _ string, _ ...time.Duration) error {
+	return ErrReadOnly
+}
+
+// MultiSetCacheWithNotFound 拒绝写入
+func (c *readOnlyCache) MultiSetCacheWithNotFound(_ context.Context, _ []string) error {
+	return ErrReadOnly
+}
+
+// DelWithTombstone 拒绝删除
+func (c *readOnlyCache) DelWithTombstone(_ context.Context, _ string, _ time.Duration) error {
+	return ErrReadOnly
+}
+
+// IncrWithTTL 拒绝写入
+func (c *readOnlyCache) IncrWithTTL(_ context.Context, _ string, _ int64, _ time.Duration) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+// DelMany 拒绝删除
+func (c *readOnlyCache) DelMany(_ context.Context, _ []string, _ DelManyOptions) error {
+	return ErrReadOnly
+}
+
+// DelDelayed 拒绝删除
+func (c *readOnlyCache) DelDelayed(_ context.Context, _ string, _ time.Duration) error {
+	return ErrReadOnly
+}
+
+// Expire 拒绝修改过期时间
+func (c *readOnlyCache) Expire(_ context.Context, _ string, _ time.Duration) error {
+	return ErrReadOnly
+}
+
+// SetNX 拒绝写入
+func (c *readOnlyCache) SetNX(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+	return false, ErrReadOnly
+}
+
+// SetWithTags 拒绝写入
+func (c *readOnlyCache) SetWithTags(_ context.Context, _ string, _ interface{}, _ time.Duration, _ ...string) error {
+	return ErrReadOnly
+}
+
+// InvalidateTag 拒绝删除
+func (c *readOnlyCache) InvalidateTag(_ context.Context, _ string) (*TagInvalidationReport, error) {
+	return nil, ErrReadOnly
+}
+
+// DelPattern 拒绝删除
+func (c *readOnlyCache) DelPattern(_ context.Context, _ string) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+// Copy 拒绝复制
+func (c *readOnlyCache) Copy(_ context.Context, _, _ string, _ bool) error {
+	return ErrReadOnly
+}
+
+// Rename 拒绝重命名
+func (c *readOnlyCache) Rename(_ context.Context, _, _ string) error {
+	return ErrReadOnly
+}
+
+// GetOrSet 拒绝读穿，未命中时需要回填缓存
+func (c *readOnlyCache) GetOrSet(_ context.Context, _ string, _ interface{}, _ time.Duration, _ Loader, _ ...GetOrSetOption) error {
+	return ErrReadOnly
+}
+
+// ReadOnly 获取内存缓存的只读视图
+func (p *memoryProvider) ReadOnly() Cache {
+	return ReadOnly(p.cache)
+}
+
+// ReadOnly 获取Redis缓存的只读视图
+func (p *redisProvider) ReadOnly() Cache {
+	return ReadOnly(p.cache)
+}
+
+// ReadOnly 获取存储缓存的只读视图
+func (p *storeProvider) ReadOnly() Cache {
+	return ReadOnly(p.cache)
+}
+
+// ReadOnly 获取Redis集群缓存的只读视图
+// 配置了RedisClusterConfig.ReplicaReads时使用单独的客户端从从节点读取，减轻主节点压力，读取可能略有延迟
+func (p *redisClusterProvider) ReadOnly() Cache {
+	if !p.clusterConfig.ReplicaReads {
+		return ReadOnly(p.cache)
+	}
+	if _, err := p.conn.ensure(); err != nil {
+		// 提供者已关闭，返回的视图在读取时报告关闭错误
+		return ReadOnly(p.cache)
+	}
+	p.replicaMu.Lock()
+	defer p.replicaMu.Unlock()
+	if p.replicaCache == nil {
+		opts := p.clusterConfig.options()
+		opts.ReadOnly = true
+		p.replicaClient = redis.NewClusterClient(opts)
+		p.replicaCache = ReadOnly(p.wrap(p.newCache(p.replicaClient)))
+	}
+	return p.replicaCache
+}
+
+// closeReplica 关闭从节点读取客户端
+func (p *redisClusterProvider) closeReplica() error {
+	p.replicaMu.Lock()
+	defer p.replicaMu.Unlock()
+	if p.replicaClient == nil {
+		return nil
+	}
+	err := p.replicaClient.Close()
+	p.replicaClient = nil
+	p.replicaCache = nil
+	return err
+}
@@ -12,159 +12,212 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// CacheNotFound 缓存未命中
-var CacheNotFound = redis.Nil
+// ErrCacheNotFound 缓存未命中，所有后端未命中时都返回该错误，可通过errors.Is判断
+// 包装了redis.Nil，原有的errors.Is(err, redis.Nil)判断仍然成立；数据已缓存为未找到占位符时返回的是ErrPlaceholder
+var ErrCacheNotFound error = cacheNotFoundError{}
+
+// CacheNotFound 缓存未命中，与ErrCacheNotFound相同
+//
+// Deprecated: 使用ErrCacheNotFound
+var CacheNotFound = ErrCacheNotFound
+
+// cacheNotFoundError 缓存未命中错误
+type cacheNotFoundError struct{}
+
+// Error 实现error接口
+func (cacheNotFoundError) Error() string {
+	return "缓存: 未命中"
+}
+
+// Unwrap 返回redis.Nil
+func (cacheNotFoundError) Unwrap() error {
+	return redis.Nil
+}
 
 // redisCache Redis缓存对象
 type redisCache struct {
-	client            *redis.Client
-	KeyPrefix         string
-	encoding          Encoding
-	DefaultExpireTime time.Duration
-	newObject         func() interface{}
+	client    *redis.Client
+	KeyPrefix string
+	newObject func() interface{}
+	cacheOptions
 }
 
 // NewRedisCache 创建新的缓存，client参数可以传入用于单元测试
-func NewRedisCache(client *redis.Client, keyPrefix string, encode Encoding, newObject func() interface{}) Cache {
+func NewRedisCache(client *redis.Client, keyPrefix string, encode Encoding, newObject func() interface{}, opts ...CacheOption) Cache {
 	return &redisCache{
-		client:    client,
-		KeyPrefix: keyPrefix,
-		encoding:  encode,
-		newObject: newObject,
+		client:       client,
+		KeyPrefix:    keyPrefix,
+		cacheOptions: newCacheOptions(&Config{}, encode, opts),
+		newObject:    newObject,
 	}
 }
 
 // Set 设置单个值
 func (c *redisCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
-	buf, err := Marshal(c.encoding, val)
+	buf, err := c.encode(val)
 	if err != nil {
-		return fmt.Errorf("编码错误: %v, 键=%s, 值=%+v ", err, key, val)
+		return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
 	}
 
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 	}
-	// 如果过期时间为0，使用默认过期时间
-	// if expiration == 0 {
-	//	expiration = DefaultExpireTime
-	// }
-	if len(buf) == 0 {
-		buf = NotFoundPlaceholderBytes
+	expiration, err = c.expiration(expiration, key)
+	if err != nil {
+		return err
+	}
+	if len(buf) == 0 && c.placeholderEnabled() {
+		buf = c.placeholderBytes()
 	}
 	err = c.client.Set(ctx, cacheKey, buf, expiration).Err()
 	if err != nil {
-		return fmt.Errorf("客户端设置错误: %v, 缓存键=%s", err, cacheKey)
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
 	}
+	c.sampleWrite(OpSet, cacheKey, len(buf), expiration)
 	return nil
 }
 
 // Get 获取单个值
 func (c *redisCache) Get(ctx context.Context, key string, val interface{}) error {
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if BypassFromContext(ctx) {
+		return ErrCacheNotFound
 	}
 
-	dataBytes, err := c.client.Get(ctx, cacheKey).Bytes()
-	// 注意：不处理redis值为nil的情况
-	// 而是留给上游处理
+	dataBytes, err := c.redisGet(ctx, c.client, cacheKey).Bytes()
 	if err != nil {
-		return err
+		// 未命中统一返回ErrCacheNotFound，它包装了redis.Nil，原有判断仍然成立
+		if errors.Is(err, redis.Nil) {
+			c.sampleRead(OpGet, cacheKey, nil, false)
+			return ErrCacheNotFound
+		}
+		return fmt.Errorf("%w: 客户端获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
 	}
+	c.sampleRead(OpGet, cacheKey, dataBytes, true)
 
+	if bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
+		return ErrTombstone
+	}
 	// 防止数据为空时Unmarshal报错
-	if len(dataBytes) == 0 || bytes.Equal(dataBytes, NotFoundPlaceholderBytes) {
+	if c.isPlaceholder(dataBytes) {
 		return ErrPlaceholder
 	}
-	err = Unmarshal(c.encoding, dataBytes, val)
+	needRewrite, err := c.decodeValue(dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("解码错误: %v, 键=%s, 缓存键=%s, 类型=%T, json=%s ",
-			err, key, cacheKey, val, dataBytes)
+		if isMiss(err) {
+			return err
+		}
+		return fmt.Errorf("%w: %w, 键=%s, 缓存键=%s, 类型=%T, json=%s ",
+			ErrDecode, err, key, cacheKey, val, dataBytes)
+	}
+	if err = c.redisValidate(ctx, c.client, key, cacheKey, val); err != nil {
+		return err
+	}
+	if needRewrite {
+		c.rewrite(ctx, cacheKey, val)
 	}
 	return nil
 }
 
-// MultiSet 设置多个值
+// MultiSet 设置多个值，任一值编码失败或构建缓存键失败时不写入
 func (c *redisCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
 	if len(valueMap) == 0 {
 		return nil
 	}
-	//if expiration == 0 {
-	//	expiration = DefaultExpireTime
-	//}
-
 	// 键值对成对出现，容量是map的两倍
 	paris := make([]interface{}, 0, 2*len(valueMap))
+	keys := make([]string, 0, len(valueMap))
 	for key, value := range valueMap {
-		buf, err := Marshal(c.encoding, value)
+		buf, err := c.encode(value)
 		if err != nil {
-			fmt.Printf("编码错误, %v, 值:%v\n", err, value)
-			continue
+			return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, value)
 		}
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 		if err != nil {
-			fmt.Printf("构建缓存键错误, %v, 键:%v\n", err, key)
-			continue
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 		}
 		paris = append(paris, []byte(cacheKey))
 		paris = append(paris, buf)
+		keys = append(keys, key)
 	}
-	pipeline := c.client.Pipeline()
-	err := pipeline.MSet(ctx, paris...).Err()
+	expiration, err := c.clamp(expiration, keys...)
 	if err != nil {
-		return fmt.Errorf("管道批量设置错误: %v", err)
-	}
-	for i := 0; i < len(paris); i = i + 2 {
-		switch paris[i].(type) {
-		case []byte:
-			pipeline.Expire(ctx, string(paris[i].([]byte)), expiration)
-		default:
-			fmt.Printf("redis过期不支持的键类型: %T\n", paris[i])
+		return err
+	}
+	if !c.pipelining {
+		if err = c.redisSetEach(ctx, c.client, paris, expiration); err != nil {
+			return err
 		}
+		c.samplePairs(OpMultiSet, paris, expiration)
+		return nil
+	}
+	pipeline := c.client.Pipeline()
+	err = pipeline.MSet(ctx, paris...).Err()
+	if err != nil {
+		return fmt.Errorf("%w: 管道批量设置错误: %w", ErrBackend, err)
+	}
+	// 过期时间为0表示永不过期，EXPIRE 0会立即删除键
+	for i := 0; expiration > 0 && i < len(paris); i = i + 2 {
+		pipeline.Expire(ctx, string(paris[i].([]byte)), c.jittered(expiration))
 	}
 	_, err = pipeline.Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("管道执行错误: %v", err)
+		return fmt.Errorf("%w: 管道执行错误: %w", ErrBackend, err)
 	}
+	c.samplePairs(OpMultiSet, paris, expiration)
 	return nil
 }
 
-// MultiGet 获取多个值
+// MultiGet 获取多个值，解码或校验失败的条目与内存缓存一致按未命中处理，不写入结果
 func (c *redisCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
-	if len(keys) == 0 {
+	if len(keys) == 0 || BypassFromContext(ctx) {
 		return nil
 	}
 	cacheKeys := make([]string, len(keys))
 	for index, key := range keys {
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 		if err != nil {
-			return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 		}
 		cacheKeys[index] = cacheKey
 	}
 	values, err := c.client.MGet(ctx, cacheKeys...).Result()
 	if err != nil {
-		return fmt.Errorf("客户端批量获取错误: %v, 键=%+v", err, cacheKeys)
+		return fmt.Errorf("%w: 客户端批量获取错误: %w, 键=%+v", ErrBackend, err, cacheKeys)
 	}
 
-	// 通过反射注入到map中
-	valueMap := reflect.ValueOf(value)
+	jobs := make([]decodeJob, 0, len(values))
 	for i, v := range values {
 		if v == nil {
+			c.sampleRead(OpMultiGet, cacheKeys[i], nil, false)
 			continue
 		}
 		dataBytes := []byte(v.(string))
-		if len(dataBytes) == 0 || bytes.Equal(dataBytes, NotFoundPlaceholderBytes) {
+		c.sampleRead(OpMultiGet, cacheKeys[i], dataBytes, true)
+		if c.isPlaceholder(dataBytes) || bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
 			continue
 		}
-		object := c.newObject()
-		err = Unmarshal(c.encoding, dataBytes, object)
-		if err != nil {
-			fmt.Printf("反序列化数据错误: %+v, 缓存键=%s 值类型=%T\n", err, cacheKeys[i], value)
+		jobs = append(jobs, decodeJob{key: cacheKeys[i], userKey: keys[i], data: dataBytes, object: c.acquire(c.newObject)})
+	}
+	c.decodeJobs(jobs, func(job *decodeJob) error {
+		if err := c.decode(job.data, job.object); err != nil {
+			return err
+		}
+		return c.redisValidate(ctx, c.client, job.userKey, job.key, job.object)
+	})
+
+	// 通过反射注入到map中
+	valueMap := reflect.ValueOf(value)
+	for _, job := range jobs {
+		if job.err != nil {
+			c.release(job.object)
 			continue
 		}
-		valueMap.SetMapIndex(reflect.ValueOf(cacheKeys[i]), reflect.ValueOf(object))
+		valueMap.SetMapIndex(reflect.ValueOf(job.key), reflect.ValueOf(job.object))
 	}
 	return nil
 }
@@ -175,29 +228,45 @@ func (c *redisCache) Del(ctx context.Context, keys ...string) error {
 		return nil
 	}
 
-	cacheKeys := make([]string, len(keys))
-	for index, key := range keys {
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	// 跳过无效的键，避免空键被发送到Redis
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	if len(cacheKeys) > 0 {
+		err := c.redisDel(ctx, c.client, cacheKeys...).Err()
 		if err != nil {
-			continue
+			return errors.Join(fmt.Errorf("%w: 客户端删除错误: %w, 键=%+v", ErrBackend, err, cacheKeys), keyErrs.errOrNil())
 		}
-		cacheKeys[index] = cacheKey
 	}
-	err := c.client.Del(ctx, cacheKeys...).Err()
-	if err != nil {
-		return fmt.Errorf("客户端删除错误: %v, 键=%+v", err, cacheKeys)
-	}
-	return nil
+	return keyErrs.errOrNil()
 }
 
 // SetCacheWithNotFound 为未找到的情况设置值
-func (c *redisCache) SetCacheWithNotFound(ctx context.Context, key string) error {
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+func (c *redisCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if !c.placeholderEnabled() {
+		return nil
 	}
 
-	return c.client.Set(ctx, cacheKey, NotFoundPlaceholder, DefaultNotFoundExpireTime).Err()
+	expiration := explicitNotFoundTTL(ttl)
+	if expiration == 0 {
+		expiration = c.redisNotFoundExpiration(ctx, c.client, cacheKey)
+	}
+	err = c.client.Set(ctx, cacheKey, c.placeholderBytes(), expiration).Err()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
+
+// rewrite 使用主编码重写条目并保留剩余过期时间，失败时忽略
+func (c *redisCache) rewrite(ctx context.Context, cacheKey string, val interface{}) {
+	buf, err := c.encode(val)
+	if err != nil || len(buf) == 0 {
+		return
+	}
+	c.client.SetArgs(ctx, cacheKey, buf, redis.SetArgs{KeepTTL: true})
 }
 
 // BuildCacheKey 使用前缀构造缓存键
@@ -218,74 +287,94 @@ func BuildCacheKey(keyPrefix string, key string) (string, error) {
 
 // redisClusterCache Redis集群缓存对象
 type redisClusterCache struct {
-	client            *redis.ClusterClient
-	KeyPrefix         string
-	encoding          Encoding
-	DefaultExpireTime time.Duration
-	newObject         func() interface{}
+	client    *redis.ClusterClient
+	KeyPrefix string
+	newObject func() interface{}
+	cacheOptions
 }
 
 // NewRedisClusterCache 创建新的集群缓存
-func NewRedisClusterCache(client *redis.ClusterClient, keyPrefix string, encode Encoding, newObject func() interface{}) Cache {
+func NewRedisClusterCache(client *redis.ClusterClient, keyPrefix string, encode Encoding, newObject func() interface{}, opts ...CacheOption) Cache {
 	return &redisClusterCache{
-		client:    client,
-		KeyPrefix: keyPrefix,
-		encoding:  encode,
-		newObject: newObject,
+		client:       client,
+		KeyPrefix:    keyPrefix,
+		cacheOptions: newCacheOptions(&Config{}, encode, opts),
+		newObject:    newObject,
 	}
 }
 
 // Set 设置单个值
 func (c *redisClusterCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
-	buf, err := Marshal(c.encoding, val)
+	buf, err := c.encode(val)
 	if err != nil {
-		return fmt.Errorf("编码错误: %v, 键=%s, 值=%+v ", err, key, val)
+		return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
 	}
 
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 	}
-	//if expiration == 0 {
-	//	expiration = DefaultExpireTime
-	//}
-	if len(buf) == 0 {
-		buf = NotFoundPlaceholderBytes
+	expiration, err = c.expiration(expiration, key)
+	if err != nil {
+		return err
+	}
+	if len(buf) == 0 && c.placeholderEnabled() {
+		buf = c.placeholderBytes()
 	}
 	err = c.client.Set(ctx, cacheKey, buf, expiration).Err()
 	if err != nil {
-		return fmt.Errorf("客户端设置错误: %v, 缓存键=%s", err, cacheKey)
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
 	}
+	c.sampleWrite(OpSet, cacheKey, len(buf), expiration)
 	return nil
 }
 
 // Get 获取单个值
 func (c *redisClusterCache) Get(ctx context.Context, key string, val interface{}) error {
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if BypassFromContext(ctx) {
+		return ErrCacheNotFound
 	}
 
-	dataBytes, err := c.client.Get(ctx, cacheKey).Bytes()
-	// NOTE: don't handle the case where redis value is nil
-	// 但留给上游处理
+	dataBytes, err := c.redisGet(ctx, c.client, cacheKey).Bytes()
 	if err != nil {
-		return err
+		// 未命中统一返回ErrCacheNotFound，它包装了redis.Nil，原有判断仍然成立
+		if errors.Is(err, redis.Nil) {
+			c.sampleRead(OpGet, cacheKey, nil, false)
+			return ErrCacheNotFound
+		}
+		return fmt.Errorf("%w: 客户端获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
 	}
+	c.sampleRead(OpGet, cacheKey, dataBytes, true)
 
+	if bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
+		return ErrTombstone
+	}
 	// 防止数据为空时Unmarshal报错
-	if len(dataBytes) == 0 || bytes.Equal(dataBytes, NotFoundPlaceholderBytes) {
+	if c.isPlaceholder(dataBytes) {
 		return ErrPlaceholder
 	}
-	err = Unmarshal(c.encoding, dataBytes, val)
+	needRewrite, err := c.decodeValue(dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("解码错误: %v, 键=%s, 缓存键=%s, 类型=%T, json=%s ",
-			err, key, cacheKey, val, dataBytes)
+		if isMiss(err) {
+			return err
+		}
+		return fmt.Errorf("%w: %w, 键=%s, 缓存键=%s, 类型=%T, json=%s ",
+			ErrDecode, err, key, cacheKey, val, dataBytes)
+	}
+	if err = c.redisValidate(ctx, c.client, key, cacheKey, val); err != nil {
+		return err
+	}
+	if needRewrite {
+		c.rewrite(ctx, cacheKey, val)
 	}
 	return nil
 }
 
-// MultiSet 设置多个值
+// MultiSet 设置多个值，任一值编码失败或构建缓存键失败时不写入
 func (c *redisClusterCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
 	if len(valueMap) == 0 {
 		return nil
@@ -293,75 +382,94 @@ func (c *redisClusterCache) MultiSet(ctx context.Context, valueMap map[string]in
 
 	// 键值对成对出现，容量是map的两倍
 	paris := make([]interface{}, 0, 2*len(valueMap))
+	keys := make([]string, 0, len(valueMap))
 	for key, value := range valueMap {
-		buf, err := Marshal(c.encoding, value)
+		buf, err := c.encode(value)
 		if err != nil {
-			fmt.Printf("编码错误, %v, 值:%v\n", err, value)
-			continue
+			return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, value)
 		}
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 		if err != nil {
-			fmt.Printf("构建缓存键错误, %v, 键:%v\n", err, key)
-			continue
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 		}
 		paris = append(paris, []byte(cacheKey))
 		paris = append(paris, buf)
+		keys = append(keys, key)
 	}
-	pipeline := c.client.Pipeline()
-	err := pipeline.MSet(ctx, paris...).Err()
+	expiration, err := c.clamp(expiration, keys...)
 	if err != nil {
-		return fmt.Errorf("管道批量设置错误: %v", err)
-	}
-	for i := 0; i < len(paris); i = i + 2 {
-		switch paris[i].(type) {
-		case []byte:
-			pipeline.Expire(ctx, string(paris[i].([]byte)), expiration)
-		default:
-			fmt.Printf("redis过期不支持的键类型: %T\n", paris[i])
+		return err
+	}
+	if !c.pipelining {
+		if err = c.redisSetEach(ctx, c.client, paris, expiration); err != nil {
+			return err
 		}
+		c.samplePairs(OpMultiSet, paris, expiration)
+		return nil
+	}
+	pipeline := c.client.Pipeline()
+	err = pipeline.MSet(ctx, paris...).Err()
+	if err != nil {
+		return fmt.Errorf("%w: 管道批量设置错误: %w", ErrBackend, err)
+	}
+	// 过期时间为0表示永不过期，EXPIRE 0会立即删除键
+	for i := 0; expiration > 0 && i < len(paris); i = i + 2 {
+		pipeline.Expire(ctx, string(paris[i].([]byte)), c.jittered(expiration))
 	}
 	_, err = pipeline.Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("管道执行错误: %v", err)
+		return fmt.Errorf("%w: 管道执行错误: %w", ErrBackend, err)
 	}
+	c.samplePairs(OpMultiSet, paris, expiration)
 	return nil
 }
 
-// MultiGet 获取多个值
+// MultiGet 获取多个值，解码或校验失败的条目与内存缓存一致按未命中处理，不写入结果
 func (c *redisClusterCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
-	if len(keys) == 0 {
+	if len(keys) == 0 || BypassFromContext(ctx) {
 		return nil
 	}
 	cacheKeys := make([]string, len(keys))
 	for index, key := range keys {
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
 		if err != nil {
-			return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
 		}
 		cacheKeys[index] = cacheKey
 	}
 	values, err := c.client.MGet(ctx, cacheKeys...).Result()
 	if err != nil {
-		return fmt.Errorf("客户端批量获取错误: %v, 键=%+v", err, cacheKeys)
+		return fmt.Errorf("%w: 客户端批量获取错误: %w, 键=%+v", ErrBackend, err, cacheKeys)
 	}
 
-	// 通过反射注入到map中
-	valueMap := reflect.ValueOf(value)
+	jobs := make([]decodeJob, 0, len(values))
 	for i, v := range values {
 		if v == nil {
+			c.sampleRead(OpMultiGet, cacheKeys[i], nil, false)
 			continue
 		}
 		dataBytes := []byte(v.(string))
-		if len(dataBytes) == 0 || bytes.Equal(dataBytes, NotFoundPlaceholderBytes) {
+		c.sampleRead(OpMultiGet, cacheKeys[i], dataBytes, true)
+		if c.isPlaceholder(dataBytes) || bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
 			continue
 		}
-		object := c.newObject()
-		err = Unmarshal(c.encoding, dataBytes, object)
-		if err != nil {
-			fmt.Printf("反序列化数据错误: %+v, 缓存键=%s 类型=%T\n", err, cacheKeys[i], value)
+		jobs = append(jobs, decodeJob{key: cacheKeys[i], userKey: keys[i], data: dataBytes, object: c.acquire(c.newObject)})
+	}
+	c.decodeJobs(jobs, func(job *decodeJob) error {
+		if err := c.decode(job.data, job.object); err != nil {
+			return err
+		}
+		return c.redisValidate(ctx, c.client, job.userKey, job.key, job.object)
+	})
+
+	// 通过反射注入到map中
+	valueMap := reflect.ValueOf(value)
+	for _, job := range jobs {
+		if job.err != nil {
+			c.release(job.object)
 			continue
 		}
-		valueMap.SetMapIndex(reflect.ValueOf(cacheKeys[i]), reflect.ValueOf(object))
+		valueMap.SetMapIndex(reflect.ValueOf(job.key), reflect.ValueOf(job.object))
 	}
 	return nil
 }
@@ -372,27 +480,43 @@ func (c *redisClusterCache) Del(ctx context.Context, keys ...string) error {
 		return nil
 	}
 
-	cacheKeys := make([]string, len(keys))
-	for index, key := range keys {
-		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	// 跳过无效的键，避免空键被发送到Redis
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	if len(cacheKeys) > 0 {
+		err := c.redisDel(ctx, c.client, cacheKeys...).Err()
 		if err != nil {
-			continue
+			return errors.Join(fmt.Errorf("%w: 客户端删除错误: %w, 键=%+v", ErrBackend, err, cacheKeys), keyErrs.errOrNil())
 		}
-		cacheKeys[index] = cacheKey
 	}
-	err := c.client.Del(ctx, cacheKeys...).Err()
-	if err != nil {
-		return fmt.Errorf("客户端删除错误: %v, 键=%+v", err, cacheKeys)
-	}
-	return nil
+	return keyErrs.errOrNil()
 }
 
 // SetCacheWithNotFound 为未找到的情况设置值
-func (c *redisClusterCache) SetCacheWithNotFound(ctx context.Context, key string) error {
-	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+func (c *redisClusterCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if !c.placeholderEnabled() {
+		return nil
+	}
+
+	expiration := explicitNotFoundTTL(ttl)
+	if expiration == 0 {
+		expiration = c.redisNotFoundExpiration(ctx, c.client, cacheKey)
+	}
+	err = c.client.Set(ctx, cacheKey, c.placeholderBytes(), expiration).Err()
 	if err != nil {
-		return fmt.Errorf("构建缓存键错误: %v, 键=%s", err, key)
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
 	}
+	return nil
+}
 
-	return c.client.Set(ctx, cacheKey, NotFoundPlaceholder, DefaultNotFoundExpireTime).Err()
+// rewrite 使用主编码重写条目并保留剩余过期时间，失败时忽略
+func (c *redisClusterCache) rewrite(ctx context.Context, cacheKey string, val interface{}) {
+	buf, err := c.encode(val)
+	if err != nil || len(buf) == 0 {
+		return
+	}
+	c.client.SetArgs(ctx, cacheKey, buf, redis.SetArgs{KeepTTL: true})
 }
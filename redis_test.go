@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// missHook 不访问网络，所有命令都返回redis.Nil
+type missHook struct{}
+
+func (missHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("missHook不建立连接")
+	}
+}
+
+func (missHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		cmd.SetErr(redis.Nil)
+		return redis.Nil
+	}
+}
+
+func (missHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			cmd.SetErr(redis.Nil)
+		}
+		return redis.Nil
+	}
+}
+
+// countingCollector 记录各类计数的统计收集器
+type countingCollector struct {
+	mu                 sync.Mutex
+	hits, misses, errs int
+}
+
+func (c *countingCollector) IncrHit(CacheType, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+}
+
+func (c *countingCollector) IncrMiss(CacheType, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.misses++
+}
+
+func (c *countingCollector) ObserveLatency(CacheType, string, time.Duration) {}
+
+func (c *countingCollector) IncrError(CacheType, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs++
+}
+
+func TestRedisGetMissReturnsErrCacheNotFound(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	client.AddHook(missHook{})
+	defer client.Close()
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{"127.0.0.1:0"}})
+	cluster.AddHook(missHook{})
+	defer cluster.Close()
+
+	newObject := func() interface{} { return new(string) }
+	backends := map[string]Cache{
+		"redis":   NewRedisCache(client, "test", &JSONEncoding{}, newObject),
+		"cluster": NewRedisClusterCache(cluster, "test", &JSONEncoding{}, newObject),
+	}
+	for name, c := range backends {
+		t.Run(name, func(t *testing.T) {
+			collector := &countingCollector{}
+			stats := WithStats(c, RedisCache, collector)
+
+			var val string
+			err := stats.Get(context.Background(), "missing", &val)
+			if !errors.Is(err, ErrCacheNotFound) {
+				t.Fatalf("Get() error = %v, want ErrCacheNotFound", err)
+			}
+			if !errors.Is(err, redis.Nil) {
+				t.Errorf("Get() error = %v, want to wrap redis.Nil", err)
+			}
+			if collector.misses != 1 || collector.errs != 0 {
+				t.Errorf("misses = %d, errors = %d, want 1 miss and no errors", collector.misses, collector.errs)
+			}
+		})
+	}
+}
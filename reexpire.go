@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reexpireBatchSize 每批SCAN和PEXPIRE的键数量
+const reexpireBatchSize = 500
+
+// ReexpireReport 批量重设过期时间的报告
+type ReexpireReport struct {
+	// Nodes 扫描的节点数量
+	Nodes int
+	// Matched 匹配的键数量
+	Matched int64
+	// Updated 成功设置过期时间的键数量
+	Updated int64
+	// Duration 耗时
+	Duration time.Duration
+}
+
+// Reexpirer 支持批量重设过期时间的提供者，由Redis单机和集群提供者实现
+type Reexpirer interface {
+	// Reexpire 重设提供者键前缀下匹配pattern的键的过期时间
+	Reexpire(ctx context.Context, pattern string, newTTL, jitter time.Duration) (*ReexpireReport, error)
+}
+
+// Reexpire 扫描匹配pattern的键并分批重设过期时间为newTTL加上[0, jitter)的随机抖动
+// 用于修改过期策略后调整已有数据，集群模式下扫描每个主节点
+func Reexpire(ctx context.Context, client redis.UniversalClient, pattern string, newTTL, jitter time.Duration) (*ReexpireReport, error) {
+	if pattern == "" {
+		return nil, errors.New("匹配模式不能为空")
+	}
+	if newTTL <= 0 {
+		return nil, fmt.Errorf("过期时间必须大于0: %s", newTTL)
+	}
+
+	start := time.Now()
+	report := &ReexpireReport{}
+	var mu sync.Mutex
+	reexpireNode := func(ctx context.Context, node *redis.Client) error {
+		matched, updated, err := reexpireNodeKeys(ctx, node, pattern, newTTL, jitter)
+		mu.Lock()
+		defer mu.Unlock()
+		report.Nodes++
+		report.Matched += matched
+		report.Updated += updated
+		return err
+	}
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, reexpireNode)
+	case *redis.Client:
+		err = reexpireNode(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", client)
+	}
+	report.Duration = time.Since(start)
+	if err != nil {
+		return report, fmt.Errorf("%w: 重设过期时间错误: %w", ErrBackend, err)
+	}
+	return report, nil
+}
+
+// reexpireNodeKeys 扫描并重设单个节点上匹配的键的过期时间
+func reexpireNodeKeys(ctx context.Context, node *redis.Client, pattern string, newTTL, jitter time.Duration) (matched, updated int64, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = node.Scan(ctx, cursor, pattern, reexpireBatchSize).Result()
+		if err != nil {
+			return matched, updated, err
+		}
+		matched += int64(len(keys))
+
+		if len(keys) > 0 {
+			pipe := node.Pipeline()
+			for _, key := range keys {
+				ttl := newTTL
+				if jitter > 0 {
+					ttl += time.Duration(rand.Int63n(int64(jitter)))
+				}
+				pipe.PExpire(ctx, key, ttl)
+			}
+			cmds, execErr := pipe.Exec(ctx)
+			if execErr != nil {
+				return matched, updated, execErr
+			}
+			for _, cmd := range cmds {
+				// 键在扫描后被删除时PEXPIRE返回false
+				if cmd.(*redis.BoolCmd).Val() {
+					updated++
+				}
+			}
+		}
+
+		if cursor == 0 {
+			return matched, updated, nil
+		}
+	}
+}
+
+// Reexpire 重设Redis键前缀下匹配pattern的键的过期时间
+func (p *redisProvider) Reexpire(ctx context.Context, pattern string, newTTL, jitter time.Duration) (*ReexpireReport, error) {
+	if err := p.redisConfig.ProxyMode.check("scan"); err != nil {
+		return nil, err
+	}
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Reexpire(ctx, p.client, namespacePattern(p.keyPrefix, pattern), newTTL, jitter)
+}
+
+// Reexpire 重设Redis集群键前缀下匹配pattern的键的过期时间
+func (p *redisClusterProvider) Reexpire(ctx context.Context, pattern string, newTTL, jitter time.Duration) (*ReexpireReport, error) {
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Reexpire(ctx, p.client, namespacePattern(p.keyPrefix, pattern), newTTL, jitter)
+}
+
+// namespacePattern 将相对于键前缀的匹配模式转换为完整模式
+func namespacePattern(keyPrefix, pattern string) string {
+	if keyPrefix == "" {
+		return pattern
+	}
+	return escapeGlob(keyPrefix) + ":" + pattern
+}
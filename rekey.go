@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RekeyOptions 迁移键前缀的选项
+type RekeyOptions struct {
+	// BatchSize 每批SCAN和迁移的键数量，默认500
+	BatchSize int
+	// RateLimit 每个节点每秒最多迁移的键数量，0表示不限制
+	RateLimit int
+	// Overwrite 新前缀下已存在同名键时覆盖，默认保留新键，新键通常是新版本写入的较新数据
+	Overwrite bool
+	// KeepOld 迁移后保留旧键，用于滚动发布期间旧版本仍读取旧前缀的场景，默认删除旧键
+	KeepOld bool
+	// DryRun 演练模式，只扫描并统计匹配的键，不做迁移
+	DryRun bool
+}
+
+// setDefaults 设置默认值
+func (o *RekeyOptions) setDefaults() {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+}
+
+// RekeyReport 迁移键前缀的报告
+type RekeyReport struct {
+	// DryRun 是否为演练模式
+	DryRun bool
+	// Nodes 扫描的节点数量
+	Nodes int
+	// Matched 旧前缀下匹配的键数量
+	Matched int64
+	// Migrated 成功写入新前缀的键数量
+	Migrated int64
+	// Skipped 未迁移的键数量，包括新键已存在且未设置Overwrite，以及扫描后被删除或过期的键
+	Skipped int64
+	// Deleted 删除的旧键数量
+	Deleted int64
+	// Duration 耗时
+	Duration time.Duration
+}
+
+// Rekeyer 支持迁移键前缀的提供者，由Redis单机和集群提供者实现
+type Rekeyer interface {
+	// Rekey 把oldPrefix下的所有键迁移到newPrefix
+	Rekey(ctx context.Context, oldPrefix, newPrefix string, opts RekeyOptions) (*RekeyReport, error)
+}
+
+// Rekey 把oldPrefix下的所有键分批迁移到newPrefix，保留剩余过期时间，用于调整键前缀规范时不需要清空缓存
+// 使用DUMP和RESTORE迁移，标签集合等非字符串类型同样保留；标签集合中记录的仍是旧前缀的缓存键
+// 集群模式下扫描每个主节点，新键可能位于其他节点
+func Rekey(ctx context.Context, client redis.UniversalClient, oldPrefix, newPrefix string, opts RekeyOptions) (*RekeyReport, error) {
+	if oldPrefix == "" {
+		return nil, errors.New("旧键前缀为空，拒绝迁移整个数据库")
+	}
+	if oldPrefix == newPrefix {
+		return nil, fmt.Errorf("新旧键前缀相同: %s", oldPrefix)
+	}
+	if strings.HasPrefix(newPrefix, oldPrefix+":") {
+		// 新键同样匹配旧前缀，会被重复扫描和迁移
+		return nil, fmt.Errorf("新键前缀不能位于旧键前缀下: %s", newPrefix)
+	}
+	opts.setDefaults()
+
+	start := time.Now()
+	report := &RekeyReport{DryRun: opts.DryRun}
+	pattern := escapeGlob(oldPrefix) + ":*"
+	var mu sync.Mutex
+	rekeyNode := func(ctx context.Context, node *redis.Client) error {
+		r, err := rekeyNodeKeys(ctx, client, node, pattern, oldPrefix+":", newPrefix, opts)
+		mu.Lock()
+		defer mu.Unlock()
+		report.Nodes++
+		report.Matched += r.Matched
+		report.Migrated += r.Migrated
+		report.Skipped += r.Skipped
+		report.Deleted += r.Deleted
+		return err
+	}
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, rekeyNode)
+	case *redis.Client:
+		err = rekeyNode(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", client)
+	}
+	report.Duration = time.Since(start)
+	if err != nil {
+		return report, fmt.Errorf("%w: 迁移键前缀错误: %w", ErrBackend, err)
+	}
+	return report, nil
+}
+
+// rekeyNodeKeys 扫描单个节点上旧前缀的键并逐批迁移，新键通过client写入
+func rekeyNodeKeys(ctx context.Context, client redis.UniversalClient, node *redis.Client, pattern, oldPrefix, newPrefix string, opts RekeyOptions) (report RekeyReport, err error) {
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = node.Scan(ctx, cursor, pattern, int64(opts.BatchSize)).Result()
+		if err != nil {
+			return report, err
+		}
+		report.Matched += int64(len(keys))
+
+		if !opts.DryRun && len(keys) > 0 {
+			batchStart := time.Now()
+			if err = rekeyBatch(ctx, client, node, keys, oldPrefix, newPrefix, opts, &report); err != nil {
+				return report, err
+			}
+			if opts.RateLimit > 0 {
+				wait := time.Duration(len(keys))*time.Second/time.Duration(opts.RateLimit) - time.Since(batchStart)
+				if wait > 0 {
+					select {
+					case <-ctx.Done():
+						return report, ctx.Err()
+					case <-time.After(wait):
+					}
+				}
+			}
+		}
+
+		if cursor == 0 {
+			return report, nil
+		}
+	}
+}
+
+// rekeyBatch 迁移一批键：在节点上读取序列化数据和剩余过期时间，写入新键后删除旧键
+func rekeyBatch(ctx context.Context, client redis.UniversalClient, node *redis.Client, keys []string, oldPrefix, newPrefix string, opts RekeyOptions, report *RekeyReport) error {
+	dumpCmds := make([]*redis.StringCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	_, err := node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			dumpCmds[i] = pipe.Dump(ctx, key)
+			ttlCmds[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	// 扫描后被删除的键DUMP返回nil，已过期的键PTTL返回-2
+	restoreKeys := make([]string, 0, len(keys))
+	restoreCmds := make([]*redis.StatusCmd, 0, len(keys))
+	// 各命令的错误在下面逐个检查
+	_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			data, dumpErr := dumpCmds[i].Result()
+			ttl := ttlCmds[i].Val()
+			if dumpErr != nil || ttl == -2 {
+				report.Skipped++
+				continue
+			}
+			if ttl < 0 {
+				ttl = 0
+			}
+			newKey, keyErr := BuildCacheKey(newPrefix, strings.TrimPrefix(key, oldPrefix))
+			if keyErr != nil {
+				report.Skipped++
+				continue
+			}
+			if opts.Overwrite {
+				restoreCmds = append(restoreCmds, pipe.RestoreReplace(ctx, newKey, ttl, data))
+			} else {
+				restoreCmds = append(restoreCmds, pipe.Restore(ctx, newKey, ttl, data))
+			}
+			restoreKeys = append(restoreKeys, key)
+		}
+		return nil
+	})
+	if len(restoreCmds) == 0 {
+		return nil
+	}
+
+	// 新键已存在时RESTORE返回BUSYKEY，旧键同样视为已迁移
+	done := make([]string, 0, len(restoreKeys))
+	for i, cmd := range restoreCmds {
+		switch cmdErr := cmd.Err(); {
+		case cmdErr == nil:
+			report.Migrated++
+			done = append(done, restoreKeys[i])
+		case strings.HasPrefix(cmdErr.Error(), "BUSYKEY"):
+			report.Skipped++
+			done = append(done, restoreKeys[i])
+		default:
+			return cmdErr
+		}
+	}
+	if opts.KeepOld || len(done) == 0 {
+		return nil
+	}
+
+	// 逐个删除，避免集群模式下跨槽错误
+	delCmds := make([]*redis.IntCmd, len(done))
+	_, err = node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range done {
+			delCmds[i] = pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	for _, cmd := range delCmds {
+		report.Deleted += cmd.Val()
+	}
+	return err
+}
+
+// Rekey 把Redis中oldPrefix下的所有键迁移到newPrefix
+func (p *redisProvider) Rekey(ctx context.Context, oldPrefix, newPrefix string, opts RekeyOptions) (*RekeyReport, error) {
+	if err := p.redisConfig.ProxyMode.check("scan"); err != nil {
+		return nil, err
+	}
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Rekey(ctx, p.client, oldPrefix, newPrefix, opts)
+}
+
+// Rekey 把Redis集群中oldPrefix下的所有键迁移到newPrefix
+func (p *redisClusterProvider) Rekey(ctx context.Context, oldPrefix, newPrefix string, opts RekeyOptions) (*RekeyReport, error) {
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+	return Rekey(ctx, p.client, oldPrefix, newPrefix, opts)
+}
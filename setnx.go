@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StoreSetNXer 支持仅在键不存在时写入的存储，写入成功时返回true
+type StoreSetNXer interface {
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// memorySetNXMu 内存缓存SetNX锁，ristretto本身不支持原子的检查并写入
+var memorySetNXMu sync.Mutex
+
+// Exists 键是否存在，未找到占位符和墓碑标记同样视为存在
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	_, ok := m.client.Get(cacheKey)
+	return ok, nil
+}
+
+// SetNX 仅在键不存在时设置数据，写入成功时返回true
+// 只与同一进程内的SetNX互斥，与并发的Set之间不保证原子性
+func (m *memoryCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	buf, err := m.encode(val)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	if len(buf) == 0 && m.placeholderEnabled() {
+		buf = m.placeholderBytes()
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	expiration, err = m.expiration(expiration, key)
+	if err != nil {
+		return false, err
+	}
+
+	memorySetNXMu.Lock()
+	defer memorySetNXMu.Unlock()
+
+	if _, ok := m.client.Get(cacheKey); ok {
+		return false, nil
+	}
+	if !m.client.SetWithTTL(cacheKey, buf, 0, expiration) {
+		return false, fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
+	}
+	m.client.Wait()
+	m.sampleWrite(OpSetNX, cacheKey, len(buf), expiration)
+	return true, nil
+}
+
+// Exists 键是否存在，未找到占位符和墓碑标记同样视为存在
+func (c *redisCache) Exists(ctx context.Context, key string) (bool, error) {
+	return redisExists(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// SetNX 仅在键不存在时设置数据，写入成功时返回true
+func (c *redisCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	return c.redisSetNX(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, val, expiration)
+}
+
+// Exists 键是否存在，未找到占位符和墓碑标记同样视为存在
+func (c *redisClusterCache) Exists(ctx context.Context, key string) (bool, error) {
+	return redisExists(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+}
+
+// SetNX 仅在键不存在时设置数据，写入成功时返回true
+func (c *redisClusterCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	return c.redisSetNX(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, val, expiration)
+}
+
+// redisExists 使用EXISTS判断键是否存在
+func redisExists(ctx context.Context, client redis.Cmdable, keyPrefix, key string) (bool, error) {
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	n, err := client.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: 客户端查询键错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return n > 0, nil
+}
+
+// redisSetNX 使用SET NX PX写入
+func (o *cacheOptions) redisSetNX(ctx context.Context, client redis.Cmdable, keyPrefix, key string, val interface{}, expiration time.Duration) (bool, error) {
+	buf, err := o.encode(val)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	if len(buf) == 0 && o.placeholderEnabled() {
+		buf = o.placeholderBytes()
+	}
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	expiration, err = o.expiration(expiration, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := client.SetNX(ctx, cacheKey, buf, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	if ok {
+		o.sampleWrite(OpSetNX, cacheKey, len(buf), expiration)
+	}
+	return ok, nil
+}
+
+// Exists 键是否存在，未找到占位符和墓碑标记同样视为存在
+func (c *storeCache) Exists(ctx context.Context, key string) (bool, error) {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if _, err = c.store.Get(ctx, cacheKey); err != nil {
+		if isMiss(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: 存储获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return true, nil
+}
+
+// SetNX 仅在键不存在时设置数据，存储未实现StoreSetNXer时返回ErrNotSupported
+func (c *storeCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	setter, ok := c.store.(StoreSetNXer)
+	if !ok {
+		return false, fmt.Errorf("%w: SetNX", ErrNotSupported)
+	}
+	buf, err := c.encode(val)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	if len(buf) == 0 && c.placeholderEnabled() {
+		buf = c.placeholderBytes()
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	expiration, err = c.expiration(expiration, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err = setter.SetNX(ctx, cacheKey, buf, expiration)
+	if err != nil {
+		return false, fmt.Errorf("%w: 存储设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	if ok {
+		c.sampleWrite(OpSetNX, cacheKey, len(buf), expiration)
+	}
+	return ok, nil
+}
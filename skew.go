@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// SourceTimestamped 携带数据源更新时间的值
+// 启用信封时，写入实现了该接口的值会把更新时间(如数据库的updated_at)记录到信封头部，供写入偏差检测使用
+type SourceTimestamped interface {
+	SourceUpdatedAt() time.Time
+}
+
+// sourceUpdatedAt 获取值的数据源更新时间，值未实现SourceTimestamped时返回零值
+func sourceUpdatedAt(v interface{}) time.Time {
+	if ts, ok := v.(SourceTimestamped); ok {
+		return ts.SourceUpdatedAt()
+	}
+	// 方法定义在指针接收者上而值不是指针时取地址再判断
+	if v != nil && !isPointer(v) {
+		ptr := reflect.New(reflect.TypeOf(v))
+		ptr.Elem().Set(reflect.ValueOf(v))
+		if ts, ok := ptr.Interface().(SourceTimestamped); ok {
+			return ts.SourceUpdatedAt()
+		}
+	}
+	return time.Time{}
+}
+
+// SkewResult 写入偏差检测结果
+type SkewResult struct {
+	// CacheKey 实际的缓存键，包含前缀
+	CacheKey string
+	// Cached 缓存中记录的数据源更新时间
+	Cached time.Time
+	// Source 数据源当前的更新时间
+	Source time.Time
+	// Skew 数据源比缓存新的时长，小于等于0表示缓存是最新的
+	Skew time.Duration
+	// Stale 偏差是否超过容忍时间，即缓存持有旧数据
+	Stale bool
+	// Err 检测失败的原因，如加载函数返回错误或panic(包装ErrLoaderPanic)，不为空时只有CacheKey和Cached有效
+	Err error
+}
+
+// SkewReportFunc 写入偏差检测结果上报函数，通常用于记录指标，如按Stale统计旧数据比例、记录Skew分布
+// 注意：该函数在后台goroutine中调用，必须是线程安全的
+type SkewReportFunc func(result SkewResult)
+
+// skewCheck 写入偏差检测配置
+type skewCheck struct {
+	sampleRate float64
+	tolerance  time.Duration
+	report     SkewReportFunc
+}
+
+// WithSkewCheck 开启写入偏差检测模式
+// GetOrSet命中缓存时按sampleRate比例在后台再调用一次加载函数，比较缓存信封中记录的数据源更新时间与加载结果的更新时间，
+// 通过report上报结果，用于发现失效遗漏导致的旧数据；检测不修改缓存，也不影响GetOrSet的返回
+// 需要启用信封且缓存的值实现SourceTimestamped，否则不会检测；每次检测额外读取一次条目元数据并访问一次数据源
+func WithSkewCheck(sampleRate float64, tolerance time.Duration, report SkewReportFunc) CacheOption {
+	return func(o *cacheOptions) {
+		if report == nil || sampleRate <= 0 {
+			return
+		}
+		o.skew = &skewCheck{
+			sampleRate: sampleRate,
+			tolerance:  max(tolerance, 0),
+			report:     report,
+		}
+	}
+}
+
+// verify 对命中的键抽样检测写入偏差，在后台执行，不受ctx取消的影响
+func (s *skewCheck) verify(ctx context.Context, c Cache, key string, loader Loader) {
+	if rand.Float64() >= s.sampleRate {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		info, err := Extend(c).Describe(ctx, key)
+		if err != nil || info.Kind != EntryValue || info.SourceUpdatedAt.IsZero() {
+			return
+		}
+		val, err := s.load(ctx, loader)
+		switch {
+		case errors.Is(err, ErrNotFound) || (err == nil && val == nil):
+			// 数据源中已不存在，缓存持有旧数据
+			s.report(SkewResult{CacheKey: info.CacheKey, Cached: info.SourceUpdatedAt, Stale: true})
+			return
+		case err != nil:
+			// 加载失败，无法比较更新时间
+			s.report(SkewResult{CacheKey: info.CacheKey, Cached: info.SourceUpdatedAt, Err: err})
+			return
+		}
+		source := sourceUpdatedAt(val)
+		if source.IsZero() {
+			return
+		}
+		skew := source.Sub(info.SourceUpdatedAt)
+		s.report(SkewResult{
+			CacheKey: info.CacheKey,
+			Cached:   info.SourceUpdatedAt,
+			Source:   source,
+			Skew:     skew,
+			Stale:    skew > s.tolerance,
+		})
+	}()
+}
+
+// load 调用加载函数，加载函数panic时返回包装ErrLoaderPanic的错误，不会传播到后台goroutine之外
+func (s *skewCheck) load(ctx context.Context, loader Loader) (val interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			val, err = nil, fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+	}()
+	return loader(ctx)
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stampedValue 记录数据源更新时间的测试值
+type stampedValue struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (v *stampedValue) SourceUpdatedAt() time.Time {
+	return v.UpdatedAt
+}
+
+func TestSkewCheckLoaderFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  Loader
+		wantErr error
+		stale   bool
+	}{
+		{"panic", func(context.Context) (interface{}, error) { panic("boom") }, ErrLoaderPanic, false},
+		{"error", func(context.Context) (interface{}, error) { return nil, context.DeadlineExceeded }, context.DeadlineExceeded, false},
+		{"not found", func(context.Context) (interface{}, error) { return nil, ErrNotFound }, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make(chan SkewResult, 1)
+			c := newTestMemoryCache(t, WithSkewCheck(1, 0, func(result SkewResult) { results <- result }))
+			c.(*memoryCache).envelope = true
+			ctx := context.Background()
+			if err := c.Set(ctx, "k", &stampedValue{Name: "v", UpdatedAt: time.Now()}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			var got stampedValue
+			if err := c.GetOrSet(ctx, "k", &got, time.Minute, tt.loader); err != nil || got.Name != "v" {
+				t.Fatalf("GetOrSet() = %+v, %v, want v", got, err)
+			}
+			select {
+			case result := <-results:
+				if !errors.Is(result.Err, tt.wantErr) || (tt.wantErr == nil && result.Err != nil) {
+					t.Errorf("Err = %v, want %v", result.Err, tt.wantErr)
+				}
+				if result.Stale != tt.stale || result.CacheKey != "test:k" {
+					t.Errorf("result = %+v, want Stale=%v CacheKey=test:k", result, tt.stale)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("未上报检测结果")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithSlidingTTL 设置滑动过期时间，每次成功Get都将键的过期时间刷新为ttl
+// Redis使用GETEX在读取的同时原子刷新，占位符和墓碑标记同样会被刷新；内存缓存仅在解码成功后刷新
+// 适用于会话等按访问续期的场景
+func WithSlidingTTL(ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.slidingTTL = ttl
+	}
+}
+
+// redisGet 读取Redis中的值，设置了滑动过期时间时使用GETEX同时刷新过期时间
+// 代理不支持GETEX时先GET再PEXPIRE，两条命令不是原子的，刷新失败时忽略
+func (o *cacheOptions) redisGet(ctx context.Context, client redis.Cmdable, cacheKey string) *redis.StringCmd {
+	if o.slidingTTL > 0 {
+		if !o.proxy.supports("getex") {
+			cmd := client.Get(ctx, cacheKey)
+			if cmd.Err() == nil {
+				_ = client.PExpire(ctx, cacheKey, o.slidingTTL).Err()
+			}
+			return cmd
+		}
+		return client.GetEx(ctx, cacheKey, o.slidingTTL)
+	}
+	return client.Get(ctx, cacheKey)
+}
+
+// touch 刷新内存缓存条目的过期时间，未设置滑动过期时间时不做处理
+func (m *memoryCache) touch(cacheKey string, data []byte) {
+	if m.slidingTTL <= 0 {
+		return
+	}
+	m.client.SetWithTTL(cacheKey, data, 0, m.slidingTTL)
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SlowLogMonitorConfig 慢查询监控配置
+type SlowLogMonitorConfig struct {
+	// Interval 轮询间隔，默认10秒
+	Interval time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Count 每个节点每次读取的慢查询条数，默认128，轮询间隔内的慢查询超过该数量时较早的条目不会回调
+	Count int64 `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+// setDefaults 设置默认值
+func (c *SlowLogMonitorConfig) setDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Count <= 0 {
+		c.Count = 128
+	}
+}
+
+// SlowLogAlert 参数中包含键前缀的慢查询
+type SlowLogAlert struct {
+	// Addr 节点地址
+	Addr string
+	// KeyPrefix 监控的键前缀
+	KeyPrefix string
+	// Entry 慢查询条目
+	Entry redis.SlowLog
+}
+
+// SlowLogMonitor 慢查询监控，定期读取Redis的SLOWLOG，参数中包含键前缀的新条目通过回调通知
+// 用于把Redis变慢快速归因到使用该键前缀的服务，集群模式下监控每个主节点
+type SlowLogMonitor struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	config    SlowLogMonitorConfig
+	handler   func(ctx context.Context, alert SlowLogAlert)
+	onError   func(err error)
+	// lastIDs 各节点已处理的最大慢查询ID
+	lastIDs map[string]int64
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSlowLogMonitor 创建慢查询监控，keyPrefix为空时所有慢查询都会回调
+// handler在监控协程中顺序调用，不应长时间阻塞
+func NewSlowLogMonitor(client redis.UniversalClient, keyPrefix string, config SlowLogMonitorConfig, handler func(ctx context.Context, alert SlowLogAlert)) *SlowLogMonitor {
+	config.setDefaults()
+	return &SlowLogMonitor{
+		client:    client,
+		keyPrefix: keyPrefix,
+		config:    config,
+		handler:   handler,
+		lastIDs:   make(map[string]int64),
+	}
+}
+
+// OnError 设置错误回调，如读取慢查询失败
+func (m *SlowLogMonitor) OnError(fn func(err error)) *SlowLogMonitor {
+	m.onError = fn
+	return m
+}
+
+// Start 开始监控，启动前已有的慢查询不会回调
+func (m *SlowLogMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return nil
+	}
+	// 第一次读取只记录各节点的最大ID
+	if err := m.poll(ctx, false); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.wg.Add(1)
+	go m.run(ctx)
+	return nil
+}
+
+// run 按间隔轮询
+func (m *SlowLogMonitor) run(ctx context.Context) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.poll(ctx, true); err != nil && m.onError != nil {
+				m.onError(err)
+			}
+		}
+	}
+}
+
+// poll 读取所有节点的慢查询，notify为false时只更新已处理的最大ID
+func (m *SlowLogMonitor) poll(ctx context.Context, notify bool) error {
+	var alerts []SlowLogAlert
+	var mu sync.Mutex
+	visit := func(ctx context.Context, node *redis.Client) error {
+		addr := node.Options().Addr
+		logs, err := node.SlowLogGet(ctx, m.config.Count).Result()
+		if err != nil {
+			return fmt.Errorf("读取慢查询错误: %w, 地址=%s", err, addr)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, m.newEntries(addr, logs, notify)...)
+		return nil
+	}
+
+	var err error
+	switch c := m.client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, visit)
+	case *redis.Client:
+		err = visit(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的Redis客户端类型: %T", m.client)
+	}
+	for _, alert := range alerts {
+		m.handler(ctx, alert)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBackend, err)
+	}
+	return nil
+}
+
+// newEntries 筛选节点上ID大于已处理最大ID且包含键前缀的慢查询，按时间顺序返回
+// 第一次读取到的节点只记录最大ID；SLOWLOG RESET或节点重启后ID从0开始，最新ID小于已处理最大ID时视为全部是新条目
+func (m *SlowLogMonitor) newEntries(addr string, logs []redis.SlowLog, notify bool) []SlowLogAlert {
+	lastID, seen := m.lastIDs[addr]
+	if len(logs) == 0 {
+		if !seen {
+			m.lastIDs[addr] = -1
+		}
+		return nil
+	}
+	if logs[0].ID < lastID {
+		lastID = -1
+	}
+	m.lastIDs[addr] = logs[0].ID
+	if !notify || !seen {
+		return nil
+	}
+	prefix := ""
+	if m.keyPrefix != "" {
+		prefix = m.keyPrefix + ":"
+	}
+	var alerts []SlowLogAlert
+	// SLOWLOG GET按从新到旧返回
+	for i := len(logs) - 1; i >= 0; i-- {
+		if logs[i].ID > lastID && slowLogTouches(logs[i], prefix) {
+			alerts = append(alerts, SlowLogAlert{Addr: addr, KeyPrefix: m.keyPrefix, Entry: logs[i]})
+		}
+	}
+	return alerts
+}
+
+// Close 停止监控
+func (m *SlowLogMonitor) Close() error {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.cancel = nil
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		m.wg.Wait()
+	}
+	return nil
+}
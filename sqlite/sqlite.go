@@ -0,0 +1,372 @@
+// Package sqlite 基于SQLite单文件的缓存后端，使用WAL模式并在后台定期清理过期数据
+// 适用于需要持久化但没有缓存服务器的桌面、命令行和边缘应用，导入该包即注册sqlite缓存类型:
+//
+//	import _ "github.com/smart-unicom/cache/sqlite"
+//
+//	config := &cache.Config{
+//		Type:  sqlite.CacheType,
+//		Extra: map[string]string{"path": "/var/lib/app/cache.db", "sweep_interval": "1m"},
+//	}
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/smart-unicom/cache"
+)
+
+// CacheType SQLite缓存类型
+const CacheType cache.CacheType = "sqlite"
+
+const (
+	// defaultSweepInterval 默认的过期数据清理间隔
+	defaultSweepInterval = time.Minute
+	// defaultBusyTimeout 默认的锁等待时间
+	defaultBusyTimeout = 5 * time.Second
+	// maxBatchKeys 单条语句最多绑定的键数量，低于SQLite默认的参数上限
+	maxBatchKeys = 500
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS cache_entries (
+	key       TEXT PRIMARY KEY,
+	value     BLOB NOT NULL,
+	expire_at INTEGER NOT NULL DEFAULT 0
+) WITHOUT ROWID;
+CREATE INDEX IF NOT EXISTS cache_entries_expire_at ON cache_entries (expire_at) WHERE expire_at > 0;`
+
+// upsertSQL 写入或覆盖条目
+const upsertSQL = `INSERT INTO cache_entries (key, value, expire_at) VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expire_at = excluded.expire_at`
+
+// insertSQL 仅在键不存在或已过期时写入条目
+const insertSQL = `INSERT INTO cache_entries (key, value, expire_at) VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expire_at = excluded.expire_at
+WHERE cache_entries.expire_at > 0 AND cache_entries.expire_at <= ?`
+
+func init() {
+	cache.RegisterStore(CacheType, newStoreFromConfig)
+}
+
+// Options SQLite存储配置
+type Options struct {
+	// Path 数据库文件路径，":memory:"表示内存数据库
+	Path string
+	// SweepInterval 过期数据的清理间隔，默认1分钟，负数表示不清理，过期数据仍会在读取时被忽略
+	SweepInterval time.Duration
+	// BusyTimeout 等待其他连接释放写锁的时间，默认5秒
+	BusyTimeout time.Duration
+}
+
+// Store SQLite存储，实现cache.Store和io.Closer
+type Store struct {
+	db   *sql.DB
+	now  func() time.Time
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var (
+	_ cache.Store            = (*Store)(nil)
+	_ cache.StoreMultiGetter = (*Store)(nil)
+	_ cache.StoreMultiSetter = (*Store)(nil)
+	_ cache.StoreIncrementer = (*Store)(nil)
+	_ cache.StoreSetNXer     = (*Store)(nil)
+)
+
+// Open 打开SQLite存储，文件不存在时自动创建
+func Open(opts Options) (*Store, error) {
+	if opts.Path == "" {
+		return nil, errors.New("SQLite文件路径不能为空")
+	}
+	if opts.SweepInterval == 0 {
+		opts.SweepInterval = defaultSweepInterval
+	}
+	if opts.BusyTimeout <= 0 {
+		opts.BusyTimeout = defaultBusyTimeout
+	}
+
+	db, err := sql.Open("sqlite", dsn(opts))
+	if err != nil {
+		return nil, fmt.Errorf("打开SQLite文件失败: %w", err)
+	}
+	if opts.Path == ":memory:" {
+		// 每个连接都是独立的内存数据库，只能使用单个连接
+		db.SetMaxOpenConns(1)
+	}
+	if _, err = db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("初始化SQLite表失败: %w", err)
+	}
+
+	s := &Store{db: db, now: time.Now, stop: make(chan struct{})}
+	if opts.SweepInterval > 0 {
+		s.wg.Add(1)
+		go s.sweepLoop(opts.SweepInterval)
+	}
+	return s, nil
+}
+
+// NewCache 打开SQLite存储并创建缓存，使用完毕后需要关闭返回的存储
+func NewCache(opts Options, keyPrefix string, encode cache.Encoding, newObject func() interface{}, cacheOpts ...cache.CacheOption) (cache.Cache, *Store, error) {
+	s, err := Open(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache.NewStoreCache(s, keyPrefix, encode, newObject, cacheOpts...), s, nil
+}
+
+// newStoreFromConfig 根据Config.Extra创建存储
+// 支持的配置: path(必填)、sweep_interval、busy_timeout
+func newStoreFromConfig(config *cache.Config) (cache.Store, error) {
+	opts := Options{Path: config.ExtraString("path")}
+	for name, target := range map[string]*time.Duration{
+		"sweep_interval": &opts.SweepInterval,
+		"busy_timeout":   &opts.BusyTimeout,
+	} {
+		v := config.ExtraString(name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("无效的%s: %w", name, err)
+		}
+		*target = d
+	}
+	return Open(opts)
+}
+
+// dsn 构造连接字符串，启用WAL模式，写事务立即获取写锁以避免升级锁时的死锁
+func dsn(opts Options) string {
+	q := url.Values{}
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "synchronous(NORMAL)")
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", opts.BusyTimeout.Milliseconds()))
+	q.Set("_txlock", "immediate")
+	return "file:" + opts.Path + "?" + q.Encode()
+}
+
+// Close 停止后台清理并关闭数据库
+func (s *Store) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		s.wg.Wait()
+		err = s.db.Close()
+	})
+	return err
+}
+
+// sweepLoop 定期删除过期数据
+func (s *Store) sweepLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_, _ = s.Sweep(context.Background())
+		}
+	}
+}
+
+// Sweep 立即删除过期数据，返回删除的条目数量
+func (s *Store) Sweep(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM cache_entries WHERE expire_at > 0 AND expire_at <= ?`, s.nowMillis())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// nowMillis 当前时间的Unix毫秒
+func (s *Store) nowMillis() int64 {
+	return s.now().UnixMilli()
+}
+
+// expireAt 计算过期的Unix毫秒，0表示永不过期
+func (s *Store) expireAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return s.now().Add(ttl).UnixMilli()
+}
+
+// Get 获取值，已过期但尚未清理的数据视为不存在
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT value FROM cache_entries WHERE key = ? AND (expire_at = 0 OR expire_at > ?)`,
+		key, s.nowMillis()).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cache.ErrCacheNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetWithTTL 获取值和剩余过期时间，永不过期时ttl为0
+func (s *Store) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	var value []byte
+	var expireAt int64
+	now := s.nowMillis()
+	err := s.db.QueryRowContext(ctx,
+		`SELECT value, expire_at FROM cache_entries WHERE key = ? AND (expire_at = 0 OR expire_at > ?)`,
+		key, now).Scan(&value, &expireAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, cache.ErrCacheNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if expireAt == 0 {
+		return value, 0, nil
+	}
+	return value, time.Duration(expireAt-now) * time.Millisecond, nil
+}
+
+// Set 设置值
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.db.ExecContext(ctx, upsertSQL, key, value, s.expireAt(ttl))
+	return err
+}
+
+// SetNX 仅在键不存在(包括已过期)时设置值，写入成功时返回true
+func (s *Store) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	res, err := s.db.ExecContext(ctx, insertSQL, key, value, s.expireAt(ttl), s.nowMillis())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Del 删除值
+func (s *Store) Del(ctx context.Context, keys ...string) error {
+	for start := 0; start < len(keys); start += maxBatchKeys {
+		end := min(start+maxBatchKeys, len(keys))
+		query, args := inClause(`DELETE FROM cache_entries WHERE key IN `, keys[start:end])
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiGet 批量获取
+func (s *Store) MultiGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	now := s.nowMillis()
+	for start := 0; start < len(keys); start += maxBatchKeys {
+		end := min(start+maxBatchKeys, len(keys))
+		query, args := inClause(`SELECT key, value FROM cache_entries WHERE (expire_at = 0 OR expire_at > ?) AND key IN `, keys[start:end])
+		rows, err := s.db.QueryContext(ctx, query, append([]any{now}, args...)...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var (
+				key   string
+				value []byte
+			)
+			if err = rows.Scan(&key, &value); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			values[key] = value
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// MultiSet 批量设置，在同一个事务中写入
+func (s *Store) MultiSet(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, upsertSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	expireAt := s.expireAt(ttl)
+	for key, value := range values {
+		if _, err = stmt.ExecContext(ctx, key, value, expireAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// IncrWithTTL 原子自增，仅在键新建(包括已过期)时设置过期时间
+func (s *Store) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		data     []byte
+		expireAt int64
+		v        = delta
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT value, expire_at FROM cache_entries WHERE key = ? AND (expire_at = 0 OR expire_at > ?)`,
+		key, s.nowMillis()).Scan(&data, &expireAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		expireAt = s.expireAt(ttl)
+	case err != nil:
+		return 0, err
+	default:
+		current, err := cache.ParseCounter(data)
+		if err != nil {
+			return 0, err
+		}
+		v = current + delta
+	}
+
+	_, err = tx.ExecContext(ctx, upsertSQL, key, []byte(fmt.Sprint(v)), expireAt)
+	if err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// inClause 构造IN子句及其参数
+func inClause(prefix string, keys []string) (string, []any) {
+	args := make([]any, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+	return prefix + "(" + strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",") + ")", args
+}
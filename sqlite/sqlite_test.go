@@ -0,0 +1,189 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/cache"
+)
+
+// newTestStore 创建内存数据库存储，返回可推进的时钟
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	s, err := Open(Options{Path: ":memory:", SweepInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestStoreExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		advance time.Duration
+		found   bool
+		wantTTL time.Duration
+	}{
+		{"永不过期", 0, time.Hour, true, 0},
+		{"未过期", time.Minute, 20 * time.Second, true, 40 * time.Second},
+		{"刚好过期", time.Minute, time.Minute, false, 0},
+		{"已过期", time.Minute, time.Hour, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, now := newTestStore(t)
+			ctx := context.Background()
+			if err := s.Set(ctx, "k", []byte("v"), tt.ttl); err != nil {
+				t.Fatal(err)
+			}
+			*now = now.Add(tt.advance)
+
+			val, ttl, err := s.GetWithTTL(ctx, "k")
+			if !tt.found {
+				if !errors.Is(err, cache.ErrCacheNotFound) {
+					t.Errorf("GetWithTTL() error = %v, want ErrCacheNotFound", err)
+				}
+				return
+			}
+			if err != nil || string(val) != "v" || ttl != tt.wantTTL {
+				t.Errorf("GetWithTTL() = %q, %v, %v, want v, %v", val, ttl, err, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestStoreSetNX(t *testing.T) {
+	s, now := newTestStore(t)
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		value   string
+		advance time.Duration
+		want    bool
+		stored  string
+	}{
+		{"新键", "a", 0, true, "a"},
+		{"已存在", "b", 0, false, "a"},
+		{"过期后", "c", 2 * time.Minute, true, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.advance)
+			ok, err := s.SetNX(ctx, "k", []byte(tt.value), time.Minute)
+			if err != nil || ok != tt.want {
+				t.Fatalf("SetNX() = %v, %v, want %v", ok, err, tt.want)
+			}
+			if got, err := s.Get(ctx, "k"); err != nil || string(got) != tt.stored {
+				t.Errorf("Get() = %q, %v, want %s", got, err, tt.stored)
+			}
+		})
+	}
+}
+
+func TestStoreIncrWithTTL(t *testing.T) {
+	s, now := newTestStore(t)
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		delta   int64
+		advance time.Duration
+		want    int64
+	}{
+		{"新建", 2, 0, 2},
+		{"累加", 3, 30 * time.Second, 5},
+		{"不延长过期时间", -1, 20 * time.Second, 4},
+		{"过期后重建", 7, 20 * time.Second, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.advance)
+			if got, err := s.IncrWithTTL(ctx, "n", tt.delta, time.Minute); err != nil || got != tt.want {
+				t.Errorf("IncrWithTTL() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+
+	if err := s.Set(ctx, "text", []byte("abc"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.IncrWithTTL(ctx, "text", 1, 0); err == nil {
+		t.Error("IncrWithTTL(非数字) error = nil")
+	}
+}
+
+func TestStoreBatch(t *testing.T) {
+	s, now := newTestStore(t)
+	ctx := context.Background()
+	// 超过单条语句的键数量上限，验证分批
+	values := make(map[string][]byte, maxBatchKeys+10)
+	keys := make([]string, 0, maxBatchKeys+10)
+	for i := 0; i < maxBatchKeys+10; i++ {
+		key := fmt.Sprintf("k%d", i)
+		values[key] = []byte(key)
+		keys = append(keys, key)
+	}
+	if err := s.MultiSet(ctx, values, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "short", []byte("v"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(2 * time.Second)
+
+	got, err := s.MultiGet(ctx, append(keys, "short", "missing"))
+	if err != nil || len(got) != len(values) {
+		t.Fatalf("MultiGet() = %d entries, %v, want %d", len(got), err, len(values))
+	}
+	if n, err := s.Sweep(ctx); err != nil || n != 1 {
+		t.Errorf("Sweep() = %d, %v, want 1", n, err)
+	}
+
+	if err := s.Del(ctx, keys[1:]...); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.MultiGet(ctx, keys)
+	if err != nil || len(got) != 1 || string(got[keys[0]]) != keys[0] {
+		t.Errorf("Del后 MultiGet() = %v, %v, want only %s", got, err, keys[0])
+	}
+}
+
+func TestNewStoreFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	tests := []struct {
+		name    string
+		extra   map[string]interface{}
+		wantErr bool
+	}{
+		{"完整配置", map[string]interface{}{"path": path, "sweep_interval": "1s", "busy_timeout": "100ms"}, false},
+		{"缺少路径", map[string]interface{}{}, true},
+		{"无效间隔", map[string]interface{}{"path": path, "sweep_interval": "soon"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := newStoreFromConfig(&cache.Config{Type: CacheType, Extra: tt.extra})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newStoreFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			s := store.(*Store)
+			defer s.Close()
+			ctx := context.Background()
+			if err := s.Set(ctx, "k", []byte("v"), 0); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := s.Get(ctx, "k"); err != nil || string(got) != "v" {
+				t.Errorf("Get() = %q, %v, want v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// 缓存操作名称，用于统计和追踪
+const (
+	OpSet                       = "set"
+	OpGet                       = "get"
+	OpMultiSet                  = "multi_set"
+	OpMultiGet                  = "multi_get"
+	OpDel                       = "del"
+	OpSetCacheWithNotFound      = "set_not_found"
+	OpMultiSetCacheWithNotFound = "multi_set_not_found"
+	OpDelWithTombstone          = "del_tombstone"
+	OpIncrWithTTL               = "incr_ttl"
+	OpDelMany                   = "del_many"
+	OpDescribe                  = "describe"
+	OpGetOrSet                  = "get_or_set"
+	OpDelDelayed                = "del_delayed"
+	OpTTL                       = "ttl"
+	OpExpire                    = "expire"
+	OpExists                    = "exists"
+	OpSetNX                     = "set_nx"
+	OpSetWithTags               = "set_tags"
+	OpInvalidateTag             = "invalidate_tag"
+	OpDelPattern                = "del_pattern"
+	OpCopy                      = "copy"
+	OpRename                    = "rename"
+)
+
+// StatsCollector 统计收集器接口
+// 与具体的监控库解耦，可以适配Prometheus、OpenTelemetry等
+// 注意：实现必须是线程安全的
+type StatsCollector interface {
+	// IncrHit 命中计数加一
+	IncrHit(backend CacheType, op string)
+	// IncrMiss 未命中计数加一
+	IncrMiss(backend CacheType, op string)
+	// ObserveLatency 记录操作耗时
+	ObserveLatency(backend CacheType, op string, d time.Duration)
+	// IncrError 错误计数加一
+	IncrError(backend CacheType, op string)
+}
+
+// statsCache 带统计功能的缓存包装
+type statsCache struct {
+	forwarder
+	backend   CacheType
+	collector StatsCollector
+}
+
+// WithStats 为缓存添加统计功能
+func WithStats(c Cache, backend CacheType, collector StatsCollector) Cache {
+	if collector == nil {
+		return c
+	}
+	return &statsCache{
+		forwarder: forwarder{c},
+		backend:   backend,
+		collector: collector,
+	}
+}
+
+// observe 记录耗时和错误
+func (s *statsCache) observe(op string, start time.Time, err error) {
+	s.collector.ObserveLatency(s.backend, op, time.Since(start))
+	if err != nil {
+		s.collector.IncrError(s.backend, op)
+	}
+}
+
+// Set 设置数据
+func (s *statsCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := s.Cache.Set(ctx, key, val, expiration)
+	s.observe(OpSet, start, err)
+	return err
+}
+
+// Get 获取数据，未找到和占位符不计为错误
+func (s *statsCache) Get(ctx context.Context, key string, val interface{}) error {
+	start := time.Now()
+	err := s.Cache.Get(ctx, key, val)
+	s.collector.ObserveLatency(s.backend, OpGet, time.Since(start))
+	switch {
+	case err == nil, errors.Is(err, ErrPlaceholder), errors.Is(err, ErrTombstone):
+		s.collector.IncrHit(s.backend, OpGet)
+	case errors.Is(err, ErrCacheNotFound):
+		s.collector.IncrMiss(s.backend, OpGet)
+	default:
+		s.collector.IncrError(s.backend, OpGet)
+	}
+	return err
+}
+
+// MultiSet 批量设置数据
+func (s *statsCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := s.Cache.MultiSet(ctx, valMap, expiration)
+	s.observe(OpMultiSet, start, err)
+	return err
+}
+
+// MultiGet 批量获取数据，按写入map的条目数统计命中
+func (s *statsCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	before := mapLen(valueMap)
+	start := time.Now()
+	err := s.Cache.MultiGet(ctx, keys, valueMap)
+	s.observe(OpMultiGet, start, err)
+	if err != nil {
+		return err
+	}
+	hits := mapLen(valueMap) - before
+	for i := 0; i < len(keys); i++ {
+		if i < hits {
+			s.collector.IncrHit(s.backend, OpMultiGet)
+		} else {
+			s.collector.IncrMiss(s.backend, OpMultiGet)
+		}
+	}
+	return nil
+}
+
+// Del 删除数据
+func (s *statsCache) Del(ctx context.Context, keys ...string) error {
+	start := time.Now()
+	err := s.Cache.Del(ctx, keys...)
+	s.observe(OpDel, start, err)
+	return err
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (s *statsCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	start := time.Now()
+	err := s.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+	s.observe(OpSetCacheWithNotFound, start, err)
+	return err
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (s *statsCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	start := time.Now()
+	err := s.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+	s.observe(OpMultiSetCacheWithNotFound, start, err)
+	return err
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (s *statsCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	start := time.Now()
+	err := s.forwarder.DelWithTombstone(ctx, key, ttl)
+	s.observe(OpDelWithTombstone, start, err)
+	return err
+}
+
+// IncrWithTTL 原子自增
+func (s *statsCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	start := time.Now()
+	v, err := s.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+	s.observe(OpIncrWithTTL, start, err)
+	return v, err
+}
+
+// DelMany 分片批量删除大量键
+func (s *statsCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	start := time.Now()
+	err := s.forwarder.DelMany(ctx, keys, opts)
+	s.observe(OpDelMany, start, err)
+	return err
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存，数据不存在不计为错误
+func (s *statsCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	start := time.Now()
+	err := s.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+	s.collector.ObserveLatency(s.backend, OpGetOrSet, time.Since(start))
+	if err != nil && !errors.Is(err, ErrNotFoundCached) && !errors.Is(err, ErrNotFound) {
+		s.collector.IncrError(s.backend, OpGetOrSet)
+	}
+	return err
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (s *statsCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	start := time.Now()
+	err := s.forwarder.DelDelayed(ctx, key, delay)
+	s.observe(OpDelDelayed, start, err)
+	return err
+}
+
+// TTL 获取剩余过期时间
+func (s *statsCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	start := time.Now()
+	ttl, err := s.Cache.TTL(ctx, key)
+	s.collector.ObserveLatency(s.backend, OpTTL, time.Since(start))
+	if err != nil && !errors.Is(err, ErrCacheNotFound) {
+		s.collector.IncrError(s.backend, OpTTL)
+	}
+	return ttl, err
+}
+
+// Expire 重设过期时间
+func (s *statsCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	start := time.Now()
+	err := s.Cache.Expire(ctx, key, ttl)
+	s.observe(OpExpire, start, err)
+	return err
+}
+
+// Exists 键是否存在
+func (s *statsCache) Exists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	ok, err := s.forwarder.Exists(ctx, key)
+	s.observe(OpExists, start, err)
+	return ok, err
+}
+
+// SetNX 仅在键不存在时设置数据
+func (s *statsCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	start := time.Now()
+	ok, err := s.forwarder.SetNX(ctx, key, val, expiration)
+	s.observe(OpSetNX, start, err)
+	return ok, err
+}
+
+// SetWithTags 设置数据并加入标签
+func (s *statsCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	start := time.Now()
+	err := s.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+	s.observe(OpSetWithTags, start, err)
+	return err
+}
+
+// InvalidateTag 删除标签下的所有键
+func (s *statsCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	start := time.Now()
+	report, err := s.forwarder.InvalidateTag(ctx, tag)
+	s.observe(OpInvalidateTag, start, err)
+	return report, err
+}
+
+// DelPattern 删除匹配模式的键
+func (s *statsCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	start := time.Now()
+	n, err := s.forwarder.DelPattern(ctx, pattern)
+	s.observe(OpDelPattern, start, err)
+	return n, err
+}
+
+// Copy 复制键到目标键
+func (s *statsCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	start := time.Now()
+	err := s.forwarder.Copy(ctx, src, dst, preserveTTL)
+	s.observe(OpCopy, start, err)
+	return err
+}
+
+// Rename 重命名键
+func (s *statsCache) Rename(ctx context.Context, src, dst string) error {
+	start := time.Now()
+	err := s.forwarder.Rename(ctx, src, dst)
+	s.observe(OpRename, start, err)
+	return err
+}
+
+// Describe 获取缓存条目的元数据
+func (s *statsCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	start := time.Now()
+	info, err := s.forwarder.Describe(ctx, key)
+	s.observe(OpDescribe, start, err)
+	return info, err
+}
+
+// mapLen 获取map的长度，非map类型返回0
+func mapLen(m interface{}) int {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		return 0
+	}
+	return v.Len()
+}
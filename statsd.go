@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsdClient statsd客户端接口
+// 与github.com/DataDog/datadog-go/v5/statsd的Client方法签名兼容，可以直接传入
+type StatsdClient interface {
+	// Incr 计数加一
+	Incr(name string, tags []string, rate float64) error
+	// Timing 记录耗时
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+}
+
+// statsdStatsCollector 基于statsd的统计收集器
+type statsdStatsCollector struct {
+	client    StatsdClient
+	namespace string
+	tags      []string
+}
+
+// NewStatsdStatsCollector 创建statsd统计收集器
+// namespace为指标名前缀(如"myapp.")，tags为附加到所有指标的全局标签(如"env:prod")
+func NewStatsdStatsCollector(client StatsdClient, namespace string, tags ...string) StatsCollector {
+	return &statsdStatsCollector{
+		client:    client,
+		namespace: namespace,
+		tags:      tags,
+	}
+}
+
+// buildTags 生成标签列表
+func (s *statsdStatsCollector) buildTags(backend CacheType, op string) []string {
+	tags := make([]string, 0, len(s.tags)+2)
+	tags = append(tags, s.tags...)
+	return append(tags, "backend:"+string(backend), "operation:"+op)
+}
+
+// IncrHit 命中计数加一
+func (s *statsdStatsCollector) IncrHit(backend CacheType, op string) {
+	_ = s.client.Incr(s.namespace+"cache.hit", s.buildTags(backend, op), 1)
+}
+
+// IncrMiss 未命中计数加一
+func (s *statsdStatsCollector) IncrMiss(backend CacheType, op string) {
+	_ = s.client.Incr(s.namespace+"cache.miss", s.buildTags(backend, op), 1)
+}
+
+// ObserveLatency 记录操作耗时
+func (s *statsdStatsCollector) ObserveLatency(backend CacheType, op string, d time.Duration) {
+	_ = s.client.Timing(s.namespace+"cache.latency", d, s.buildTags(backend, op), 1)
+}
+
+// IncrError 错误计数加一
+func (s *statsdStatsCollector) IncrError(backend CacheType, op string) {
+	_ = s.client.Incr(s.namespace+"cache.error", s.buildTags(backend, op), 1)
+}
+
+// ----------------------------------------------------------------------------
+
+// udpStatsdClient 简单的UDP statsd客户端，使用DogStatsD协议格式输出标签
+type udpStatsdClient struct {
+	conn net.Conn
+}
+
+// NewStatsdUDPClient 创建UDP statsd客户端，addr如"127.0.0.1:8125"
+// 适用于未引入datadog-go等客户端库的场景
+func NewStatsdUDPClient(addr string) (StatsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接statsd失败: %w", err)
+	}
+	return &udpStatsdClient{conn: conn}, nil
+}
+
+// Incr 计数加一
+func (c *udpStatsdClient) Incr(name string, tags []string, rate float64) error {
+	return c.send(name, "1", "c", tags, rate)
+}
+
+// Timing 记录耗时，单位毫秒
+func (c *udpStatsdClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	ms := strconv.FormatFloat(float64(value)/float64(time.Millisecond), 'f', -1, 64)
+	return c.send(name, ms, "ms", tags, rate)
+}
+
+// Close 关闭连接
+func (c *udpStatsdClient) Close() error {
+	return c.conn.Close()
+}
+
+// send 发送一条指标，格式: name:value|type|@rate|#tag1,tag2
+func (c *udpStatsdClient) send(name, value, typ string, tags []string, rate float64) error {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(typ)
+	if rate > 0 && rate < 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	if len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(tags, ","))
+	}
+	_, err := c.conn.Write([]byte(b.String()))
+	return err
+}
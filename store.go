@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ErrNotSupported 后端不支持该操作
+var ErrNotSupported = errors.New("缓存后端不支持该操作")
+
+// Store 字节级键值存储，第三方后端实现该接口后通过NewStoreCache获得完整的Cache实现
+// 编码、占位符、墓碑和过期时间策略由NewStoreCache统一处理，后端只负责存取字节
+type Store interface {
+	// Get 获取值，不存在时返回ErrCacheNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set 设置值，ttl为0表示永不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del 删除值，键不存在时不返回错误
+	Del(ctx context.Context, keys ...string) error
+}
+
+// StoreMultiGetter 支持批量获取的存储，结果中不包含未找到的键
+type StoreMultiGetter interface {
+	MultiGet(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// StoreMultiSetter 支持批量设置的存储
+type StoreMultiSetter interface {
+	MultiSet(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+}
+
+// StoreIncrementer 支持原子自增的存储，仅在键新建时设置过期时间
+type StoreIncrementer interface {
+	IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// storeCache 基于Store的缓存
+type storeCache struct {
+	store     Store
+	KeyPrefix string
+	newObject func() interface{}
+	cacheOptions
+}
+
+// NewStoreCache 基于字节级存储创建缓存
+func NewStoreCache(store Store, keyPrefix string, encode Encoding, newObject func() interface{}, opts ...CacheOption) Cache {
+	return newStoreCache(store, &Config{KeyPrefix: keyPrefix}, encode, newObject, opts...)
+}
+
+// newStoreCache 根据配置创建基于Store的缓存，供各后端的提供者使用
+func newStoreCache(store Store, config *Config, encode Encoding, newObject func() interface{}, opts ...CacheOption) *storeCache {
+	return &storeCache{
+		store:        store,
+		KeyPrefix:    config.KeyPrefix,
+		newObject:    newObject,
+		cacheOptions: newCacheOptions(config, encode, opts),
+	}
+}
+
+// Set 设置数据
+func (c *storeCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	buf, err := c.encode(val)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	if len(buf) == 0 && c.placeholderEnabled() {
+		buf = c.placeholderBytes()
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	expiration, err = c.expiration(expiration, key)
+	if err != nil {
+		return err
+	}
+	if err = c.store.Set(ctx, cacheKey, buf, expiration); err != nil {
+		return fmt.Errorf("%w: 存储设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	c.sampleWrite(OpSet, cacheKey, len(buf), expiration)
+	return nil
+}
+
+// Get 获取数据
+func (c *storeCache) Get(ctx context.Context, key string, val interface{}) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if BypassFromContext(ctx) {
+		return ErrCacheNotFound
+	}
+	dataBytes, err := c.store.Get(ctx, cacheKey)
+	if err != nil {
+		if isMiss(err) {
+			c.sampleRead(OpGet, cacheKey, nil, false)
+			return ErrCacheNotFound
+		}
+		return fmt.Errorf("%w: 存储获取错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	c.sampleRead(OpGet, cacheKey, dataBytes, true)
+	return c.decodeEntry(ctx, key, cacheKey, dataBytes, val)
+}
+
+// decodeEntry 解码存储中的数据，处理墓碑、占位符、校验和回退编码重写
+func (c *storeCache) decodeEntry(ctx context.Context, key, cacheKey string, dataBytes []byte, val interface{}) error {
+	if bytes.Equal(dataBytes, TombstonePlaceholderBytes) {
+		return ErrTombstone
+	}
+	if c.isPlaceholder(dataBytes) {
+		return ErrPlaceholder
+	}
+	needRewrite, err := c.decodeValue(dataBytes, val)
+	if err != nil {
+		if isMiss(err) {
+			return err
+		}
+		return fmt.Errorf("%w: %w, 键=%s, 缓存键=%s, 类型=%T, 数据=%s ",
+			ErrDecode, err, key, cacheKey, val, dataBytes)
+	}
+	if !c.valid(key, val) {
+		_ = c.store.Del(ctx, cacheKey)
+		return ErrCacheNotFound
+	}
+	// 存储无法读取剩余过期时间，只有设置了滑动过期时间才刷新，回退编码的数据同时用主编码重写，失败时忽略
+	if c.slidingTTL > 0 {
+		buf := dataBytes
+		if needRewrite {
+			if encoded, err := c.encode(val); err == nil && len(encoded) > 0 {
+				buf = encoded
+			}
+		}
+		_ = c.store.Set(ctx, cacheKey, buf, c.slidingTTL)
+	}
+	return nil
+}
+
+// MultiSet 批量设置数据，存储未实现StoreMultiSetter或启用了过期时间抖动时逐个写入
+func (c *storeCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	setter, ok := c.store.(StoreMultiSetter)
+	if !ok || c.jitter > 0 {
+		for key, value := range valueMap {
+			if err := c.Set(ctx, key, value, expiration); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	values := make(map[string][]byte, len(valueMap))
+	keys := make([]string, 0, len(valueMap))
+	for key, value := range valueMap {
+		buf, err := c.encode(value)
+		if err != nil {
+			return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, value)
+		}
+		if len(buf) == 0 && c.placeholderEnabled() {
+			buf = c.placeholderBytes()
+		}
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+		if err != nil {
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+		}
+		values[cacheKey] = buf
+		keys = append(keys, key)
+	}
+	expiration, err := c.expiration(expiration, keys...)
+	if err != nil {
+		return err
+	}
+	if err = setter.MultiSet(ctx, values, expiration); err != nil {
+		return fmt.Errorf("%w: 存储批量设置错误: %w", ErrBackend, err)
+	}
+	for cacheKey, buf := range values {
+		c.sampleWrite(OpMultiSet, cacheKey, len(buf), expiration)
+	}
+	return nil
+}
+
+// MultiGet 批量获取数据，未找到、占位符和解码失败的键被跳过
+func (c *storeCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
+	if len(keys) == 0 || BypassFromContext(ctx) {
+		return nil
+	}
+	getter, ok := c.store.(StoreMultiGetter)
+	valueMap := reflect.ValueOf(value)
+	if !ok {
+		for _, key := range keys {
+			object := c.acquire(c.newObject)
+			if err := c.Get(ctx, key, object); err != nil {
+				c.release(object)
+				continue
+			}
+			valueMap.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(object))
+		}
+		return nil
+	}
+
+	cacheKeys := make([]string, 0, len(keys))
+	userKeys := make(map[string]string, len(keys))
+	for _, key := range keys {
+		cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+		if err != nil {
+			return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+		}
+		cacheKeys = append(cacheKeys, cacheKey)
+		userKeys[cacheKey] = key
+	}
+	values, err := getter.MultiGet(ctx, cacheKeys)
+	if err != nil {
+		return fmt.Errorf("%w: 存储批量获取错误: %w, 键=%+v", ErrBackend, err, cacheKeys)
+	}
+	jobs := make([]decodeJob, 0, len(values))
+	for _, cacheKey := range cacheKeys {
+		dataBytes, found := values[cacheKey]
+		c.sampleRead(OpMultiGet, cacheKey, dataBytes, found)
+		if !found {
+			continue
+		}
+		jobs = append(jobs, decodeJob{key: cacheKey, data: dataBytes, object: c.acquire(c.newObject)})
+	}
+	c.decodeJobs(jobs, func(job *decodeJob) error {
+		return c.decodeEntry(ctx, userKeys[job.key], job.key, job.data, job.object)
+	})
+	for _, job := range jobs {
+		if job.err != nil {
+			c.release(job.object)
+			continue
+		}
+		valueMap.SetMapIndex(reflect.ValueOf(userKeys[job.key]), reflect.ValueOf(job.object))
+	}
+	return nil
+}
+
+// Del 删除数据
+func (c *storeCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	if len(cacheKeys) > 0 {
+		if err := c.store.Del(ctx, cacheKeys...); err != nil {
+			return errors.Join(fmt.Errorf("%w: 存储删除错误: %w, 键=%+v", ErrBackend, err, cacheKeys), keyErrs.errOrNil())
+		}
+	}
+	return keyErrs.errOrNil()
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (c *storeCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if !c.placeholderEnabled() {
+		return nil
+	}
+	expiration := explicitNotFoundTTL(ttl)
+	if expiration == 0 {
+		expiration = c.storeNotFoundExpiration(ctx, cacheKey)
+	}
+	if err = c.store.Set(ctx, cacheKey, c.placeholderBytes(), expiration); err != nil {
+		return fmt.Errorf("%w: 存储设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (c *storeCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	if err = c.store.Set(ctx, cacheKey, TombstonePlaceholderBytes, tombstoneTTL(ttl)); err != nil {
+		return fmt.Errorf("%w: 存储设置墓碑错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
+
+// IncrWithTTL 原子自增，存储未实现StoreIncrementer时返回ErrNotSupported
+func (c *storeCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	incr, ok := c.store.(StoreIncrementer)
+	if !ok {
+		return 0, fmt.Errorf("%w: IncrWithTTL", ErrNotSupported)
+	}
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ttl, err = c.expiration(ttl, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := incr.IncrWithTTL(ctx, cacheKey, delta, ttl)
+	if err != nil {
+		return 0, fmt.Errorf("%w: 存储自增错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return v, nil
+}
+
+// DelMany 按分片批量删除大量键
+func (c *storeCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	cacheKeys, keyErrs := buildCacheKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), keys)
+	p := &progress{total: len(cacheKeys), onEvent: opts.OnProgress}
+	opts.setDefaults()
+	for _, chunk := range chunkKeys(cacheKeys, opts.ChunkSize) {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(err, keyErrs.errOrNil())
+		}
+		if err := c.store.Del(ctx, chunk...); err != nil {
+			return errors.Join(fmt.Errorf("%w: 存储删除错误: %w", ErrBackend, err), keyErrs.errOrNil())
+		}
+		p.add(len(chunk))
+	}
+	return keyErrs.errOrNil()
+}
+
+// ParseCounter 解析计数器的值，供实现StoreIncrementer的存储使用
+func ParseCounter(data []byte) (int64, error) {
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: 值不是整数", ErrDecode)
+	}
+	return v, nil
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// StoreFactory 根据配置创建字节级存储，返回的存储实现io.Closer时在提供者关闭时调用
+type StoreFactory func(config *Config) (Store, error)
+
+var (
+	storeFactoriesMu sync.RWMutex
+	storeFactories   = make(map[CacheType]StoreFactory)
+)
+
+// RegisterStore 注册基于Store的缓存类型，通常在后端子包的init中调用
+// 注册后即可通过NewProvider按Config.Type创建，后端特有的配置通过Config.Extra传入
+func RegisterStore(t CacheType, factory StoreFactory) {
+	storeFactoriesMu.Lock()
+	defer storeFactoriesMu.Unlock()
+	if factory == nil {
+		panic("缓存: 存储工厂不能为空")
+	}
+	if _, ok := storeFactories[t]; ok {
+		panic(fmt.Sprintf("缓存: 重复注册缓存类型 %s", t))
+	}
+	if _, ok := lookupBackend(t); ok {
+		panic(fmt.Sprintf("缓存: 重复注册缓存类型 %s", t))
+	}
+	storeFactories[t] = factory
+}
+
+// lookupStore 获取已注册的存储工厂
+func lookupStore(t CacheType) (StoreFactory, bool) {
+	storeFactoriesMu.RLock()
+	defer storeFactoriesMu.RUnlock()
+	factory, ok := storeFactories[t]
+	return factory, ok
+}
+
+// storeProvider 基于Store的缓存提供者
+type storeProvider struct {
+	cache     Cache
+	store     Store
+	cacheType CacheType
+	health    healthRecorder
+}
+
+// newStoreProvider 创建基于Store的缓存提供者
+func newStoreProvider(factory StoreFactory, config *Config, encoding Encoding, newObject func() interface{}, opts ...CacheOption) (Provider, error) {
+	store, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建%s存储失败: %w", config.Type, err)
+	}
+	return &storeProvider{
+		cache:     wrapCache(config, encoding, newStoreCache(store, config, encoding, newObject, opts...)),
+		store:     store,
+		cacheType: config.Type,
+	}, nil
+}
+
+// GetCache 获取缓存实例
+func (p *storeProvider) GetCache() Cache {
+	return p.cache
+}
+
+// Close 关闭存储
+func (p *storeProvider) Close() error {
+	if closer, ok := p.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Connect 存储在创建时已完成初始化
+func (p *storeProvider) Connect(_ context.Context) error {
+	return nil
+}
+
+// HealthCheck 读取探测键检查存储健康状态，未找到视为健康
+func (p *storeProvider) HealthCheck(ctx context.Context) HealthStatus {
+	status := HealthStatus{Backend: p.cacheType, CheckedAt: time.Now()}
+	start := time.Now()
+	_, err := p.store.Get(ctx, healthProbeKey)
+	status.Latency = time.Since(start)
+	if isMiss(err) {
+		err = nil
+	}
+	p.health.record(&status, err)
+	return status
+}
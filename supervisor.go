@@ -0,0 +1,393 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable 缓存后端不可用，监控器判定连接持续失败时快速返回该错误
+var ErrUnavailable = errors.New("缓存后端不可用")
+
+// ConnState 连接状态
+type ConnState int
+
+const (
+	// ConnStateConnected 已连接
+	ConnStateConnected ConnState = iota
+	// ConnStateDisconnected 已断开，请求将快速失败
+	ConnStateDisconnected
+	// ConnStateReconnecting 正在重连
+	ConnStateReconnecting
+)
+
+// String 返回连接状态名称
+func (s ConnState) String() string {
+	switch s {
+	case ConnStateConnected:
+		return "connected"
+	case ConnStateDisconnected:
+		return "disconnected"
+	case ConnStateReconnecting:
+		return "reconnecting"
+	default:
+		return fmt.Sprintf("ConnState(%d)", int(s))
+	}
+}
+
+// SupervisorConfig 连接监控配置
+type SupervisorConfig struct {
+	// CheckInterval 连接正常时的探测间隔
+	CheckInterval time.Duration `json:"check_interval" yaml:"check_interval"`
+	// FailureThreshold 连续失败多少次后判定为断开
+	FailureThreshold int `json:"failure_threshold" yaml:"failure_threshold"`
+	// InitialBackoff 首次重连等待时间
+	InitialBackoff time.Duration `json:"initial_backoff" yaml:"initial_backoff"`
+	// MaxBackoff 最大重连等待时间，重连等待时间按指数增长直到该值
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff"`
+	// OnStateChange 连接状态变化回调
+	OnStateChange func(from, to ConnState, err error) `json:"-" yaml:"-"`
+}
+
+// setDefaults 设置默认值
+func (c *SupervisorConfig) setDefaults() {
+	if c.CheckInterval == 0 {
+		c.CheckInterval = 5 * time.Second
+	}
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = 3
+	}
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = 500 * time.Millisecond
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+}
+
+// supervisor 连接监控器
+// 周期性探测后端，连续失败达到阈值后标记为断开，此后按指数退避尝试重连
+type supervisor struct {
+	config   SupervisorConfig
+	ping     func(ctx context.Context) error
+	timeout  time.Duration
+	mu       sync.RWMutex
+	state    ConnState
+	failures int
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newSupervisor 创建并启动连接监控器，timeout为单次探测超时时间
+func newSupervisor(config SupervisorConfig, timeout time.Duration, ping func(ctx context.Context) error) *supervisor {
+	config.setDefaults()
+	s := &supervisor{
+		config:  config,
+		ping:    ping,
+		timeout: timeout,
+		state:   ConnStateConnected,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// State 获取当前连接状态
+func (s *supervisor) State() ConnState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// available 后端是否可用，重连中的请求也会快速失败
+func (s *supervisor) available() bool {
+	return s.State() == ConnStateConnected
+}
+
+// degraded 后端是否处于降级状态，最近的探测失败但尚未达到断开阈值时同样视为降级
+func (s *supervisor) degraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state != ConnStateConnected || s.failures > 0
+}
+
+// run 监控循环
+func (s *supervisor) run() {
+	defer close(s.done)
+	backoff := s.config.InitialBackoff
+	wait := s.config.CheckInterval
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(wait):
+		}
+
+		if s.State() == ConnStateDisconnected {
+			s.setState(ConnStateReconnecting, nil)
+		}
+		err := s.probe()
+		if err == nil {
+			s.mu.Lock()
+			s.failures = 0
+			s.mu.Unlock()
+			s.setState(ConnStateConnected, nil)
+			backoff = s.config.InitialBackoff
+			wait = s.config.CheckInterval
+			continue
+		}
+
+		s.mu.Lock()
+		s.failures++
+		failures := s.failures
+		s.mu.Unlock()
+		switch state := s.State(); {
+		case state == ConnStateReconnecting:
+			s.setState(ConnStateDisconnected, err)
+		case failures >= s.config.FailureThreshold:
+			s.setState(ConnStateDisconnected, err)
+		default:
+			// 未达到阈值，按正常间隔继续探测
+			wait = s.config.CheckInterval
+			continue
+		}
+
+		// 指数退避
+		wait = backoff
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+}
+
+// probe 执行一次探测
+func (s *supervisor) probe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.ping(ctx)
+}
+
+// setState 切换状态并触发回调
+func (s *supervisor) setState(to ConnState, err error) {
+	s.mu.Lock()
+	from := s.state
+	s.state = to
+	s.mu.Unlock()
+	if from != to && s.config.OnStateChange != nil {
+		s.config.OnStateChange(from, to, err)
+	}
+}
+
+// Close 停止监控
+func (s *supervisor) Close() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
+
+// ----------------------------------------------------------------------------
+
+// supervisedCache 受监控的缓存，后端断开时快速失败，避免每个请求都等待连接超时
+type supervisedCache struct {
+	forwarder
+	supervisor *supervisor
+}
+
+// check 检查后端是否可用
+func (c *supervisedCache) check() error {
+	if !c.supervisor.available() {
+		return fmt.Errorf("%w: 状态=%s", ErrUnavailable, c.supervisor.State())
+	}
+	return nil
+}
+
+// Set 设置数据
+func (c *supervisedCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.Set(ctx, key, val, expiration)
+}
+
+// Get 获取数据
+func (c *supervisedCache) Get(ctx context.Context, key string, val interface{}) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.Get(ctx, key, val)
+}
+
+// MultiSet 批量设置数据
+func (c *supervisedCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.MultiSet(ctx, valMap, expiration)
+}
+
+// MultiGet 批量获取数据
+func (c *supervisedCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.MultiGet(ctx, keys, valueMap)
+}
+
+// Del 删除数据
+func (c *supervisedCache) Del(ctx context.Context, keys ...string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (c *supervisedCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (c *supervisedCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (c *supervisedCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.DelWithTombstone(ctx, key, ttl)
+}
+
+// IncrWithTTL 原子自增
+func (c *supervisedCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	return c.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+}
+
+// DelMany 分片批量删除大量键
+func (c *supervisedCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.DelMany(ctx, keys, opts)
+}
+
+// Describe 获取缓存条目的元数据
+func (c *supervisedCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	if err := c.check(); err != nil {
+		return EntryInfo{}, err
+	}
+	return c.forwarder.Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存
+func (c *supervisedCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (c *supervisedCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.DelDelayed(ctx, key, delay)
+}
+
+// TTL 获取剩余过期时间
+func (c *supervisedCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	return c.Cache.TTL(ctx, key)
+}
+
+// Expire 重设过期时间
+func (c *supervisedCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.Cache.Expire(ctx, key, ttl)
+}
+
+// Exists 键是否存在
+func (c *supervisedCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := c.check(); err != nil {
+		return false, err
+	}
+	return c.forwarder.Exists(ctx, key)
+}
+
+// SetNX 仅在键不存在时设置数据
+func (c *supervisedCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	if err := c.check(); err != nil {
+		return false, err
+	}
+	return c.forwarder.SetNX(ctx, key, val, expiration)
+}
+
+// SetWithTags 设置数据并加入标签
+func (c *supervisedCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTag 删除标签下的所有键
+func (c *supervisedCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.forwarder.InvalidateTag(ctx, tag)
+}
+
+// PreviewInvalidateTag 返回标签下的键
+func (c *supervisedCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.forwarder.PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除匹配模式的键
+func (c *supervisedCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	return c.forwarder.DelPattern(ctx, pattern)
+}
+
+// Copy 复制键到目标键
+func (c *supervisedCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键
+func (c *supervisedCache) Rename(ctx context.Context, src, dst string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.forwarder.Rename(ctx, src, dst)
+}
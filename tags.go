@@ -0,0 +1,382 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tagKeyPrefix 标签成员集合键的前缀，与数据键共用缓存键前缀
+const tagKeyPrefix = "__tag:"
+
+// tagInvalidateChunk 集群失效标签时每批删除的键数量
+const tagInvalidateChunk = 500
+
+// tagAddScript 将缓存键加入标签集合，并保证集合不早于成员过期
+// KEYS[1]为标签集合键，ARGV[1]为缓存键，ARGV[2]为成员的过期时间(毫秒)，0表示永不过期
+var tagAddScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local cur = redis.call('PTTL', KEYS[1])
+if existed == 0 or (cur >= 0 and cur < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// setWithTagsScript 原子地写入数据并加入所有标签集合，规则与tagAddScript相同
+// KEYS[1]为缓存键，KEYS[2..]为标签集合键，ARGV[1]为值，ARGV[2]为过期时间(毫秒)，0表示永不过期
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl <= 0 then
+		redis.call('PERSIST', KEYS[i])
+	else
+		local cur = redis.call('PTTL', KEYS[i])
+		if existed == 0 or (cur >= 0 and cur < ttl) then
+			redis.call('PEXPIRE', KEYS[i], ttl)
+		end
+	end
+end
+return 1
+`)
+
+// invalidateTagScript 原子地删除标签集合中的所有键和集合本身，返回删除的成员数量和删除前数据的总字节数
+// KEYS[1]为标签集合键，ARGV[1]为删除命令(DEL或UNLINK)；非字符串类型的成员不计入字节数
+var invalidateTagScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+local bytes = 0
+for i = 1, #members do
+	local n = redis.pcall('STRLEN', members[i])
+	if type(n) == 'number' then
+		bytes = bytes + n
+	end
+end
+for i = 1, #members, 1000 do
+	redis.call(ARGV[1], unpack(members, i, math.min(i + 999, #members)))
+end
+redis.call('DEL', KEYS[1])
+return {#members, bytes}
+`)
+
+// popTagScript 原子地取出并删除标签集合，返回集合中的缓存键
+var popTagScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+redis.call('DEL', KEYS[1])
+return members
+`)
+
+// TagInvalidationReport 标签失效报告
+type TagInvalidationReport struct {
+	// Tag 标签
+	Tag string
+	// Keys 标签集合中的键数量，包含已过期但尚未移出集合的键
+	Keys int
+	// Bytes 删除前数据的估算字节数，按存储的编码后大小累加，已过期的键不计入
+	Bytes int64
+}
+
+// memoryTagMu 保护内存缓存中标签集合的读改写
+var memoryTagMu sync.Mutex
+
+// memoryTagSet 内存缓存中的标签集合
+type memoryTagSet map[string]struct{}
+
+// buildTagKeys 构建标签集合键
+func buildTagKeys(keyPrefix string, tags []string) ([]string, error) {
+	tagKeys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == "" {
+			return nil, fmt.Errorf("%w: 标签不能为空", ErrKeyBuild)
+		}
+		tagKey, err := BuildCacheKey(keyPrefix, tagKeyPrefix+tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w, 标签=%s", ErrKeyBuild, err, tag)
+		}
+		tagKeys = append(tagKeys, tagKey)
+	}
+	return tagKeys, nil
+}
+
+// tagMemberKeys 将标签集合中的缓存键去掉键前缀并排序，不带该前缀的键原样返回
+func tagMemberKeys(keyPrefix string, members []string) []string {
+	keys := make([]string, 0, len(members))
+	for _, member := range members {
+		if keyPrefix != "" {
+			member = strings.TrimPrefix(member, keyPrefix+":")
+		}
+		keys = append(keys, member)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetWithTags 设置数据并加入标签，标签记录在进程内，随后可通过InvalidateTag删除标签下的所有键
+func (m *memoryCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	keyPrefix := KeyPrefixFromContext(ctx, m.KeyPrefix)
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	tagKeys, err := buildTagKeys(keyPrefix, tags)
+	if err != nil {
+		return err
+	}
+	ttl, err := m.expiration(expiration, key)
+	if err != nil {
+		return err
+	}
+
+	// 先加入标签再写入数据，避免并发的InvalidateTag漏掉该键
+	memoryTagMu.Lock()
+	for _, tagKey := range tagKeys {
+		set, _ := m.tagSet(tagKey)
+		if set == nil {
+			set = make(memoryTagSet)
+		}
+		set[cacheKey] = struct{}{}
+		remaining, ok := m.client.GetTTL(tagKey)
+		switch {
+		case ttl <= 0:
+			remaining = 0
+		case !ok || (remaining > 0 && remaining < ttl):
+			remaining = ttl
+		}
+		m.client.SetWithTTL(tagKey, set, 0, remaining)
+	}
+	m.client.Wait()
+	memoryTagMu.Unlock()
+
+	return m.Set(ctx, key, val, expiration)
+}
+
+// InvalidateTag 删除标签下的所有键
+func (m *memoryCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	tagKeys, err := buildTagKeys(KeyPrefixFromContext(ctx, m.KeyPrefix), []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	report := &TagInvalidationReport{Tag: tag}
+	memoryTagMu.Lock()
+	defer memoryTagMu.Unlock()
+	set, ok := m.tagSet(tagKeys[0])
+	if !ok {
+		return report, nil
+	}
+	for cacheKey := range set {
+		if data, ok := m.client.Get(cacheKey); ok {
+			if buf, ok := data.([]byte); ok {
+				report.Bytes += int64(len(buf))
+			}
+		}
+		m.client.Del(cacheKey)
+	}
+	m.client.Del(tagKeys[0])
+	report.Keys = len(set)
+	return report, nil
+}
+
+// PreviewInvalidateTag 返回标签下的键(不含键前缀)，不删除数据
+func (m *memoryCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	keyPrefix := KeyPrefixFromContext(ctx, m.KeyPrefix)
+	tagKeys, err := buildTagKeys(keyPrefix, []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	memoryTagMu.Lock()
+	set, _ := m.tagSet(tagKeys[0])
+	members := make([]string, 0, len(set))
+	for cacheKey := range set {
+		members = append(members, cacheKey)
+	}
+	memoryTagMu.Unlock()
+	return tagMemberKeys(keyPrefix, members), nil
+}
+
+// tagSet 获取标签集合
+func (m *memoryCache) tagSet(tagKey string) (memoryTagSet, bool) {
+	data, ok := m.client.Get(tagKey)
+	if !ok {
+		return nil, false
+	}
+	set, ok := data.(memoryTagSet)
+	return set, ok
+}
+
+// SetWithTags 设置数据并加入标签，写入和加入标签在一个Lua脚本中原子执行
+func (c *redisCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	if c.proxy != ProxyModeNone {
+		return fmt.Errorf("%w: 标签需要在同一节点执行多键脚本", ErrProxyUnsupported)
+	}
+	return c.redisSetWithTags(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, val, expiration, tags, true)
+}
+
+// InvalidateTag 在一个Lua脚本中原子地删除标签下的所有键
+func (c *redisCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	if c.proxy != ProxyModeNone {
+		return nil, fmt.Errorf("%w: 标签需要在同一节点执行多键脚本", ErrProxyUnsupported)
+	}
+	tagKeys, err := buildTagKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	del := "DEL"
+	if c.unlink {
+		del = "UNLINK"
+	}
+	res, err := invalidateTagScript.Run(ctx, c.client, tagKeys, del).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 客户端失效标签错误: %w, 标签=%s", ErrBackend, err, tag)
+	}
+	report := &TagInvalidationReport{Tag: tag}
+	if len(res) == 2 {
+		report.Keys, report.Bytes = int(res[0]), res[1]
+	}
+	return report, nil
+}
+
+// PreviewInvalidateTag 返回标签下的键(不含键前缀)，不删除数据
+func (c *redisCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	if c.proxy != ProxyModeNone {
+		return nil, fmt.Errorf("%w: 标签需要在同一节点执行多键脚本", ErrProxyUnsupported)
+	}
+	return redisPreviewTag(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), tag)
+}
+
+// SetWithTags 设置数据并加入标签，标签集合与数据可能位于不同的槽，先加入标签再写入数据
+func (c *redisClusterCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	return c.redisSetWithTags(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), key, val, expiration, tags, false)
+}
+
+// InvalidateTag 删除标签下的所有键
+// 标签集合的取出是原子的，成员位于不同的槽，按批删除，删除过程中其他客户端可能短暂读到部分旧数据
+// 每批删除前通过管道STRLEN估算字节数，估算失败不影响删除
+func (c *redisClusterCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	tagKeys, err := buildTagKeys(KeyPrefixFromContext(ctx, c.KeyPrefix), []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	members, err := popTagScript.Run(ctx, c.client, tagKeys).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 客户端失效标签错误: %w, 标签=%s", ErrBackend, err, tag)
+	}
+	report := &TagInvalidationReport{Tag: tag, Keys: len(members)}
+	for len(members) > 0 {
+		n := min(len(members), tagInvalidateChunk)
+		report.Bytes += redisValueBytes(ctx, c.client, members[:n])
+		if err = c.redisUnlinkChunk(ctx, c.client, members[:n]); err != nil {
+			return nil, fmt.Errorf("%w: 客户端删除错误: %w, 标签=%s", ErrBackend, err, tag)
+		}
+		members = members[n:]
+	}
+	return report, nil
+}
+
+// PreviewInvalidateTag 返回标签下的键(不含键前缀)，不删除数据
+func (c *redisClusterCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	return redisPreviewTag(ctx, c.client, KeyPrefixFromContext(ctx, c.KeyPrefix), tag)
+}
+
+// redisPreviewTag 读取标签集合中的缓存键并去掉键前缀
+func redisPreviewTag(ctx context.Context, client redis.Cmdable, keyPrefix, tag string) ([]string, error) {
+	tagKeys, err := buildTagKeys(keyPrefix, []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	members, err := client.SMembers(ctx, tagKeys[0]).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 客户端获取标签错误: %w, 标签=%s", ErrBackend, err, tag)
+	}
+	return tagMemberKeys(keyPrefix, members), nil
+}
+
+// redisValueBytes 通过管道STRLEN估算键的数据大小，不存在、非字符串类型或命令失败的键按0计算
+func redisValueBytes(ctx context.Context, client redis.Cmdable, keys []string) int64 {
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.StrLen(ctx, key)
+		}
+		return nil
+	})
+	var total int64
+	for _, cmd := range cmds {
+		total += cmd.Val()
+	}
+	return total
+}
+
+// redisSetWithTags 写入数据并加入标签集合，atomic为true时所有键在一个脚本中写入，要求所有键位于同一节点
+func (o *cacheOptions) redisSetWithTags(ctx context.Context, client redis.Cmdable, keyPrefix, key string, val interface{}, expiration time.Duration, tags []string, atomic bool) error {
+	buf, err := o.encode(val)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s, 值=%+v ", ErrEncode, err, key, val)
+	}
+	cacheKey, err := BuildCacheKey(keyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	tagKeys, err := buildTagKeys(keyPrefix, tags)
+	if err != nil {
+		return err
+	}
+	expiration, err = o.expiration(expiration, key)
+	if err != nil {
+		return err
+	}
+	if len(buf) == 0 && o.placeholderEnabled() {
+		buf = o.placeholderBytes()
+	}
+	ttl := expiration.Milliseconds()
+
+	if atomic {
+		err = setWithTagsScript.Run(ctx, client, append([]string{cacheKey}, tagKeys...), buf, ttl).Err()
+	} else {
+		// 先加入标签再写入数据，避免并发的InvalidateTag漏掉该键
+		for _, tagKey := range tagKeys {
+			if err = tagAddScript.Run(ctx, client, []string{tagKey}, cacheKey, ttl).Err(); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = client.Set(ctx, cacheKey, buf, expiration).Err()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	o.sampleWrite(OpSetWithTags, cacheKey, len(buf), expiration)
+	return nil
+}
+
+// SetWithTags 存储后端不支持标签
+func (c *storeCache) SetWithTags(_ context.Context, _ string, _ interface{}, _ time.Duration, _ ...string) error {
+	return fmt.Errorf("%w: SetWithTags", ErrNotSupported)
+}
+
+// InvalidateTag 存储后端不支持标签
+func (c *storeCache) InvalidateTag(_ context.Context, _ string) (*TagInvalidationReport, error) {
+	return nil, fmt.Errorf("%w: InvalidateTag", ErrNotSupported)
+}
+
+// PreviewInvalidateTag 存储后端不支持标签
+func (c *storeCache) PreviewInvalidateTag(_ context.Context, _ string) ([]string, error) {
+	return nil, fmt.Errorf("%w: PreviewInvalidateTag", ErrNotSupported)
+}
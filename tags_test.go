@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestMemoryCache(t *testing.T, opts ...CacheOption) Cache {
+	t.Helper()
+	cfg := defaultMemoryConfig()
+	cfg.Engine = MemoryEngineDeterministic
+	st, err := newMemoryStore(cfg, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(st.Close)
+	return NewMemoryCacheWithStore(st, "test", &JSONEncoding{}, func() interface{} { return new(string) }, opts...)
+}
+
+func TestPreviewInvalidateTag(t *testing.T) {
+	c := Extend(newTestMemoryCache(t))
+	ctx := context.Background()
+	for key, val := range map[string]string{"item:2": "bb", "item:1": "a"} {
+		if err := c.SetWithTags(ctx, key, &val, time.Minute, "items"); err != nil {
+			t.Fatalf("SetWithTags(%s) error = %v", key, err)
+		}
+	}
+
+	keys, err := c.PreviewInvalidateTag(ctx, "items")
+	if err != nil {
+		t.Fatalf("PreviewInvalidateTag() error = %v", err)
+	}
+	if want := []string{"item:1", "item:2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("PreviewInvalidateTag() = %v, want %v", keys, want)
+	}
+	var val string
+	if err := c.Get(ctx, "item:1", &val); err != nil || val != "a" {
+		t.Errorf("演练后Get() = %q, %v, want a", val, err)
+	}
+
+	keys, err = c.PreviewInvalidateTag(ctx, "missing")
+	if err != nil || len(keys) != 0 {
+		t.Errorf("PreviewInvalidateTag(missing) = %v, %v, want empty", keys, err)
+	}
+}
+
+func TestInvalidateTagReport(t *testing.T) {
+	c := Extend(newTestMemoryCache(t))
+	ctx := context.Background()
+	a, b := "a", "bb"
+	if err := c.SetWithTags(ctx, "item:1", &a, time.Minute, "items"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetWithTags(ctx, "item:2", &b, time.Minute, "items"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := c.InvalidateTag(ctx, "items")
+	if err != nil {
+		t.Fatalf("InvalidateTag() error = %v", err)
+	}
+	// JSON编码后为"a"和"bb"
+	want := &TagInvalidationReport{Tag: "items", Keys: 2, Bytes: 7}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("InvalidateTag() = %+v, want %+v", report, want)
+	}
+	var val string
+	if err := c.Get(ctx, "item:1", &val); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("失效后Get() error = %v, want ErrCacheNotFound", err)
+	}
+
+	report, err = c.InvalidateTag(ctx, "items")
+	if err != nil || report.Keys != 0 || report.Bytes != 0 {
+		t.Errorf("再次InvalidateTag() = %+v, %v, want empty report", report, err)
+	}
+}
+
+func TestPreviewInvalidateTagReadOnly(t *testing.T) {
+	c := newTestMemoryCache(t)
+	ctx := context.Background()
+	val := "a"
+	if err := Extend(c).SetWithTags(ctx, "item:1", &val, time.Minute, "items"); err != nil {
+		t.Fatal(err)
+	}
+
+	ro := Extend(ReadOnly(c))
+	keys, err := ro.PreviewInvalidateTag(ctx, "items")
+	if err != nil || !reflect.DeepEqual(keys, []string{"item:1"}) {
+		t.Errorf("只读PreviewInvalidateTag() = %v, %v, want [item:1]", keys, err)
+	}
+	if _, err := ro.InvalidateTag(ctx, "items"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("只读InvalidateTag() error = %v, want ErrReadOnly", err)
+	}
+}
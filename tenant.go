@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DBRouter 按数据库索引路由缓存，由Redis单机提供者实现
+// 不同的逻辑缓存可以使用同一服务器上的不同数据库，共享同一份连接池配置
+type DBRouter interface {
+	// ForDB 获取指定数据库的缓存实例
+	ForDB(db int) (Cache, error)
+	// ForTenant 根据RedisConfig.TenantDBs获取租户对应的缓存实例
+	ForTenant(tenant string) (Cache, error)
+}
+
+// ForDB 获取指定数据库的缓存实例，同一数据库的实例会被复用
+// 每个数据库使用独立的连接池，连接池配置与提供者相同
+func (p *redisProvider) ForDB(db int) (Cache, error) {
+	if db < 0 {
+		return nil, fmt.Errorf("数据库索引不能为负数: %d", db)
+	}
+	if db == p.redisConfig.DB {
+		return p.cache, nil
+	}
+	if err := p.redisConfig.ProxyMode.check("select"); err != nil {
+		return nil, err
+	}
+	// 确保提供者未关闭
+	if _, err := p.conn.ensure(); err != nil {
+		return nil, err
+	}
+
+	p.dbMu.Lock()
+	defer p.dbMu.Unlock()
+	if c, ok := p.dbCaches[db]; ok {
+		return c, nil
+	}
+	if p.dbClients == nil {
+		p.dbClients = make(map[int]*redis.Client)
+		p.dbCaches = make(map[int]Cache)
+	}
+	client := redis.NewClient(p.redisConfig.options(db))
+	c := p.wrap(p.newCache(client))
+	p.dbClients[db] = client
+	p.dbCaches[db] = c
+	return c, nil
+}
+
+// ForTenant 根据RedisConfig.TenantDBs获取租户对应的缓存实例
+func (p *redisProvider) ForTenant(tenant string) (Cache, error) {
+	db, ok := p.redisConfig.TenantDBs[tenant]
+	if !ok {
+		return nil, fmt.Errorf("租户 %s 未配置数据库", tenant)
+	}
+	return p.ForDB(db)
+}
+
+// closeDBClients 关闭所有按数据库创建的客户端
+func (p *redisProvider) closeDBClients() error {
+	p.dbMu.Lock()
+	defer p.dbMu.Unlock()
+	var errs []error
+	for db, client := range p.dbClients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭数据库 %d 的客户端失败: %w", db, err))
+		}
+	}
+	p.dbClients = nil
+	p.dbCaches = nil
+	return errors.Join(errs...)
+}
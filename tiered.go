@@ -0,0 +1,665 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultTieredChannel 默认的L1失效消息频道
+const defaultTieredChannel = "cache:tiered:invalidate"
+
+// tieredPublishBatch 每条失效消息最多包含的键数量
+const tieredPublishBatch = 1000
+
+// tieredBackgroundLimit 同时执行的后台回填和修复任务上限，超过时跳过，下次读取时再尝试
+const tieredBackgroundLimit = 64
+
+// tieredRepairMaxKeys 写回L2时最多跟踪的键数量，超出后新键跳过写回，下次读取时再尝试
+const tieredRepairMaxKeys = 10000
+
+// TieredConfig 两级缓存配置
+type TieredConfig struct {
+	// L1TTL L1的过期时间，同时是L1过期时间的上限，默认1分钟
+	L1TTL time.Duration `json:"l1_ttl" yaml:"l1_ttl"`
+	// L1TTLFraction L1过期时间不超过L2过期时间的该比例，0表示不按比例限制
+	// 失效消息丢失时L1最多在该时间内返回旧数据
+	L1TTLFraction float64 `json:"l1_ttl_fraction,omitempty" yaml:"l1_ttl_fraction,omitempty"`
+	// UseL2TTL L2命中回填L1时读取L2的剩余过期时间，L1不会比L2更晚过期，每次回填多一次PTTL
+	UseL2TTL bool `json:"use_l2_ttl,omitempty" yaml:"use_l2_ttl,omitempty"`
+	// AsyncBackfill L2命中时在后台回填L1，读取不等待回填；后台重新读取L2的原始数据和剩余过期时间，
+	// 总是按L2的剩余过期时间回填，每次回填多一次GET和一次PTTL
+	AsyncBackfill bool `json:"async_backfill,omitempty" yaml:"async_backfill,omitempty"`
+	// RepairL2 L1命中时在后台用L1中的数据以SETNX写回L2，用于L2被清空(如Redis重启或FLUSHDB)后恢复，
+	// L2中已有数据时不覆盖；每次L1命中多一次L2写入。删除数据时应使用DelWithTombstone，
+	// 墓碑标记使SETNX失败，避免失效消息到达前其他实例把旧数据写回L2
+	RepairL2 bool `json:"repair_l2,omitempty" yaml:"repair_l2,omitempty"`
+	// RepairTTL 写回L2时的过期时间，为0时使用L1中的剩余过期时间，避免写回的数据在L2中永不过期
+	RepairTTL time.Duration `json:"repair_ttl,omitempty" yaml:"repair_ttl,omitempty"`
+	// RepairInterval 同一个键两次写回L2的最小间隔，默认与L1TTL相同，间隔内的L1命中不再写回
+	RepairInterval time.Duration `json:"repair_interval,omitempty" yaml:"repair_interval,omitempty"`
+	// Channel L1失效消息的发布订阅频道，默认cache:tiered:invalidate，同一组实例必须一致
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// ObjectPool 批量获取解码目标对象的对象池，见WithObjectPool
+	ObjectPool *sync.Pool `json:"-" yaml:"-"`
+}
+
+// setDefaults 设置默认值
+func (c *TieredConfig) setDefaults() {
+	if c.L1TTL <= 0 {
+		c.L1TTL = time.Minute
+	}
+	if c.RepairInterval <= 0 {
+		c.RepairInterval = c.L1TTL
+	}
+	if c.Channel == "" {
+		c.Channel = defaultTieredChannel
+	}
+}
+
+// tieredMessage L1失效消息
+type tieredMessage struct {
+	// Source 发布消息的实例，实例忽略自己发布的消息
+	Source string `json:"src"`
+	// Prefix 上下文中的键前缀，为空时使用L1的默认前缀
+	Prefix string `json:"prefix,omitempty"`
+	// Keys 失效的键
+	Keys []string `json:"keys"`
+	// Tags 失效的标签
+	Tags []string `json:"tags,omitempty"`
+	// Patterns 失效的键匹配模式
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// TieredCache 两级缓存，L1为进程内的内存缓存，L2为Redis等共享缓存
+// 读取先查L1，L1未命中时读取L2并回填L1；写入和删除同时作用于两级，并通过Redis发布订阅通知其他实例删除L1
+// 计数器(IncrWithTTL)只存放在L2
+type TieredCache struct {
+	l1        ExtendedCache
+	l2        ExtendedCache
+	newObject func() interface{}
+	config    TieredConfig
+	client    redis.UniversalClient
+	source    string
+	onError   func(err error)
+	// fence L1写入栅栏，为空时不检查，见TrackedCache
+	fence *l1Fence
+	// background 后台回填和修复任务的并发信号量
+	background chan struct{}
+	// repairs 合并同一个键的并发写回
+	repairs flightGroup
+	// repairMu 保护repaired
+	repairMu sync.Mutex
+	// repaired 各个键最近一次写回L2的时间，用于限制写回频率
+	repaired map[string]time.Time
+	jobs     sync.WaitGroup
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTieredCache 创建两级缓存，l1通常由NewMemoryCache创建，l2通常是Redis缓存，两者使用相同的键前缀
+// client为空时不发布和订阅失效消息，只适用于单实例部署；需要调用Start开始订阅
+func NewTieredCache(l1, l2 Cache, newObject func() interface{}, client redis.UniversalClient, config TieredConfig) *TieredCache {
+	config.setDefaults()
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &TieredCache{
+		l1:         Extend(l1),
+		l2:         Extend(l2),
+		newObject:  newObject,
+		config:     config,
+		client:     client,
+		source:     hex.EncodeToString(id),
+		background: make(chan struct{}, tieredBackgroundLimit),
+		repaired:   make(map[string]time.Time),
+	}
+}
+
+// OnError 设置错误回调，如失效消息发布失败
+func (t *TieredCache) OnError(fn func(err error)) *TieredCache {
+	t.onError = fn
+	return t
+}
+
+// reportError 回调错误
+func (t *TieredCache) reportError(err error) {
+	if err != nil && t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// Start 订阅L1失效消息
+func (t *TieredCache) Start(ctx context.Context) error {
+	if t.client == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := t.client.Subscribe(ctx, t.config.Channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		cancel()
+		_ = sub.Close()
+		return err
+	}
+	t.cancel = cancel
+	t.wg.Add(1)
+	go t.consume(ctx, sub)
+	return nil
+}
+
+// consume 处理失效消息
+func (t *TieredCache) consume(ctx context.Context, sub *redis.PubSub) {
+	defer t.wg.Done()
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var m tieredMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				t.reportError(err)
+				continue
+			}
+			if m.Source != t.source {
+				m.apply(ctx, t.l1, t.reportError)
+			}
+		}
+	}
+}
+
+// Close 停止订阅，并等待后台回填和修复任务结束
+func (t *TieredCache) Close() error {
+	t.mu.Lock()
+	cancel := t.cancel
+	t.cancel = nil
+	t.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		t.wg.Wait()
+	}
+	t.jobs.Wait()
+	return nil
+}
+
+// publish 通知其他实例删除L1中的键
+func (t *TieredCache) publish(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	t.publishMessage(ctx, tieredMessage{Source: t.source, Prefix: KeyPrefixFromContext(ctx, ""), Keys: keys})
+}
+
+// publishTags 通知其他实例失效L1中的标签
+func (t *TieredCache) publishTags(ctx context.Context, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	t.publishMessage(ctx, tieredMessage{Source: t.source, Prefix: KeyPrefixFromContext(ctx, ""), Tags: tags})
+}
+
+// publishPatterns 通知其他实例删除L1中匹配模式的键
+func (t *TieredCache) publishPatterns(ctx context.Context, patterns ...string) {
+	if len(patterns) == 0 {
+		return
+	}
+	t.publishMessage(ctx, tieredMessage{Source: t.source, Prefix: KeyPrefixFromContext(ctx, ""), Patterns: patterns})
+}
+
+// publishMessage 发布失效消息
+func (t *TieredCache) publishMessage(ctx context.Context, m tieredMessage) {
+	if t.client == nil {
+		return
+	}
+	t.reportError(m.publish(ctx, t.client, t.config.Channel))
+}
+
+// publish 发布失效消息，键数量超过tieredPublishBatch时分为多条消息
+func (m tieredMessage) publish(ctx context.Context, client redis.UniversalClient, channel string) error {
+	var errs []error
+	keys := m.Keys
+	for {
+		n := min(len(keys), tieredPublishBatch)
+		m.Keys = keys[:n]
+		payload, err := json.Marshal(m)
+		if err == nil {
+			err = client.Publish(ctx, channel, payload).Err()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		keys = keys[n:]
+		if len(keys) == 0 {
+			return errors.Join(errs...)
+		}
+		// 标签和模式只随第一条消息发布
+		m.Tags, m.Patterns = nil, nil
+	}
+}
+
+// apply 在本地缓存中执行失效消息，错误通过report回调
+func (m tieredMessage) apply(ctx context.Context, local Cache, report func(err error)) {
+	ext := Extend(local)
+	if m.Prefix != "" {
+		ctx = WithKeyPrefix(ctx, m.Prefix)
+	}
+	if len(m.Keys) > 0 {
+		report(local.Del(ctx, m.Keys...))
+	}
+	for _, tag := range m.Tags {
+		_, err := ext.InvalidateTag(ctx, tag)
+		report(err)
+	}
+	for _, pattern := range m.Patterns {
+		_, err := ext.DelPattern(ctx, pattern)
+		report(err)
+	}
+}
+
+// l1TTL 计算L1的过期时间，l2TTL为0表示L2永不过期或未知
+func (t *TieredCache) l1TTL(l2TTL time.Duration) time.Duration {
+	ttl := t.config.L1TTL
+	if l2TTL > 0 {
+		if t.config.L1TTLFraction > 0 {
+			ttl = min(ttl, time.Duration(float64(l2TTL)*t.config.L1TTLFraction))
+		}
+		ttl = min(ttl, l2TTL)
+	}
+	return max(ttl, time.Millisecond)
+}
+
+// backfill L2命中后回填L1，启用AsyncBackfill时在后台读取L2的原始数据后回填，不使用调用方可能修改的val
+func (t *TieredCache) backfill(ctx context.Context, epoch uint64, key string, val interface{}, ttl time.Duration) {
+	if !t.config.AsyncBackfill {
+		if t.config.UseL2TTL {
+			ttl = t.l2TTL(ctx, key, ttl)
+		}
+		t.fill(ctx, epoch, func() { _ = t.l1.Set(ctx, key, val, t.l1TTL(ttl)) }, key)
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	t.spawn(func() {
+		var raw RawValue
+		if err := t.l2.Get(ctx, key, &raw); err != nil {
+			return
+		}
+		ttl := t.l2TTL(ctx, key, ttl)
+		t.fill(ctx, epoch, func() { _ = t.l1.Set(ctx, key, raw, t.l1TTL(ttl)) }, key)
+	})
+}
+
+// l2TTL 获取L2中的剩余过期时间，获取失败或永不过期时返回def
+func (t *TieredCache) l2TTL(ctx context.Context, key string, def time.Duration) time.Duration {
+	if ttl, err := t.l2.TTL(ctx, key); err == nil && ttl > 0 {
+		return ttl
+	}
+	return def
+}
+
+// repair L1命中后在后台用L1中的数据写回L2，L2中已有数据时不覆盖
+// 同一个键在RepairInterval内只写回一次，写回中的键合并为一次写入
+func (t *TieredCache) repair(ctx context.Context, key string) {
+	id := slotKey(ctx, key)
+	if !t.allowRepair(id) {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	started := t.spawn(func() {
+		_, _ = t.repairs.do(id, func() ([]byte, error) {
+			var raw RawValue
+			if err := t.l1.Get(ctx, key, &raw); err != nil {
+				return nil, nil
+			}
+			ttl := t.config.RepairTTL
+			if ttl <= 0 {
+				if ttl, _ = t.l1.TTL(ctx, key); ttl <= 0 {
+					ttl = t.config.L1TTL
+				}
+			}
+			_, err := t.l2.SetNX(ctx, key, raw, ttl)
+			t.reportError(err)
+			return nil, err
+		})
+	})
+	if !started {
+		// 后台任务达到上限时未写回，下次读取时再尝试
+		t.repairMu.Lock()
+		delete(t.repaired, id)
+		t.repairMu.Unlock()
+	}
+}
+
+// allowRepair 判断键是否可以写回L2并记录写回时间，距上次写回不足RepairInterval时返回false
+// 跟踪的键达到上限时先清理已过间隔的键，仍然达到上限时跳过
+func (t *TieredCache) allowRepair(id string) bool {
+	now := time.Now()
+	t.repairMu.Lock()
+	defer t.repairMu.Unlock()
+	if last, ok := t.repaired[id]; ok && now.Sub(last) < t.config.RepairInterval {
+		return false
+	}
+	if len(t.repaired) >= tieredRepairMaxKeys {
+		for k, last := range t.repaired {
+			if now.Sub(last) >= t.config.RepairInterval {
+				delete(t.repaired, k)
+			}
+		}
+		if len(t.repaired) >= tieredRepairMaxKeys {
+			return false
+		}
+	}
+	t.repaired[id] = now
+	return true
+}
+
+// spawn 在后台执行任务，后台任务达到上限时跳过并返回false
+func (t *TieredCache) spawn(fn func()) bool {
+	select {
+	case t.background <- struct{}{}:
+	default:
+		return false
+	}
+	t.jobs.Add(1)
+	go func() {
+		defer func() {
+			<-t.background
+			t.jobs.Done()
+		}()
+		fn()
+	}()
+	return true
+}
+
+// begin 读写L2之前记录L1的失效版本，未启用栅栏时为0
+func (t *TieredCache) begin() uint64 {
+	if t.fence == nil {
+		return 0
+	}
+	return t.fence.current()
+}
+
+// fill 写入L1，启用栅栏且读写L2之后有过失效时改为删除L1中的键，避免旧数据覆盖失效
+func (t *TieredCache) fill(ctx context.Context, epoch uint64, write func(), keys ...string) {
+	if t.fence == nil {
+		write()
+		return
+	}
+	if !t.fence.guard(epoch, write) {
+		_ = t.l1.Del(ctx, keys...)
+	}
+}
+
+// Set 设置数据，先写L2再写L1
+func (t *TieredCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	epoch := t.begin()
+	if err := t.l2.Set(ctx, key, val, expiration); err != nil {
+		_ = t.l1.Del(ctx, key)
+		return err
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.Set(ctx, key, val, t.l1TTL(expiration)) }, key)
+	t.publish(ctx, key)
+	return nil
+}
+
+// Get 获取数据，L1未命中时读取L2并回填L1
+func (t *TieredCache) Get(ctx context.Context, key string, val interface{}) error {
+	_, err := t.GetWithSource(ctx, key, val)
+	return err
+}
+
+// GetWithSource 获取数据并返回命中的层级，命中占位符或墓碑标记时也返回对应层级，其他错误时返回SourceNone
+func (t *TieredCache) GetWithSource(ctx context.Context, key string, val interface{}) (CacheSource, error) {
+	err := t.l1.Get(ctx, key, val)
+	if err == nil && t.config.RepairL2 {
+		t.repair(ctx, key)
+	}
+	if err == nil || errors.Is(err, ErrPlaceholder) || errors.Is(err, ErrTombstone) {
+		return SourceL1, err
+	}
+	epoch := t.begin()
+	err = t.l2.Get(ctx, key, val)
+	switch {
+	case err == nil:
+		t.backfill(ctx, epoch, key, val, 0)
+	case errors.Is(err, ErrPlaceholder):
+		t.fill(ctx, epoch, func() { _ = t.l1.SetCacheWithNotFound(ctx, key) }, key)
+	case !errors.Is(err, ErrTombstone):
+		return SourceNone, err
+	}
+	return SourceL2, err
+}
+
+// MultiSet 批量设置数据
+func (t *TieredCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	keys := make([]string, 0, len(valueMap))
+	for key := range valueMap {
+		keys = append(keys, key)
+	}
+	epoch := t.begin()
+	if err := t.l2.MultiSet(ctx, valueMap, expiration); err != nil {
+		_ = t.l1.Del(ctx, keys...)
+		return err
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.MultiSet(ctx, valueMap, t.l1TTL(expiration)) }, keys...)
+	t.publish(ctx, keys...)
+	return nil
+}
+
+// MultiGet 批量获取数据，结果以键(不含前缀)为map的键，L1未命中的键逐个从L2读取并回填L1
+func (t *TieredCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
+	valueMap := reflect.ValueOf(value)
+	for _, key := range keys {
+		object := acquireObject(t.config.ObjectPool, t.newObject)
+		if err := t.Get(ctx, key, object); err != nil {
+			releaseObject(t.config.ObjectPool, object)
+			continue
+		}
+		valueMap.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(object))
+	}
+	return nil
+}
+
+// Del 删除两级中的数据
+func (t *TieredCache) Del(ctx context.Context, keys ...string) error {
+	err := t.l2.Del(ctx, keys...)
+	_ = t.l1.Del(ctx, keys...)
+	t.publish(ctx, keys...)
+	return err
+}
+
+// SetCacheWithNotFound 在两级中设置未找到的缓存，指定过期时间时L1使用按L1过期时间修正后的值
+func (t *TieredCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	epoch := t.begin()
+	if err := t.l2.SetCacheWithNotFound(ctx, key, ttl...); err != nil {
+		_ = t.l1.Del(ctx, key)
+		return err
+	}
+	var l1TTL []time.Duration
+	if d := explicitNotFoundTTL(ttl); d > 0 {
+		l1TTL = []time.Duration{t.l1TTL(d)}
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.SetCacheWithNotFound(ctx, key, l1TTL...) }, key)
+	t.publish(ctx, key)
+	return nil
+}
+
+// MultiSetCacheWithNotFound 在两级中批量设置未找到的缓存
+func (t *TieredCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	epoch := t.begin()
+	if err := t.l2.MultiSetCacheWithNotFound(ctx, keys); err != nil {
+		_ = t.l1.Del(ctx, keys...)
+		return err
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.MultiSetCacheWithNotFound(ctx, keys) }, keys...)
+	t.publish(ctx, keys...)
+	return nil
+}
+
+// DelWithTombstone 删除两级中的数据并写入墓碑标记
+func (t *TieredCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	err := t.l2.DelWithTombstone(ctx, key, ttl)
+	_ = t.l1.DelWithTombstone(ctx, key, ttl)
+	t.publish(ctx, key)
+	return err
+}
+
+// IncrWithTTL 在L2中原子自增，并删除L1中的旧值
+func (t *TieredCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	n, err := t.l2.IncrWithTTL(ctx, key, delta, ttl)
+	_ = t.l1.Del(ctx, key)
+	t.publish(ctx, key)
+	return n, err
+}
+
+// DelMany 分片批量删除两级中的大量键
+func (t *TieredCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	err := t.l2.DelMany(ctx, keys, opts)
+	_ = t.l1.DelMany(ctx, keys, DelManyOptions{})
+	t.publish(ctx, keys...)
+	return err
+}
+
+// Describe 获取L2中缓存条目的元数据
+func (t *TieredCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	return t.l2.Describe(ctx, key)
+}
+
+// GetOrSet 获取数据，两级都未命中时通过L2读穿并回填L1，命中墓碑标记时加载的数据不回填
+// 强制刷新时跳过两级读取，L2覆盖后更新L1并通知其他实例删除L1
+func (t *TieredCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	options := newGetOrSetOptions(opts)
+	options.setSource(SourceNone)
+	backfill := true
+	if !options.forceRefresh {
+		source, err := t.GetWithSource(ctx, key, dest)
+		switch {
+		case err == nil:
+			options.setSource(source)
+			return nil
+		case errors.Is(err, ErrPlaceholder):
+			options.setSource(source)
+			return ErrNotFoundCached
+		}
+		backfill = !errors.Is(err, ErrTombstone)
+	}
+	// L2的GetOrSet命中时记为SourceL1，需要转换为SourceL2
+	var l2Source CacheSource
+	epoch := t.begin()
+	err := t.l2.GetOrSet(ctx, key, dest, ttl, loader, append(opts[:len(opts):len(opts)], WithSource(&l2Source))...)
+	if l2Source == SourceL1 {
+		l2Source = SourceL2
+	}
+	options.setSource(l2Source)
+	switch {
+	case err == nil && backfill:
+		t.backfill(ctx, epoch, key, dest, ttl)
+	case errors.Is(err, ErrNotFoundCached):
+		t.fill(ctx, epoch, func() { _ = t.l1.SetCacheWithNotFound(ctx, key) }, key)
+	}
+	if options.forceRefresh {
+		t.publish(ctx, key)
+	}
+	return err
+}
+
+// DelDelayed 立即删除两级中的数据，delay后再删除一次
+func (t *TieredCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	return delDelayed(ctx, t, key, delay)
+}
+
+// TTL 获取L2中的剩余过期时间
+func (t *TieredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.l2.TTL(ctx, key)
+}
+
+// Expire 重设L2中的过期时间并删除L1中的数据，下次读取时按新的过期时间回填L1
+func (t *TieredCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	err := t.l2.Expire(ctx, key, ttl)
+	_ = t.l1.Del(ctx, key)
+	t.publish(ctx, key)
+	return err
+}
+
+// Exists L2中键是否存在
+func (t *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	return t.l2.Exists(ctx, key)
+}
+
+// SetNX 仅在L2中键不存在时设置数据，写入成功后写L1并通知其他实例删除L1
+func (t *TieredCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	epoch := t.begin()
+	ok, err := t.l2.SetNX(ctx, key, val, expiration)
+	if err != nil || !ok {
+		return ok, err
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.Set(ctx, key, val, t.l1TTL(expiration)) }, key)
+	t.publish(ctx, key)
+	return true, nil
+}
+
+// SetWithTags 在两级中设置数据并加入标签，先写L2再写L1
+func (t *TieredCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	epoch := t.begin()
+	if err := t.l2.SetWithTags(ctx, key, val, expiration, tags...); err != nil {
+		_ = t.l1.Del(ctx, key)
+		return err
+	}
+	t.fill(ctx, epoch, func() { _ = t.l1.SetWithTags(ctx, key, val, t.l1TTL(expiration), tags...) }, key)
+	t.publish(ctx, key)
+	return nil
+}
+
+// InvalidateTag 删除两级中标签下的所有键，并通知其他实例失效L1中的标签，返回L2的失效报告
+func (t *TieredCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	report, err := t.l2.InvalidateTag(ctx, tag)
+	_, _ = t.l1.InvalidateTag(ctx, tag)
+	t.publishTags(ctx, tag)
+	return report, err
+}
+
+// PreviewInvalidateTag 返回L2中标签下的键，不删除数据
+func (t *TieredCache) PreviewInvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	return t.l2.PreviewInvalidateTag(ctx, tag)
+}
+
+// DelPattern 删除两级中匹配模式的键，并通知其他实例删除L1中匹配的键，返回L2中删除的键数量
+// L1使用不支持遍历键的引擎(如ristretto)时无法删除，L1中的旧数据在L1过期时间内仍可能被读到
+func (t *TieredCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	n, err := t.l2.DelPattern(ctx, pattern)
+	_, _ = t.l1.DelPattern(ctx, pattern)
+	t.publishPatterns(ctx, pattern)
+	return n, err
+}
+
+// Copy 在L2中复制键并删除L1中的目标键，通知其他实例删除L1，下次读取时回填
+func (t *TieredCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	err := t.l2.Copy(ctx, src, dst, preserveTTL)
+	_ = t.l1.Del(ctx, dst)
+	t.publish(ctx, dst)
+	return err
+}
+
+// Rename 在L2中重命名键并删除L1中的两个键，通知其他实例删除L1
+func (t *TieredCache) Rename(ctx context.Context, src, dst string) error {
+	err := t.l2.Rename(ctx, src, dst)
+	_ = t.l1.Del(ctx, src, dst)
+	t.publish(ctx, src, dst)
+	return err
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestTieredCache(t *testing.T, config TieredConfig) (tiered *TieredCache, l1, l2 Cache) {
+	t.Helper()
+	l1, l2 = newTestMemoryCache(t), newTestMemoryCache(t)
+	tiered = NewTieredCache(l1, l2, func() interface{} { return new(string) }, nil, config)
+	return tiered, l1, l2
+}
+
+func TestTieredAsyncBackfill(t *testing.T) {
+	tiered, l1, l2 := newTestTieredCache(t, TieredConfig{L1TTL: time.Hour, AsyncBackfill: true})
+	ctx := context.Background()
+	val := "v"
+	if err := l2.Set(ctx, "k", &val, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	source, err := tiered.GetWithSource(ctx, "k", &got)
+	if err != nil || source != SourceL2 || got != "v" {
+		t.Fatalf("GetWithSource() = %s, %q, %v, want l2 v", source, got, err)
+	}
+	_ = tiered.Close()
+
+	var backfilled string
+	if err := l1.Get(ctx, "k", &backfilled); err != nil || backfilled != "v" {
+		t.Fatalf("L1 Get() = %q, %v, want v", backfilled, err)
+	}
+	ttl, err := l1.TTL(ctx, "k")
+	if err != nil || ttl <= 0 || ttl > 10*time.Second {
+		t.Errorf("L1 TTL() = %v, %v, want L2剩余过期时间", ttl, err)
+	}
+}
+
+func TestTieredRepairL2(t *testing.T) {
+	tiered, l1, l2 := newTestTieredCache(t, TieredConfig{RepairL2: true, RepairTTL: time.Minute})
+	ctx := context.Background()
+	val := "v"
+	if err := l1.Set(ctx, "k", &val, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	source, err := tiered.GetWithSource(ctx, "k", &got)
+	if err != nil || source != SourceL1 {
+		t.Fatalf("GetWithSource() = %s, %v, want l1", source, err)
+	}
+	_ = tiered.Close()
+
+	var repaired string
+	if err := l2.Get(ctx, "k", &repaired); err != nil || repaired != "v" {
+		t.Fatalf("L2 Get() = %q, %v, want v", repaired, err)
+	}
+	ttl, err := l2.TTL(ctx, "k")
+	if err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("L2 TTL() = %v, %v, want RepairTTL", ttl, err)
+	}
+}
+
+func TestTieredRepairL2KeepsExisting(t *testing.T) {
+	tiered, l1, l2 := newTestTieredCache(t, TieredConfig{RepairL2: true})
+	ctx := context.Background()
+	stale, fresh := "stale", "fresh"
+	if err := l1.Set(ctx, "k", &stale, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.Set(ctx, "k", &fresh, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.SetCacheWithNotFound(ctx, "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Set(ctx, "missing", &stale, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := Extend(l2).DelWithTombstone(ctx, "deleted", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Set(ctx, "deleted", &stale, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"k", "missing", "deleted"} {
+		var got string
+		_ = tiered.Get(ctx, key, &got)
+	}
+	_ = tiered.Close()
+
+	var got string
+	if err := l2.Get(ctx, "k", &got); err != nil || got != "fresh" {
+		t.Errorf("L2 Get(k) = %q, %v, want fresh", got, err)
+	}
+	if err := l2.Get(ctx, "missing", &got); !errors.Is(err, ErrPlaceholder) {
+		t.Errorf("L2 Get(missing) error = %v, want ErrPlaceholder", err)
+	}
+	if err := l2.Get(ctx, "deleted", &got); !errors.Is(err, ErrTombstone) {
+		t.Errorf("L2 Get(deleted) error = %v, want ErrTombstone", err)
+	}
+}
+
+func TestTieredRepairL2DefaultTTLAndInterval(t *testing.T) {
+	tiered, l1, l2 := newTestTieredCache(t, TieredConfig{RepairL2: true})
+	ctx := context.Background()
+	val := "v"
+	if err := l1.Set(ctx, "k", &val, 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	if err := tiered.Get(ctx, "k", &got); err != nil {
+		t.Fatal(err)
+	}
+	tiered.jobs.Wait()
+	ttl, err := l2.TTL(ctx, "k")
+	if err != nil || ttl <= 0 || ttl > 30*time.Second {
+		t.Errorf("L2 TTL() = %v, %v, want L1剩余过期时间", ttl, err)
+	}
+
+	// RepairInterval内再次命中L1不写回
+	if err := l2.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := tiered.Get(ctx, "k", &got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = tiered.Close()
+	if err := l2.Get(ctx, "k", &got); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("L2 Get() error = %v, want ErrCacheNotFound", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// tombstoneTTL 获取墓碑标记的过期时间
+func tombstoneTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultTombstoneExpireTime
+	}
+	return ttl
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+// 墓碑存在期间Get返回ErrTombstone，避免读穿加载器立即用旧数据回填刚被删除的键
+func (m *memoryCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, m.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	ok := m.client.SetWithTTL(cacheKey, TombstonePlaceholderBytes, 0, tombstoneTTL(ttl))
+	if !ok {
+		// 写入被拒绝时至少保证旧数据被删除
+		m.client.Del(cacheKey)
+		return fmt.Errorf("%w: SetWithTTL失败", ErrBackend)
+	}
+	m.client.Wait()
+	return nil
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记，覆盖写入即完成删除
+func (c *redisCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	err = c.client.Set(ctx, cacheKey, TombstonePlaceholder, tombstoneTTL(ttl)).Err()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置墓碑错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记，覆盖写入即完成删除
+func (c *redisClusterCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	cacheKey, err := BuildCacheKey(KeyPrefixFromContext(ctx, c.KeyPrefix), key)
+	if err != nil {
+		return fmt.Errorf("%w: %w, 键=%s", ErrKeyBuild, err, key)
+	}
+	err = c.client.Set(ctx, cacheKey, TombstonePlaceholder, tombstoneTTL(ttl)).Err()
+	if err != nil {
+		return fmt.Errorf("%w: 客户端设置墓碑错误: %w, 缓存键=%s", ErrBackend, err, cacheKey)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTracedKeys 批量操作在span中记录的最大键数量，超过时只记录前面的键和总数
+const maxTracedKeys = 16
+
+// tracingCache 带链路追踪的缓存包装
+type tracingCache struct {
+	forwarder
+	backend CacheType
+	tracer  trace.Tracer
+}
+
+// WithTracing 为缓存添加OpenTelemetry链路追踪，每个操作创建一个子span
+// span名称为cache.<操作名称>，记录键、后端、命中情况和错误，未找到不记为错误
+func WithTracing(c Cache, backend CacheType, tracer trace.Tracer) Cache {
+	if tracer == nil {
+		return c
+	}
+	return &tracingCache{
+		forwarder: forwarder{c},
+		backend:   backend,
+		tracer:    tracer,
+	}
+}
+
+// start 创建操作span
+func (t *tracingCache) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("cache.backend", string(t.backend)),
+		attribute.String("cache.operation", op),
+	)
+	return t.tracer.Start(ctx, "cache."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// end 记录错误并结束span，未找到不记为错误
+func (t *tracingCache) end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrCacheNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// keyAttr 单键属性
+func keyAttr(key string) attribute.KeyValue {
+	return attribute.String("cache.key", key)
+}
+
+// keysAttrs 批量键属性，键过多时截断
+func keysAttrs(keys []string) []attribute.KeyValue {
+	traced := keys
+	if len(traced) > maxTracedKeys {
+		traced = traced[:maxTracedKeys]
+	}
+	return []attribute.KeyValue{
+		attribute.StringSlice("cache.keys", traced),
+		attribute.Int("cache.key_count", len(keys)),
+	}
+}
+
+// Set 设置数据
+func (t *tracingCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	ctx, span := t.start(ctx, OpSet, keyAttr(key))
+	err := t.Cache.Set(ctx, key, val, expiration)
+	t.end(span, err)
+	return err
+}
+
+// Get 获取数据，占位符和墓碑记为命中
+func (t *tracingCache) Get(ctx context.Context, key string, val interface{}) error {
+	ctx, span := t.start(ctx, OpGet, keyAttr(key))
+	err := t.Cache.Get(ctx, key, val)
+	hit := err == nil || errors.Is(err, ErrPlaceholder) || errors.Is(err, ErrTombstone)
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+	t.end(span, err)
+	return err
+}
+
+// MultiSet 批量设置数据
+func (t *tracingCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	keys := make([]string, 0, len(valMap))
+	for key := range valMap {
+		keys = append(keys, key)
+	}
+	ctx, span := t.start(ctx, OpMultiSet, keysAttrs(keys)...)
+	err := t.Cache.MultiSet(ctx, valMap, expiration)
+	t.end(span, err)
+	return err
+}
+
+// MultiGet 批量获取数据，按写入map的条目数记录命中数
+func (t *tracingCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	ctx, span := t.start(ctx, OpMultiGet, keysAttrs(keys)...)
+	before := mapLen(valueMap)
+	err := t.Cache.MultiGet(ctx, keys, valueMap)
+	if err == nil {
+		hits := mapLen(valueMap) - before
+		span.SetAttributes(
+			attribute.Int("cache.hits", hits),
+			attribute.Int("cache.misses", len(keys)-hits),
+		)
+	}
+	t.end(span, err)
+	return err
+}
+
+// Del 删除数据
+func (t *tracingCache) Del(ctx context.Context, keys ...string) error {
+	ctx, span := t.start(ctx, OpDel, keysAttrs(keys)...)
+	err := t.Cache.Del(ctx, keys...)
+	t.end(span, err)
+	return err
+}
+
+// SetCacheWithNotFound 设置未找到的缓存
+func (t *tracingCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	ctx, span := t.start(ctx, OpSetCacheWithNotFound, keyAttr(key))
+	err := t.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+	t.end(span, err)
+	return err
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存
+func (t *tracingCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	ctx, span := t.start(ctx, OpMultiSetCacheWithNotFound, keysAttrs(keys)...)
+	err := t.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+	t.end(span, err)
+	return err
+}
+
+// DelWithTombstone 删除数据并写入短期墓碑标记
+func (t *tracingCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	ctx, span := t.start(ctx, OpDelWithTombstone, keyAttr(key))
+	err := t.forwarder.DelWithTombstone(ctx, key, ttl)
+	t.end(span, err)
+	return err
+}
+
+// IncrWithTTL 原子自增
+func (t *tracingCache) IncrWithTTL(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ctx, span := t.start(ctx, OpIncrWithTTL, keyAttr(key))
+	v, err := t.forwarder.IncrWithTTL(ctx, key, delta, ttl)
+	t.end(span, err)
+	return v, err
+}
+
+// DelMany 分片批量删除大量键
+func (t *tracingCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	ctx, span := t.start(ctx, OpDelMany, keysAttrs(keys)...)
+	err := t.forwarder.DelMany(ctx, keys, opts)
+	t.end(span, err)
+	return err
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数并回填缓存，加载函数的span是本操作span的子span
+func (t *tracingCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	ctx, span := t.start(ctx, OpGetOrSet, keyAttr(key))
+	err := t.Cache.GetOrSet(ctx, key, dest, ttl, loader, opts...)
+	spanErr := err
+	if errors.Is(err, ErrNotFoundCached) || errors.Is(err, ErrNotFound) {
+		spanErr = nil
+	}
+	t.end(span, spanErr)
+	return err
+}
+
+// DelDelayed 立即删除数据，delay后再删除一次
+func (t *tracingCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	ctx, span := t.start(ctx, OpDelDelayed, keyAttr(key))
+	err := t.forwarder.DelDelayed(ctx, key, delay)
+	t.end(span, err)
+	return err
+}
+
+// TTL 获取剩余过期时间
+func (t *tracingCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ctx, span := t.start(ctx, OpTTL, keyAttr(key))
+	ttl, err := t.Cache.TTL(ctx, key)
+	t.end(span, err)
+	return ttl, err
+}
+
+// Expire 重设过期时间
+func (t *tracingCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	ctx, span := t.start(ctx, OpExpire, keyAttr(key))
+	err := t.Cache.Expire(ctx, key, ttl)
+	t.end(span, err)
+	return err
+}
+
+// Exists 键是否存在，存在时记为命中
+func (t *tracingCache) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, span := t.start(ctx, OpExists, keyAttr(key))
+	ok, err := t.forwarder.Exists(ctx, key)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("cache.hit", ok))
+	}
+	t.end(span, err)
+	return ok, err
+}
+
+// SetNX 仅在键不存在时设置数据
+func (t *tracingCache) SetNX(ctx context.Context, key string, val interface{}, expiration time.Duration) (bool, error) {
+	ctx, span := t.start(ctx, OpSetNX, keyAttr(key))
+	ok, err := t.forwarder.SetNX(ctx, key, val, expiration)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("cache.set", ok))
+	}
+	t.end(span, err)
+	return ok, err
+}
+
+// SetWithTags 设置数据并加入标签
+func (t *tracingCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	ctx, span := t.start(ctx, OpSetWithTags, keyAttr(key), attribute.StringSlice("cache.tags", tags))
+	err := t.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+	t.end(span, err)
+	return err
+}
+
+// InvalidateTag 删除标签下的所有键
+func (t *tracingCache) InvalidateTag(ctx context.Context, tag string) (*TagInvalidationReport, error) {
+	ctx, span := t.start(ctx, OpInvalidateTag, attribute.String("cache.tag", tag))
+	report, err := t.forwarder.InvalidateTag(ctx, tag)
+	if report != nil {
+		span.SetAttributes(attribute.Int("cache.deleted", report.Keys), attribute.Int64("cache.bytes_freed", report.Bytes))
+	}
+	t.end(span, err)
+	return report, err
+}
+
+// DelPattern 删除匹配模式的键
+func (t *tracingCache) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	ctx, span := t.start(ctx, OpDelPattern, attribute.String("cache.pattern", pattern))
+	n, err := t.forwarder.DelPattern(ctx, pattern)
+	span.SetAttributes(attribute.Int64("cache.deleted", n))
+	t.end(span, err)
+	return n, err
+}
+
+// Copy 复制键到目标键
+func (t *tracingCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	ctx, span := t.start(ctx, OpCopy, keyAttr(src), attribute.String("cache.dst", dst), attribute.Bool("cache.preserve_ttl", preserveTTL))
+	err := t.forwarder.Copy(ctx, src, dst, preserveTTL)
+	t.end(span, err)
+	return err
+}
+
+// Rename 重命名键
+func (t *tracingCache) Rename(ctx context.Context, src, dst string) error {
+	ctx, span := t.start(ctx, OpRename, keyAttr(src), attribute.String("cache.dst", dst))
+	err := t.forwarder.Rename(ctx, src, dst)
+	t.end(span, err)
+	return err
+}
+
+// Describe 获取缓存条目的元数据
+func (t *tracingCache) Describe(ctx context.Context, key string) (EntryInfo, error) {
+	ctx, span := t.start(ctx, OpDescribe, keyAttr(key))
+	info, err := t.forwarder.Describe(ctx, key)
+	t.end(span, err)
+	return info, err
+}
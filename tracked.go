@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// trackingChannel Redis客户端缓存失效消息的频道，重定向的目标连接通过订阅该频道接收失效消息
+const trackingChannel = "__redis__:invalidate"
+
+// trackingPingInterval 跟踪连接的检查间隔，跟踪连接断开后最晚在该时间内重新开启跟踪并清空L1
+const trackingPingInterval = 10 * time.Second
+
+// l1Fence L1写入栅栏，读写L2之后、写入L1之前到达的失效使本次L1写入改为删除，避免旧数据覆盖失效
+// 版本在任何失效时推进，键前缀下写入频繁时L1写入会经常被跳过
+type l1Fence struct {
+	mu    sync.RWMutex
+	epoch uint64
+}
+
+// current 当前失效版本
+func (f *l1Fence) current() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.epoch
+}
+
+// guard 失效版本未变化时执行写入，写入期间不会处理失效，返回是否已写入
+func (f *l1Fence) guard(epoch uint64, write func()) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.epoch != epoch {
+		return false
+	}
+	write()
+	return true
+}
+
+// invalidate 推进失效版本并执行失效
+func (f *l1Fence) invalidate(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.epoch++
+	fn()
+}
+
+// TrackedCache 基于Redis客户端缓存(CLIENT TRACKING)的两级缓存，L1为进程内的内存缓存，L2为Redis
+// 使用广播模式按键前缀跟踪，任何客户端修改前缀下的键时Redis主动发送失效消息，不需要应用层发布订阅
+// 失效消息重定向到单独的RESP2订阅连接，数据连接可以使用RESP2或RESP3；只支持Redis单机，需要Redis 6.0及以上
+// 订阅连接或跟踪连接重连时清空L1，上下文中的键前缀与keyPrefix不同时，这些键的变更不会使L1失效
+type TrackedCache struct {
+	*TieredCache
+	store      MemoryStore
+	client     *redis.Client
+	tracking   *redis.Client
+	subscriber *redis.Client
+	prefix     string
+	fence      *l1Fence
+	redirect   atomic.Int64
+	resync     chan struct{}
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTrackedCache 创建基于Redis客户端缓存的两级缓存，需要调用Start开启跟踪
+// memConfig为空时使用默认内存配置，config的Channel不使用
+func NewTrackedCache(redisConfig *RedisConfig, memConfig *MemoryConfig, keyPrefix string, encoding Encoding, newObject func() interface{}, config TieredConfig, opts ...CacheOption) (*TrackedCache, error) {
+	if redisConfig == nil {
+		return nil, fmt.Errorf("Redis配置不能为空")
+	}
+	if err := redisConfig.ProxyMode.check("client"); err != nil {
+		return nil, err
+	}
+	if !redisConfig.Compat.clientTracking() {
+		return nil, fmt.Errorf("%w: 已禁用CLIENT TRACKING", ErrIncompatible)
+	}
+	if memConfig == nil {
+		memConfig = defaultMemoryConfig()
+	}
+	store, err := newMemoryStore(memConfig, false)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TrackedCache{
+		store:  store,
+		client: redis.NewClient(redisConfig.options(redisConfig.DB)),
+		fence:  &l1Fence{},
+		resync: make(chan struct{}, 1),
+	}
+	if keyPrefix != "" {
+		t.prefix = keyPrefix + ":"
+	}
+
+	// 跟踪连接只有一个且不过期，建立连接时开启跟踪
+	trackingOpts := redisConfig.options(redisConfig.DB)
+	trackingOpts.PoolSize = 1
+	trackingOpts.MinIdleConns = 0
+	trackingOpts.ConnMaxLifetime = 0
+	trackingOpts.ConnMaxIdleTime = -1
+	trackingOpts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		if err := cn.Do(ctx, t.trackingArgs()...).Err(); err != nil {
+			return err
+		}
+		t.clear()
+		return nil
+	}
+	t.tracking = redis.NewClient(trackingOpts)
+
+	// 订阅连接使用RESP2，失效消息以发布订阅消息的形式送达
+	subscriberOpts := redisConfig.options(redisConfig.DB)
+	subscriberOpts.Protocol = ProtocolRESP2
+	subscriberOpts.PoolSize = 1
+	subscriberOpts.MinIdleConns = 0
+	subscriberOpts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return err
+		}
+		t.redirect.Store(id)
+		// 重连后跟踪连接仍重定向到旧连接，需要重新开启跟踪
+		select {
+		case t.resync <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	t.subscriber = redis.NewClient(subscriberOpts)
+
+	l1 := &memoryCache{
+		client:       store,
+		KeyPrefix:    keyPrefix,
+		cacheOptions: newCacheOptions(&Config{}, encoding, opts),
+		newObject:    newObject,
+	}
+	l2 := NewRedisCache(t.client, keyPrefix, encoding, newObject, opts...)
+	t.TieredCache = NewTieredCache(l1, l2, newObject, nil, config)
+	t.TieredCache.fence = t.fence
+	return t, nil
+}
+
+// OnError 设置错误回调，如重新开启跟踪失败
+func (t *TrackedCache) OnError(fn func(err error)) *TrackedCache {
+	t.TieredCache.OnError(fn)
+	return t
+}
+
+// trackingArgs 开启广播模式跟踪的命令参数
+func (t *TrackedCache) trackingArgs() []interface{} {
+	args := []interface{}{"CLIENT", "TRACKING", "on", "REDIRECT", strconv.FormatInt(t.redirect.Load(), 10), "BCAST"}
+	if t.prefix != "" {
+		args = append(args, "PREFIX", t.prefix)
+	}
+	return args
+}
+
+// register 在跟踪连接上重新开启跟踪，重定向到当前的订阅连接
+func (t *TrackedCache) register(ctx context.Context) error {
+	if err := t.tracking.Do(ctx, "CLIENT", "TRACKING", "off").Err(); err != nil {
+		return fmt.Errorf("%w: 关闭跟踪错误: %w", ErrBackend, err)
+	}
+	if err := t.tracking.Do(ctx, t.trackingArgs()...).Err(); err != nil {
+		return fmt.Errorf("%w: 开启跟踪错误: %w", ErrBackend, err)
+	}
+	// 关闭和开启之间的失效消息已丢失
+	t.clear()
+	return nil
+}
+
+// clear 清空L1
+func (t *TrackedCache) clear() {
+	t.fence.invalidate(func() {
+		if clearer, ok := t.store.(MemoryClearer); ok {
+			clearer.Clear()
+		}
+	})
+}
+
+// Start 订阅失效消息并开启跟踪
+func (t *TrackedCache) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := t.subscriber.Subscribe(ctx, trackingChannel)
+	if _, err := sub.Receive(ctx); err != nil {
+		cancel()
+		_ = sub.Close()
+		return err
+	}
+	// 订阅连接建立时已请求重新开启跟踪，这里同步执行以返回错误
+	<-t.resync
+	if err := t.register(ctx); err != nil {
+		cancel()
+		_ = sub.Close()
+		return err
+	}
+	t.cancel = cancel
+	t.wg.Add(1)
+	go t.consume(ctx, sub)
+	return nil
+}
+
+// consume 处理失效消息，订阅连接重连后重新开启跟踪，并定期检查跟踪连接
+func (t *TrackedCache) consume(ctx context.Context, sub *redis.PubSub) {
+	defer t.wg.Done()
+	defer sub.Close()
+	ch := sub.Channel()
+	ticker := time.NewTicker(trackingPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.resync:
+			t.reportError(t.register(ctx))
+		case <-ticker.C:
+			// 跟踪连接断开时Ping使用新连接，建立连接时重新开启跟踪
+			t.reportError(t.tracking.Ping(ctx).Err())
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			keys := msg.PayloadSlice
+			if msg.Payload != "" {
+				keys = append(keys, msg.Payload)
+			}
+			t.fence.invalidate(func() {
+				for _, key := range keys {
+					t.store.Del(key)
+				}
+			})
+		}
+	}
+}
+
+// Close 停止订阅并关闭所有连接和L1
+func (t *TrackedCache) Close() error {
+	t.mu.Lock()
+	cancel := t.cancel
+	t.cancel = nil
+	t.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		t.wg.Wait()
+	}
+	err := t.client.Close()
+	_ = t.tracking.Close()
+	_ = t.subscriber.Close()
+	t.store.Close()
+	return err
+}
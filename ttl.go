@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ErrZeroTTL 过期时间为0且策略为拒绝
+var ErrZeroTTL = errors.New("过期时间不能为0")
+
+// ZeroTTLPolicy 过期时间为0时的处理策略
+type ZeroTTLPolicy string
+
+const (
+	// ZeroTTLNoExpiry 永不过期，与历史行为一致，为默认策略
+	ZeroTTLNoExpiry ZeroTTLPolicy = "no_expiry"
+	// ZeroTTLDefault 使用默认过期时间
+	ZeroTTLDefault ZeroTTLPolicy = "default"
+	// ZeroTTLReject 拒绝写入，返回ErrZeroTTL
+	ZeroTTLReject ZeroTTLPolicy = "reject"
+)
+
+// TTLClampFunc 请求的过期时间被修正时的回调，用于记录告警
+type TTLClampFunc func(key string, requested, applied time.Duration)
+
+// ttlPolicy 过期时间策略
+type ttlPolicy struct {
+	// min 最小过期时间，0表示不限制
+	min time.Duration
+	// max 最大过期时间，0表示不限制
+	max time.Duration
+	// zero 过期时间为0时的处理策略
+	zero ZeroTTLPolicy
+	// defaultTTL 策略为ZeroTTLDefault时使用的过期时间
+	defaultTTL time.Duration
+	// notFound 未找到占位符的过期时间，0表示使用包级DefaultNotFoundExpireTime
+	notFound time.Duration
+	// notFoundMax 未找到占位符指数退避的最大过期时间，不大于notFound时不退避
+	notFoundMax time.Duration
+	// onClamp 过期时间被修正时的回调
+	onClamp TTLClampFunc
+	// jitter 过期时间随机抖动的比例，0表示不抖动
+	jitter float64
+}
+
+// newTTLPolicy 根据配置创建过期时间策略
+func newTTLPolicy(config *Config) ttlPolicy {
+	defaultTTL := config.DefaultExpireTime
+	if defaultTTL <= 0 {
+		defaultTTL = DefaultExpireTime
+	}
+	return ttlPolicy{
+		min:         config.MinTTL,
+		max:         config.MaxTTL,
+		zero:        config.ZeroTTLPolicy,
+		defaultTTL:  defaultTTL,
+		notFound:    config.NotFoundExpireTime,
+		notFoundMax: config.NotFoundMaxTTL,
+		onClamp:     config.OnTTLClamped,
+	}
+}
+
+// WithNotFoundExpireTime 设置当前缓存实例未找到占位符的过期时间，覆盖包级DefaultNotFoundExpireTime
+func WithNotFoundExpireTime(d time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.notFound = d
+	}
+}
+
+// explicitNotFoundTTL SetCacheWithNotFound指定的过期时间，未指定或不大于0时返回0
+func explicitNotFoundTTL(ttl []time.Duration) time.Duration {
+	if len(ttl) == 0 || ttl[0] <= 0 {
+		return 0
+	}
+	return ttl[0]
+}
+
+// WithDefaultExpireTime 过期时间为0时使用默认过期时间d，等同于Config.ZeroTTLPolicy为default
+// 用于直接通过NewMemoryCache、NewRedisCache等构造函数创建的缓存，d不大于0时使用包级DefaultExpireTime
+func WithDefaultExpireTime(d time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		if d <= 0 {
+			d = DefaultExpireTime
+		}
+		o.zero = ZeroTTLDefault
+		o.defaultTTL = d
+	}
+}
+
+// WithTTLJitter 写入时将过期时间随机调整±percent%，避免批量写入的数据同时过期导致回源压力集中
+// percent不大于0时不抖动，超过100时按100处理；抖动在MinTTL和MaxTTL修正之后进行，结果仍不超出该范围
+// 未找到占位符有单独的过期时间，不受影响
+func WithTTLJitter(percent float64) CacheOption {
+	return func(o *cacheOptions) {
+		o.jitter = max(min(percent, 100), 0) / 100
+	}
+}
+
+// validateTTLConfig 校验过期时间相关配置
+func validateTTLConfig(config *Config) error {
+	switch config.ZeroTTLPolicy {
+	case "", ZeroTTLNoExpiry, ZeroTTLDefault, ZeroTTLReject:
+	default:
+		return fmt.Errorf("不支持的零过期时间策略: %s", config.ZeroTTLPolicy)
+	}
+	if config.MinTTL < 0 || config.MaxTTL < 0 || config.NotFoundExpireTime < 0 || config.NotFoundMaxTTL < 0 {
+		return fmt.Errorf("MinTTL、MaxTTL、NotFoundExpireTime和NotFoundMaxTTL不能为负数")
+	}
+	if config.MaxTTL > 0 && config.MinTTL > config.MaxTTL {
+		return fmt.Errorf("MinTTL(%s)不能大于MaxTTL(%s)", config.MinTTL, config.MaxTTL)
+	}
+	return nil
+}
+
+// notFoundExpiration 未找到占位符的过期时间
+func (p *ttlPolicy) notFoundExpiration() time.Duration {
+	if p.notFound > 0 {
+		return p.notFound
+	}
+	return DefaultNotFoundExpireTime
+}
+
+// expiration 按策略修正请求的过期时间并随机抖动，keys用于回调时定位写入方
+// 返回0表示永不过期
+func (p *ttlPolicy) expiration(requested time.Duration, keys ...string) (time.Duration, error) {
+	applied, err := p.clamp(requested, keys...)
+	if err != nil {
+		return 0, err
+	}
+	return p.jittered(applied), nil
+}
+
+// clamp 按策略修正请求的过期时间，不抖动，批量写入时配合jittered逐个键抖动
+// 返回0表示永不过期
+func (p *ttlPolicy) clamp(requested time.Duration, keys ...string) (time.Duration, error) {
+	if requested <= 0 {
+		switch p.zero {
+		case ZeroTTLDefault:
+			requested = p.defaultTTL
+		case ZeroTTLReject:
+			return 0, fmt.Errorf("%w: 键=%v", ErrZeroTTL, keys)
+		default:
+			return 0, nil
+		}
+	}
+
+	applied := requested
+	if p.min > 0 && applied < p.min {
+		applied = p.min
+	}
+	if p.max > 0 && applied > p.max {
+		applied = p.max
+	}
+	if applied != requested && p.onClamp != nil {
+		for _, key := range keys {
+			p.onClamp(key, requested, applied)
+		}
+	}
+	return applied, nil
+}
+
+// jittered 将过期时间随机调整±jitter比例，永不过期时不调整，结果不小于1毫秒且不超出MinTTL和MaxTTL
+func (p *ttlPolicy) jittered(ttl time.Duration) time.Duration {
+	if p.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	ttl += time.Duration(float64(ttl) * p.jitter * (2*rand.Float64() - 1))
+	if p.min > 0 && ttl < p.min {
+		ttl = p.min
+	}
+	if p.max > 0 && ttl > p.max {
+		ttl = p.max
+	}
+	return max(ttl, time.Millisecond)
+}
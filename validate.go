@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithValidator 设置解码后的校验函数，key为调用方传入的键，v为解码后的对象
+// 校验失败的条目视为未命中，Get返回ErrCacheNotFound并删除该条目，MultiGet跳过该条目，以*RawValue读取时不校验，
+// 用于在数据到达业务逻辑前发现结构变更或损坏的数据；设置了WithDecodeWorkers时validator会被并发调用
+func WithValidator(validator func(key string, v interface{}) error) CacheOption {
+	return func(o *cacheOptions) {
+		o.validator = validator
+	}
+}
+
+// valid 校验解码后的值，未设置校验函数时总是有效；以*RawValue读取时未解码，不校验
+func (o *cacheOptions) valid(key string, v interface{}) bool {
+	if _, raw := v.(*RawValue); raw {
+		return true
+	}
+	return o.validator == nil || o.validator(key, v) == nil
+}
+
+// redisValidate 校验解码后的值，失败时删除缓存键并返回ErrCacheNotFound，删除失败时忽略
+func (o *cacheOptions) redisValidate(ctx context.Context, client redis.Cmdable, key, cacheKey string, v interface{}) error {
+	if o.valid(key, v) {
+		return nil
+	}
+	_ = o.redisDel(ctx, client, cacheKey).Err()
+	return ErrCacheNotFound
+}
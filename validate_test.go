@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidatorSkipsRawValue(t *testing.T) {
+	validator := func(_ string, v interface{}) error {
+		if _, ok := v.(*string); !ok {
+			return errors.New("unexpected type")
+		}
+		return nil
+	}
+	c := newTestMemoryCache(t, WithValidator(validator))
+	ctx := context.Background()
+	val := "a"
+	if err := c.Set(ctx, "k", &val, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw RawValue
+	if err := c.Get(ctx, "k", &raw); err != nil || string(raw) != `"a"` {
+		t.Fatalf("Get(*RawValue) = %s, %v, want \"a\"", raw, err)
+	}
+	var got string
+	if err := c.Get(ctx, "k", &got); err != nil || got != "a" {
+		t.Errorf("以RawValue读取后Get() = %q, %v, want a", got, err)
+	}
+}
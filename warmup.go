@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WarmIterator 预热数据源，每次调用返回下一条数据，ok为false表示数据已读完
+// 只在一个goroutine中调用，实现不需要线程安全
+type WarmIterator func() (key string, val interface{}, ok bool)
+
+// WarmOptions 批量预热选项
+type WarmOptions struct {
+	// BatchSize 每批MultiSet的键数量，默认500
+	BatchSize int
+	// Rate 每秒最多写入的键数量，0表示不限速
+	Rate int
+	// OnProgress 每批写入完成后回调，written为已写入的键数量
+	// 可能在多个goroutine中调用，但调用是串行的
+	OnProgress func(written int)
+}
+
+// setDefaults 设置默认值
+func (o *WarmOptions) setDefaults() {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+}
+
+// warmLimiter 按键数量限速
+type warmLimiter struct {
+	mu    sync.Mutex
+	rate  int
+	start time.Time
+	sent  int
+}
+
+// wait 等待到可以再写入n个键
+func (l *warmLimiter) wait(ctx context.Context, n int) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	due := l.start.Add(time.Duration(l.sent) * time.Second / time.Duration(l.rate))
+	l.sent += n
+	l.mu.Unlock()
+
+	delay := time.Until(due)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WarmFromIterator 从任意数据源(如SQL查询结果)流式读取数据，按批量MultiSet并发写入缓存，用于集群上线时的初始填充
+// Redis的MultiSet使用管道，concurrency为同时执行的批次数量，小于1时为1
+// 某一批写入失败后停止读取新数据，等待已提交的批次完成后返回，written为成功写入的键数量
+func WarmFromIterator(ctx context.Context, c Cache, it WarmIterator, ttl time.Duration, concurrency int, opts WarmOptions) (written int, err error) {
+	opts.setDefaults()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limiter := &warmLimiter{rate: opts.Rate}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   []error
+		failed bool
+	)
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return failed
+	}
+	sem := make(chan struct{}, concurrency)
+	flush := func(batch map[string]interface{}) error {
+		if err := limiter.wait(ctx, len(batch)); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := c.MultiSet(ctx, batch, ttl)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = true
+				errs = append(errs, fmt.Errorf("批量预热错误: %w, 键数量=%d", err, len(batch)))
+				return
+			}
+			written += len(batch)
+			if opts.OnProgress != nil {
+				opts.OnProgress(written)
+			}
+		}()
+		return nil
+	}
+
+	batch := make(map[string]interface{}, opts.BatchSize)
+	for !stopped() {
+		key, val, ok := it()
+		if !ok {
+			break
+		}
+		batch[key] = val
+		if len(batch) < opts.BatchSize {
+			continue
+		}
+		if err := flush(batch); err != nil {
+			wg.Wait()
+			return written, errors.Join(append(errs, err)...)
+		}
+		batch = make(map[string]interface{}, opts.BatchSize)
+	}
+	if len(batch) > 0 && !stopped() {
+		if err := flush(batch); err != nil {
+			wg.Wait()
+			return written, errors.Join(append(errs, err)...)
+		}
+	}
+	wg.Wait()
+	return written, errors.Join(errs...)
+}
@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WriteLimitMode 超出写入频率时的处理方式
+type WriteLimitMode string
+
+const (
+	// WriteLimitDrop 丢弃超出频率的写入，默认值
+	WriteLimitDrop WriteLimitMode = "drop"
+	// WriteLimitCoalesce 合并超出频率的写入，间隔结束时只写入最后一次的值
+	WriteLimitCoalesce WriteLimitMode = "coalesce"
+)
+
+// WriteLimitConfig 单键写入频率限制配置
+// 多个副本同时重新计算同一个热点聚合结果时，限制每个键的重写频率以保护Redis
+// 限制只在当前进程内生效，删除操作总是执行并清除该键的限制状态和待写入的值
+// Set、MultiSet和GetOrSet的回填受限制；SetNX和IncrWithTTL需要返回实际结果，不受限制
+type WriteLimitConfig struct {
+	// Interval 同一个键两次写入的最小间隔
+	Interval time.Duration `json:"interval" yaml:"interval"`
+	// Mode 超出频率时的处理方式，drop(默认)或coalesce
+	Mode WriteLimitMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// MaxKeys 最多跟踪的键数量，超出后新键不受限制，默认10000
+	MaxKeys int `json:"max_keys,omitempty" yaml:"max_keys,omitempty"`
+	// OnLimited 写入被丢弃或合并时的回调
+	OnLimited func(key string) `json:"-" yaml:"-"`
+}
+
+// validate 校验配置
+func (c *WriteLimitConfig) validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("写入频率限制间隔必须大于0: %s", c.Interval)
+	}
+	switch c.Mode {
+	case "", WriteLimitDrop, WriteLimitCoalesce:
+		return nil
+	default:
+		return fmt.Errorf("不支持的写入频率限制方式: %s", c.Mode)
+	}
+}
+
+// pendingWrite 合并模式下等待写入的值，保存编码后的数据，调用方之后修改值不影响写入
+// 写入时不再编码原始值，因此不记录数据源更新时间等依赖原始值的信封字段
+type pendingWrite struct {
+	ctx        context.Context
+	val        RawValue
+	expiration time.Duration
+}
+
+// writeSlot 单个键的写入状态
+type writeSlot struct {
+	last    time.Time
+	pending *pendingWrite
+	timer   *time.Timer
+}
+
+// writeLimitCache 限制单键写入频率的缓存
+type writeLimitCache struct {
+	forwarder
+	config   WriteLimitConfig
+	encoding Encoding
+	mu       sync.Mutex
+	slots    map[string]*writeSlot
+}
+
+// WithWriteLimit 为缓存添加单键写入频率限制，config为空时返回原缓存
+// encoding用于合并模式下编码待写入的值，应与缓存使用的编码相同
+func WithWriteLimit(c Cache, encoding Encoding, config *WriteLimitConfig) Cache {
+	if config == nil {
+		return c
+	}
+	cfg := *config
+	if cfg.Mode == "" {
+		cfg.Mode = WriteLimitDrop
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = 10000
+	}
+	return &writeLimitCache{forwarder: forwarder{c}, config: cfg, encoding: encoding, slots: make(map[string]*writeSlot)}
+}
+
+// slotKey 限制状态的键，区分上下文中的键前缀
+func slotKey(ctx context.Context, key string) string {
+	return KeyPrefixFromContext(ctx, "") + "\x00" + key
+}
+
+// snapshot 编码合并写入的值
+func (c *writeLimitCache) snapshot(val interface{}) (RawValue, error) {
+	if buf, ok := rawBytes(val); ok {
+		return RawValue(bytes.Clone(buf)), nil
+	}
+	buf, err := Marshal(c.encoding, val)
+	return RawValue(buf), err
+}
+
+// admit 判断键是否可以立即写入，不能写入时按模式丢弃或合并
+// 合并模式下编码值失败时立即写入，由后端返回编码错误
+func (c *writeLimitCache) admit(ctx context.Context, key string, val interface{}, expiration time.Duration) bool {
+	sk := slotKey(ctx, key)
+	now := time.Now()
+
+	c.mu.Lock()
+	slot, ok := c.slots[sk]
+	if !ok {
+		if len(c.slots) >= c.config.MaxKeys {
+			c.sweep(now)
+		}
+		if len(c.slots) >= c.config.MaxKeys {
+			c.mu.Unlock()
+			return true
+		}
+		c.slots[sk] = &writeSlot{last: now}
+		c.mu.Unlock()
+		return true
+	}
+	wait := slot.last.Add(c.config.Interval).Sub(now)
+	if wait <= 0 && slot.pending == nil {
+		slot.last = now
+		c.mu.Unlock()
+		return true
+	}
+	if c.config.Mode == WriteLimitCoalesce {
+		// 编码时不持有锁，完成后重新获取键的状态，键已被清除时直接写入
+		c.mu.Unlock()
+		raw, err := c.snapshot(val)
+		if err != nil {
+			return true
+		}
+		c.mu.Lock()
+		if slot, ok = c.slots[sk]; !ok {
+			c.mu.Unlock()
+			return true
+		}
+		slot.pending = &pendingWrite{ctx: context.WithoutCancel(ctx), val: raw, expiration: expiration}
+		if slot.timer == nil {
+			slot.timer = time.AfterFunc(max(wait, 0), func() { c.flush(sk, key) })
+		}
+	}
+	c.mu.Unlock()
+
+	if c.config.OnLimited != nil {
+		c.config.OnLimited(key)
+	}
+	return false
+}
+
+// flush 合并模式下在间隔结束时写入最后一次的值
+func (c *writeLimitCache) flush(sk, key string) {
+	c.mu.Lock()
+	slot, ok := c.slots[sk]
+	if !ok || slot.pending == nil {
+		c.mu.Unlock()
+		return
+	}
+	pending := slot.pending
+	slot.pending = nil
+	slot.timer = nil
+	slot.last = time.Now()
+	c.mu.Unlock()
+
+	_ = c.Cache.Set(pending.ctx, key, pending.val, pending.expiration)
+}
+
+// sweep 清除超过间隔且没有待写入值的键
+func (c *writeLimitCache) sweep(now time.Time) {
+	for sk, slot := range c.slots {
+		if slot.pending == nil && now.Sub(slot.last) >= c.config.Interval {
+			delete(c.slots, sk)
+		}
+	}
+}
+
+// forget 清除键的限制状态和待写入的值
+func (c *writeLimitCache) forget(ctx context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		sk := slotKey(ctx, key)
+		if slot, ok := c.slots[sk]; ok {
+			if slot.timer != nil {
+				slot.timer.Stop()
+			}
+			delete(c.slots, sk)
+		}
+	}
+}
+
+// Set 设置数据，超出频率时丢弃或合并并返回nil
+func (c *writeLimitCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if !c.admit(ctx, key, val, expiration) {
+		return nil
+	}
+	return c.Cache.Set(ctx, key, val, expiration)
+}
+
+// GetOrSet 获取数据，未命中时调用加载函数，回填缓存同样受频率限制，超出频率时丢弃或合并回填
+func (c *writeLimitCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader Loader, opts ...GetOrSetOption) error {
+	admit := withBackfillAdmit(func(val interface{}) bool {
+		return c.admit(ctx, key, val, ttl)
+	})
+	return c.Cache.GetOrSet(ctx, key, dest, ttl, loader, append(opts[:len(opts):len(opts)], admit)...)
+}
+
+// MultiSet 批量设置数据，只写入未超出频率的键
+func (c *writeLimitCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	admitted := make(map[string]interface{}, len(valueMap))
+	for key, val := range valueMap {
+		if c.admit(ctx, key, val, expiration) {
+			admitted[key] = val
+		}
+	}
+	if len(admitted) == 0 {
+		return nil
+	}
+	return c.Cache.MultiSet(ctx, admitted, expiration)
+}
+
+// Del 删除数据并清除限制状态
+func (c *writeLimitCache) Del(ctx context.Context, keys ...string) error {
+	c.forget(ctx, keys...)
+	return c.Cache.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound 设置未找到的缓存并清除待写入的值
+func (c *writeLimitCache) SetCacheWithNotFound(ctx context.Context, key string, ttl ...time.Duration) error {
+	c.forget(ctx, key)
+	return c.Cache.SetCacheWithNotFound(ctx, key, ttl...)
+}
+
+// MultiSetCacheWithNotFound 批量设置未找到的缓存并清除待写入的值
+func (c *writeLimitCache) MultiSetCacheWithNotFound(ctx context.Context, keys []string) error {
+	c.forget(ctx, keys...)
+	return c.forwarder.MultiSetCacheWithNotFound(ctx, keys)
+}
+
+// DelWithTombstone 删除数据并清除限制状态
+func (c *writeLimitCache) DelWithTombstone(ctx context.Context, key string, ttl time.Duration) error {
+	c.forget(ctx, key)
+	return c.forwarder.DelWithTombstone(ctx, key, ttl)
+}
+
+// DelDelayed 延迟双删并清除限制状态
+func (c *writeLimitCache) DelDelayed(ctx context.Context, key string, delay time.Duration) error {
+	c.forget(ctx, key)
+	return c.forwarder.DelDelayed(ctx, key, delay)
+}
+
+// DelMany 分片批量删除大量键并清除限制状态
+func (c *writeLimitCache) DelMany(ctx context.Context, keys []string, opts DelManyOptions) error {
+	c.forget(ctx, keys...)
+	return c.forwarder.DelMany(ctx, keys, opts)
+}
+
+// SetWithTags 设置数据并加入标签，不受频率限制，延迟写入会丢失标签，因此清除待写入的值后直接写入
+func (c *writeLimitCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	c.forget(ctx, key)
+	return c.forwarder.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// Copy 复制键到目标键并清除目标键待写入的值
+func (c *writeLimitCache) Copy(ctx context.Context, src, dst string, preserveTTL bool) error {
+	c.forget(ctx, dst)
+	return c.forwarder.Copy(ctx, src, dst, preserveTTL)
+}
+
+// Rename 重命名键并清除两个键待写入的值
+func (c *writeLimitCache) Rename(ctx context.Context, src, dst string) error {
+	c.forget(ctx, src, dst)
+	return c.forwarder.Rename(ctx, src, dst)
+}
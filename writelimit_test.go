@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestWriteLimitCache(t *testing.T, mode WriteLimitMode, limited *atomic.Int32) (Cache, Cache) {
+	t.Helper()
+	inner := newTestMemoryCache(t)
+	c := WithWriteLimit(inner, &JSONEncoding{}, &WriteLimitConfig{
+		Interval:  50 * time.Millisecond,
+		Mode:      mode,
+		OnLimited: func(string) { limited.Add(1) },
+	})
+	return c, inner
+}
+
+func TestWriteLimitSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    WriteLimitMode
+		want    string
+		limited int32
+	}{
+		{"drop", WriteLimitDrop, "v1", 2},
+		{"coalesce", WriteLimitCoalesce, "v3", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limited atomic.Int32
+			c, inner := newTestWriteLimitCache(t, tt.mode, &limited)
+			ctx := context.Background()
+			for _, v := range []string{"v1", "v2", "v3"} {
+				v := v
+				if err := c.Set(ctx, "k", &v, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if n := limited.Load(); n != tt.limited {
+				t.Errorf("OnLimited调用次数 = %d, want %d", n, tt.limited)
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			var got string
+			if err := inner.Get(ctx, "k", &got); err != nil || got != tt.want {
+				t.Errorf("Get() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLimitCoalesceSnapshot(t *testing.T) {
+	var limited atomic.Int32
+	c, inner := newTestWriteLimitCache(t, WriteLimitCoalesce, &limited)
+	ctx := context.Background()
+	first, second := "v1", "v2"
+	if err := c.Set(ctx, "k", &first, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "k", &second, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// 合并的写入保存编码后的数据，之后修改值不影响写入
+	second = "modified"
+
+	time.Sleep(100 * time.Millisecond)
+	var got string
+	if err := inner.Get(ctx, "k", &got); err != nil || got != "v2" {
+		t.Errorf("Get() = %q, %v, want v2", got, err)
+	}
+}
+
+func TestWriteLimitGetOrSet(t *testing.T) {
+	var limited atomic.Int32
+	c, inner := newTestWriteLimitCache(t, WriteLimitDrop, &limited)
+	ctx := context.Background()
+	v := "set"
+	if err := c.Set(ctx, "k", &v, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	err := c.GetOrSet(ctx, "k", &got, time.Minute, func(context.Context) (interface{}, error) {
+		return "loaded", nil
+	})
+	if err != nil || got != "loaded" {
+		t.Fatalf("GetOrSet() = %q, %v, want loaded", got, err)
+	}
+	if limited.Load() != 1 {
+		t.Errorf("OnLimited调用次数 = %d, want 1", limited.Load())
+	}
+	if err := inner.Get(ctx, "k", &got); err == nil {
+		t.Errorf("间隔内的回填未被丢弃: Get() = %q", got)
+	}
+}
+
+func TestWriteLimitDelClearsPending(t *testing.T) {
+	var limited atomic.Int32
+	c, inner := newTestWriteLimitCache(t, WriteLimitCoalesce, &limited)
+	ctx := context.Background()
+	for _, v := range []string{"v1", "v2"} {
+		v := v
+		if err := c.Set(ctx, "k", &v, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	var got string
+	if err := inner.Get(ctx, "k", &got); err == nil {
+		t.Errorf("删除后仍写入了待写入的值: Get() = %q", got)
+	}
+}